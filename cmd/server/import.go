@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/KaranPal130/transfers-system/internal/importer"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	service "github.com/KaranPal130/transfers-system/internal/services"
+)
+
+const importUsage = "usage: server import -kind accounts|transfers [-format csv|jsonl] [-chunk-size n] [-dry-run] [-report file] file"
+
+// runImport implements the `import` subcommand. Rejected rows are written to
+// the report as CSV, and the command exits non-zero if there were any.
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.Usage = func() { log.Print(importUsage) }
+
+	kind := flags.String("kind", "", "accounts or transfers")
+	format := flags.String("format", "", "csv or jsonl (default from the file extension)")
+	chunkSize := flags.Int("chunk-size", service.DefaultImportChunkSize, "rows per transaction")
+	dryRun := flags.Bool("dry-run", false, "validate without writing")
+	reportPath := flags.String("report", "", "file to write rejected rows to (default stderr)")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		log.Fatal(importUsage)
+	}
+	path := flags.Arg(0)
+
+	if *format == "" {
+		var ok bool
+		*format, ok = importer.FormatFromName(path)
+		if !ok {
+			log.Fatal("Cannot tell the format from the file name; pass -format")
+		}
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open import file: %v", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var out io.Writer = os.Stderr
+	if *reportPath != "" {
+		file, err := os.Create(*reportPath)
+		if err != nil {
+			log.Fatalf("Failed to create report: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	records, err := importer.NewReader(in, *format)
+	if err != nil {
+		log.Fatalf("Failed to read import file: %v", err)
+	}
+
+	uow, stores, closeStores := openStores()
+	defer closeStores()
+
+	transactionService := service.NewTransactionService(uow, stores.Accounts, stores.Transactions, stores.Idempotency, stores.Batches, stores.Approvals, stores.Risk, loadRates(), service.DefaultIdempotencyKeyTTL, service.ApprovalPolicy{}, loadLimitPolicy(), nil)
+	importService := service.NewImportService(uow, transactionService)
+
+	report := csv.NewWriter(out)
+	_ = report.Write([]string{"line", "error"})
+
+	result, err := importService.Import(context.Background(), records, service.ImportOptions{
+		Kind:      *kind,
+		ChunkSize: *chunkSize,
+		DryRun:    *dryRun,
+		Report: func(rowError models.ImportRowError) error {
+			return report.Write([]string{strconv.Itoa(rowError.Line), rowError.Error})
+		},
+	})
+
+	report.Flush()
+	if flushErr := report.Error(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+
+	verb := "Imported"
+	if result.DryRun {
+		verb = "Dry run: would import"
+	}
+	log.Printf("%s %d of %d %s rows, %d rejected", verb, result.Imported, result.Rows, *kind, result.Failed)
+
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+	if result.Failed > 0 {
+		closeStores()
+		os.Exit(1)
+	}
+}
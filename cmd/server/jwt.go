@@ -0,0 +1,272 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const jwtUsage = `usage: server jwt keygen -key key.pem -jwks jwks.json
+       server jwt sign -key key.pem -iss issuer -aud audience -sub subject [-scope "accounts:read ..."] [-accounts 1,2] [-ttl 1h]`
+
+// runJWT implements the `jwt` subcommand, which stands in for an identity
+// provider when trying out bearer-token authentication locally: keygen makes
+// an ES256 key pair and its JWKS, and sign issues a token with it.
+func runJWT(args []string) {
+	if len(args) == 0 {
+		log.Fatal(jwtUsage)
+	}
+
+	switch args[0] {
+	case "keygen":
+		runJWTKeygen(args[1:])
+	case "sign":
+		runJWTSign(args[1:])
+	default:
+		log.Fatal(jwtUsage)
+	}
+}
+
+func runJWTKeygen(args []string) {
+	flags := flag.NewFlagSet("jwt keygen", flag.ExitOnError)
+	flags.Usage = func() { log.Print(jwtUsage) }
+
+	keyPath := flags.String("key", "", "file to write the private key to, as PKCS #8 PEM")
+	jwksPath := flags.String("jwks", "", "file to write the public JWKS to")
+	_ = flags.Parse(args)
+
+	if *keyPath == "" || *jwksPath == "" {
+		log.Fatal(jwtUsage)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		log.Fatalf("Failed to encode key: %v", err)
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(*keyPath, keyPEM, 0o600); err != nil {
+		log.Fatalf("Failed to write key: %v", err)
+	}
+
+	public := publicJWK(key.Public())
+	public["kid"] = thumbprint(key.Public())
+	public["alg"] = "ES256"
+	public["use"] = "sig"
+
+	jwks, err := json.MarshalIndent(map[string]any{"keys": []map[string]string{public}}, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode JWKS: %v", err)
+	}
+
+	if err := os.WriteFile(*jwksPath, append(jwks, '\n'), 0o644); err != nil {
+		log.Fatalf("Failed to write JWKS: %v", err)
+	}
+}
+
+func runJWTSign(args []string) {
+	flags := flag.NewFlagSet("jwt sign", flag.ExitOnError)
+	flags.Usage = func() { log.Print(jwtUsage) }
+
+	keyPath := flags.String("key", "", "PKCS #8 PEM private key (ECDSA, RSA or Ed25519)")
+	issuer := flags.String("iss", "", "issuer")
+	audience := flags.String("aud", "", "audience")
+	subject := flags.String("sub", "", "subject")
+	scope := flags.String("scope", "", "space separated scopes")
+	accounts := flags.String("accounts", "", "comma separated account IDs the caller may debit")
+	ttl := flags.Duration("ttl", time.Hour, "lifetime")
+	_ = flags.Parse(args)
+
+	if *keyPath == "" || *issuer == "" || *audience == "" || *subject == "" {
+		log.Fatal(jwtUsage)
+	}
+
+	signer := loadSigningKey(*keyPath)
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss": *issuer,
+		"aud": *audience,
+		"sub": *subject,
+		"iat": now.Unix(),
+		"exp": now.Add(*ttl).Unix(),
+	}
+	if *scope != "" {
+		claims["scope"] = *scope
+	}
+	if *accounts != "" {
+		var ids []int64
+		for _, field := range strings.Split(*accounts, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+			if err != nil {
+				log.Fatalf("Invalid account ID %q", field)
+			}
+			ids = append(ids, id)
+		}
+		claims["account_ids"] = ids
+	}
+
+	token, err := signToken(signer, claims)
+	if err != nil {
+		log.Fatalf("Failed to sign token: %v", err)
+	}
+
+	fmt.Println(token)
+}
+
+func loadSigningKey(path string) crypto.Signer {
+	keyPEM, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Failed to read key: %v", err)
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		log.Fatal("Key file holds no PEM block")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		log.Fatalf("Failed to parse key: %v", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		log.Fatal("Unsupported key type")
+	}
+
+	return signer
+}
+
+func signToken(signer crypto.Signer, claims map[string]any) (string, error) {
+	var alg string
+	var hash crypto.Hash
+
+	switch key := signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			alg, hash = "ES256", crypto.SHA256
+		case elliptic.P384():
+			alg, hash = "ES384", crypto.SHA384
+		case elliptic.P521():
+			alg, hash = "ES512", crypto.SHA512
+		default:
+			return "", fmt.Errorf("unsupported curve %s", key.Curve.Params().Name)
+		}
+	case *rsa.PublicKey:
+		alg, hash = "RS256", crypto.SHA256
+	case ed25519.PublicKey:
+		alg = "EdDSA"
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": thumbprint(signer.Public())})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := []byte(signingInput)
+	if hash != 0 {
+		h := hash.New()
+		h.Write(digest)
+		digest = h.Sum(nil)
+	}
+
+	signature, err := signer.Sign(rand.Reader, digest, hash)
+	if err != nil {
+		return "", err
+	}
+
+	// JWS wants an ECDSA signature as the fixed-size r and s, not ASN.1.
+	if key, ok := signer.Public().(*ecdsa.PublicKey); ok {
+		signature, err = rawECDSASignature(signature, key)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func rawECDSASignature(der []byte, key *ecdsa.PublicKey) ([]byte, error) {
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, err
+	}
+
+	size := (key.Curve.Params().BitSize + 7) / 8
+	raw := make([]byte, 2*size)
+	sig.R.FillBytes(raw[:size])
+	sig.S.FillBytes(raw[size:])
+	return raw, nil
+}
+
+// publicJWK returns the members RFC 7638 uses to identify a public key.
+func publicJWK(key crypto.PublicKey) map[string]string {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return map[string]string{
+			"kty": "EC",
+			"crv": key.Curve.Params().Name,
+			"x":   b64(key.X.FillBytes(make([]byte, size))),
+			"y":   b64(key.Y.FillBytes(make([]byte, size))),
+		}
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"n":   b64(key.N.Bytes()),
+			"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return map[string]string{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   b64(key),
+		}
+	default:
+		return nil
+	}
+}
+
+// thumbprint is the RFC 7638 thumbprint of key, used as its kid.
+// json.Marshal writes map keys sorted and without whitespace, as the RFC
+// requires.
+func thumbprint(key crypto.PublicKey) string {
+	members, _ := json.Marshal(publicJWK(key))
+	sum := sha256.Sum256(members)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// FreezeAccount handles account freeze requests
+// @Summary Freeze account
+// @Description Block debits (mode "debit") or all movements (mode "all", the default) on the account until it is unfrozen
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param freeze body models.FreezeAccountRequest true "Freeze request"
+// @Success 200 {object} models.Account
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/accounts/{account_id}/freeze [post]
+func (h *Handler) FreezeAccount(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.FreezeAccountRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	account, err := h.accountService.FreezeAccount(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// UnfreezeAccount handles account unfreeze requests
+// @Summary Unfreeze account
+// @Description Return a frozen account to active
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param unfreeze body models.UnfreezeAccountRequest true "Unfreeze request"
+// @Success 200 {object} models.Account
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/accounts/{account_id}/unfreeze [post]
+func (h *Handler) UnfreezeAccount(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UnfreezeAccountRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	account, err := h.accountService.UnfreezeAccount(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// CloseAccount handles account closure requests
+// @Summary Close account
+// @Description Close the account for good. The balance must be zero, or positive with sweep_to_account_id set to transfer it out first, and the account must have no active holds.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param close body models.CloseAccountRequest true "Close request"
+// @Success 200 {object} models.Account
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/accounts/{account_id}/close [post]
+func (h *Handler) CloseAccount(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.CloseAccountRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	account, err := h.accountService.CloseAccount(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// ListAccountStatusChanges handles account status history requests
+// @Summary List account status history
+// @Description List every status change of the account, oldest first, with the reason given for each
+// @Tags accounts
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Success 200 {array} models.AccountStatusChange
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /accounts/{account_id}/status-history [get]
+func (h *Handler) ListAccountStatusChanges(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	changes, err := h.accountService.ListStatusChanges(c.Request.Context(), accountID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+func accountIDParam(c *gin.Context) (int64, bool) {
+	accountID, err := strconv.ParseInt(c.Param("account_id"), 10, 64)
+	if err != nil {
+		writeParamError(c, "account_id", "must be an integer")
+		return 0, false
+	}
+
+	return accountID, true
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKey handles API key creation requests
+// @Summary Create API key
+// @Description Mint an API key with the given scopes, optionally limited to debiting the given accounts. The secret is only returned here.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body models.APIKeyRequest true "API key request"
+// @Success 201 {object} models.IssuedAPIKey
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys [post]
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var req models.APIKeyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	key, err := h.apiKeyService.CreateKey(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys handles API key listing requests
+// @Summary List API keys
+// @Description List every API key, including revoked ones. Secrets are never listed.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys [get]
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListKeys(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RotateAPIKey handles API key rotation requests
+// @Summary Rotate API key
+// @Description Mint a successor with the same scopes and accounts. The old key keeps working for the grace period, if any.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Param rotation body models.APIKeyRotateRequest false "Rotation request"
+// @Success 201 {object} models.IssuedAPIKey
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys/{id}/rotate [post]
+func (h *Handler) RotateAPIKey(c *gin.Context) {
+	id, ok := apiKeyIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.APIKeyRotateRequest
+
+	// The body is optional.
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeBodyError(c, err)
+			return
+		}
+	}
+
+	key, err := h.apiKeyService.RotateKey(c.Request.Context(), id, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKey handles API key revocation requests
+// @Summary Revoke API key
+// @Description Stop an API key from authenticating, with immediate effect
+// @Tags admin
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys/{id}/revoke [post]
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	id, ok := apiKeyIDParam(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.apiKeyService.RevokeKey(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+func apiKeyIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return 0, false
+	}
+
+	return id, true
+}
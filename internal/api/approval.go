@@ -0,0 +1,129 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ApproveTransaction handles transfer approval requests
+// @Summary Approve transaction
+// @Description Post a transfer awaiting approval. The caller must not be the one that made it. The transfer is checked again as if it were made now; if a check fails it stays pending.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param decision body models.ApprovalDecisionRequest false "Approval decision"
+// @Success 200 {object} models.Transaction
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 422 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transactions/{id}/approve [post]
+func (h *Handler) ApproveTransaction(c *gin.Context) {
+	id, req, ok := approvalDecision(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.transactionService.ApproveTransaction(c.Request.Context(), id, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// RejectTransaction handles transfer rejection requests
+// @Summary Reject transaction
+// @Description Turn down a transfer awaiting approval. The caller must not be the one that made it. No money moves, and the transfer's external reference may be used again.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param decision body models.ApprovalDecisionRequest false "Approval decision"
+// @Success 200 {object} models.Transaction
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 422 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transactions/{id}/reject [post]
+func (h *Handler) RejectTransaction(c *gin.Context) {
+	id, req, ok := approvalDecision(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.transactionService.RejectTransaction(c.Request.Context(), id, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// ListApprovalEvents handles approval audit trail requests
+// @Summary List approval events
+// @Description List the approval chain of a transaction, oldest first: its request, then the approval, rejection or expiry that settled it
+// @Tags transactions
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {array} models.ApprovalEvent
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transactions/{id}/approvals [get]
+func (h *Handler) ListApprovalEvents(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return
+	}
+
+	events, err := h.transactionService.ListApprovalEvents(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// approvalDecision reads the transaction ID and the optional decision body,
+// writing the error response if either is invalid.
+func approvalDecision(c *gin.Context) (int64, models.ApprovalDecisionRequest, bool) {
+	var req models.ApprovalDecisionRequest
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return 0, req, false
+	}
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeBodyError(c, err)
+			return 0, req, false
+		}
+	}
+
+	return id, req, true
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	service "github.com/KaranPal130/transfers-system/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateBatch handles batch transfer requests
+// @Summary Create batch transfer
+// @Description Post several transfers atomically, in order, with every involved account locked. If any leg fails, nothing is posted and the response lists every failing leg.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param batch body models.BatchTransactionRequest true "Batch request"
+// @Param Idempotency-Key header string false "Client-generated key that makes retries safe"
+// @Success 201 {object} models.TransactionBatch
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 422 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transactions/batch [post]
+func (h *Handler) CreateBatch(c *gin.Context) {
+	var req models.BatchTransactionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+
+	batch, err := h.transactionService.CreateBatch(c.Request.Context(), req, idempotencyKey)
+	if err != nil {
+		var batchErr *service.BatchError
+		if errors.As(err, &batchErr) {
+			writeProblem(c, problemBatchRejected, batchErr.Error(), legProblems(batchErr))
+			return
+		}
+
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, batch)
+}
+
+// legProblems lists the errors of a rejected batch, each under the field of
+// its leg, such as legs[2].amount, or under legs[2] itself when the leg was
+// rejected as a whole.
+func legProblems(batchErr *service.BatchError) []models.FieldProblem {
+	var problems []models.FieldProblem
+	for _, leg := range batchErr.Legs {
+		prefix := fmt.Sprintf("legs[%d]", leg.Index)
+
+		fields := fieldProblems(leg.Err)
+		if len(fields) == 0 {
+			pt, _ := lookupProblem(leg.Err)
+			problems = append(problems, models.FieldProblem{Field: prefix, Code: pt.code, Detail: pt.title})
+			continue
+		}
+
+		for _, field := range fields {
+			field.Field = prefix + "." + field.Field
+			problems = append(problems, field)
+		}
+	}
+	return problems
+}
+
+// GetBatch handles batch retrieval requests
+// @Summary Get batch transfer
+// @Description Get a batch and its legs
+// @Tags transactions
+// @Produce json
+// @Param id path int true "Batch ID"
+// @Success 200 {object} models.TransactionBatch
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transactions/batch/{id} [get]
+func (h *Handler) GetBatch(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return
+	}
+
+	batch, err := h.transactionService.GetBatch(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
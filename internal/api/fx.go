@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateFXQuote handles FX quote requests
+// @Summary Create FX quote
+// @Description Lock the current exchange rate for a currency pair. Reference the quote ID as fx_quote_id in POST /transactions before it expires.
+// @Tags fx
+// @Accept json
+// @Produce json
+// @Param quote body models.FXQuoteRequest true "FX quote request"
+// @Success 201 {object} models.FXQuote
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /fx/quotes [post]
+func (h *Handler) CreateFXQuote(c *gin.Context) {
+	var req models.FXQuoteRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	quote, err := h.fxService.CreateQuote(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, quote)
+}
+
+// GetFXQuote handles FX quote retrieval requests
+// @Summary Get FX quote
+// @Description Get FX quote by ID
+// @Tags fx
+// @Produce json
+// @Param id path string true "Quote ID"
+// @Success 200 {object} models.FXQuote
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /fx/quotes/{id} [get]
+func (h *Handler) GetFXQuote(c *gin.Context) {
+	quote, err := h.fxService.GetQuote(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
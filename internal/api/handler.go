@@ -1,12 +1,10 @@
 package api
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/KaranPal130/transfers-system/internal/models"
-	repository "github.com/KaranPal130/transfers-system/internal/repositories"
 	service "github.com/KaranPal130/transfers-system/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -14,12 +12,33 @@ import (
 type Handler struct {
 	accountService     *service.AccountService
 	transactionService *service.TransactionService
+	fxService          *service.FXService
+	holdService        *service.HoldService
+	scheduleService    *service.ScheduleService
+	statementService   *service.StatementService
+	importService      *service.ImportService
+	apiKeyService      *service.APIKeyService
 }
 
-func NewHandler(accountService *service.AccountService, transactionService *service.TransactionService) *Handler {
+func NewHandler(
+	accountService *service.AccountService,
+	transactionService *service.TransactionService,
+	fxService *service.FXService,
+	holdService *service.HoldService,
+	scheduleService *service.ScheduleService,
+	statementService *service.StatementService,
+	importService *service.ImportService,
+	apiKeyService *service.APIKeyService,
+) *Handler {
 	return &Handler{
 		accountService:     accountService,
 		transactionService: transactionService,
+		fxService:          fxService,
+		holdService:        holdService,
+		scheduleService:    scheduleService,
+		statementService:   statementService,
+		importService:      importService,
+		apiKeyService:      apiKeyService,
 	}
 }
 
@@ -31,34 +50,31 @@ func NewHandler(accountService *service.AccountService, transactionService *serv
 // @Produce json
 // @Param account body models.AccountCreateRequest true "Account create request"
 // @Success 201 {object} models.Account
-// @Failure 400 {object} map[string]string
-// @Failure 409 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /accounts [post]
 func (h *Handler) CreateAccount(c *gin.Context) {
 	var req models.AccountCreateRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		writeBodyError(c, err)
 		return
 	}
 
 	err := h.accountService.CreateAccount(c.Request.Context(), req)
 	if err != nil {
-		switch {
-		case errors.Is(err, service.ErrInvalidInitialBalance):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid initial balance"})
-		case errors.Is(err, service.ErrAccountAlreadyExists):
-			c.JSON(http.StatusConflict, gin.H{"error": "Account already exists"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+		writeError(c, err)
 		return
 	}
 
 	account, err := h.accountService.GetAccount(c.Request.Context(), req.AccountID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch created account"})
+		writeError(c, err)
 		return
 	}
 
@@ -72,69 +88,442 @@ func (h *Handler) CreateAccount(c *gin.Context) {
 // @Produce json
 // @Param account_id path int true "Account ID"
 // @Success 200 {object} models.Account
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /accounts/{account_id} [get]
 func (h *Handler) GetAccount(c *gin.Context) {
 	accountIDStr := c.Param("account_id")
 
 	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		writeParamError(c, "account_id", "must be an integer")
 		return
 	}
 
 	account, err := h.accountService.GetAccount(c.Request.Context(), accountID)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrAccountNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+		writeError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, account)
 }
 
+// ListAccounts handles account listing requests
+// @Summary List accounts
+// @Description List accounts matching the filters, one page at a time. Pass next_cursor back as cursor, with the same sort, to fetch the next page.
+// @Tags accounts
+// @Produce json
+// @Param status query string false "Account status"
+// @Param currency query string false "ISO 4217 currency code"
+// @Param min_balance query string false "Minimum ledger balance, inclusive"
+// @Param max_balance query string false "Maximum ledger balance, inclusive"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created before (RFC 3339)"
+// @Param sort query string false "account_id (default), balance or created_at; prefix with - for descending"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} models.AccountPage
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /accounts [get]
+func (h *Handler) ListAccounts(c *gin.Context) {
+	var req models.AccountListRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeParamError(c, "limit", "must be an integer")
+		return
+	}
+
+	page, err := h.accountService.ListAccounts(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// UpdateOverdraftLimit handles overdraft limit changes
+// @Summary Set overdraft limit
+// @Description Set how far below zero transfers may take the account's balance. Lowering the limit below what the account already owes only blocks further debits.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param limit body models.OverdraftLimitRequest true "Overdraft limit request"
+// @Success 200 {object} models.Account
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/accounts/{account_id}/overdraft [put]
+func (h *Handler) UpdateOverdraftLimit(c *gin.Context) {
+	accountIDStr := c.Param("account_id")
+
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "account_id", "must be an integer")
+		return
+	}
+
+	var req models.OverdraftLimitRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	account, err := h.accountService.UpdateOverdraftLimit(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// GetAccountBalance handles point-in-time balance requests
+// @Summary Get account balance as of a time
+// @Description Get the account balance at a point in time, computed from the journal postings made up to then
+// @Tags accounts
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param as_of query string false "RFC 3339 timestamp, inclusive (default now)"
+// @Success 200 {object} models.AccountBalance
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /accounts/{account_id}/balance [get]
+func (h *Handler) GetAccountBalance(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.accountService.GetBalanceAsOf(c.Request.Context(), accountID, c.Query("as_of"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetAccountBalanceHistory handles balance history requests
+// @Summary Get account balance history
+// @Description Replay the account's journal postings after from and up to to. Without an interval every posting is listed with the running balance after it; with one, each UTC hour, day or week (from Monday) is listed with its net change and closing balance.
+// @Tags accounts
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param from query string false "RFC 3339 start, exclusive (default 30 days before to)"
+// @Param to query string false "RFC 3339 end, inclusive (default now)"
+// @Param interval query string false "hour, day or week"
+// @Success 200 {object} models.BalanceHistory
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /accounts/{account_id}/balance-history [get]
+func (h *Handler) GetAccountBalanceHistory(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.BalanceHistoryRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeProblem(c, problemInvalidParameter, "Invalid query parameters", nil)
+		return
+	}
+
+	history, err := h.accountService.GetBalanceHistory(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// ReconcileAccount handles balance reconciliation requests
+// @Summary Reconcile account balance
+// @Description Recompute the account balance from its journal postings and report drift against the cached balance
+// @Tags accounts
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Success 200 {object} models.BalanceReconciliation
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /accounts/{account_id}/reconciliation [get]
+func (h *Handler) ReconcileAccount(c *gin.Context) {
+	accountIDStr := c.Param("account_id")
+
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "account_id", "must be an integer")
+		return
+	}
+
+	reconciliation, err := h.accountService.ReconcileBalance(c.Request.Context(), accountID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliation)
+}
+
 // CreateTransaction handles transaction creation requests
 // @Summary Create transaction
-// @Description Create a new transaction
+// @Description Create a new transaction. A transfer above the approval threshold is accepted as pending_approval, and moves no money until another caller approves it. A transfer that would exceed the source account's limits is rejected with 429, or with 422 if it is above the account's maximum amount. Transfers are screened against the risk rules, if configured: one they deny is rejected with 422, and one they flag for review is held for approval like a large transfer.
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Param transaction body models.TransactionRequest true "Transaction request"
-// @Success 201 {object} models.TransactionRequest
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Param Idempotency-Key header string false "Client-generated key that makes retries safe"
+// @Success 201 {object} models.Transaction
+// @Success 202 {object} models.Transaction
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 422 {object} models.Problem
+// @Failure 429 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /transactions [post]
 func (h *Handler) CreateTransaction(c *gin.Context) {
 	var req models.TransactionRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		writeBodyError(c, err)
+		return
+	}
+
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+
+	transaction, err := h.transactionService.CreateTransaction(c.Request.Context(), req, idempotencyKey)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	if transaction.Status == models.TransactionStatusPendingApproval {
+		c.JSON(http.StatusAccepted, transaction)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// FindTransactions handles transaction lookup requests
+// @Summary Find transactions by external reference
+// @Description List the transactions made with an external reference, from any source account, oldest first. References are unique per source account, so at most one comes from each.
+// @Tags transactions
+// @Produce json
+// @Param external_reference query string true "External reference"
+// @Success 200 {array} models.Transaction
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transactions [get]
+func (h *Handler) FindTransactions(c *gin.Context) {
+	transactions, err := h.transactionService.FindByExternalReference(c.Request.Context(), c.Query("external_reference"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
+
+// GetTransaction handles transaction retrieval requests
+// @Summary Get transaction
+// @Description Get transaction by ID
+// @Tags transactions
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} models.Transaction
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transactions/{id} [get]
+func (h *Handler) GetTransaction(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return
+	}
+
+	transaction, err := h.transactionService.GetTransaction(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// ReverseTransaction handles transaction reversal requests
+// @Summary Reverse transaction
+// @Description Return all or part of a transfer to its source through a linked compensating transaction. Omit amount to reverse everything not yet reversed.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param reversal body models.ReversalRequest true "Reversal request"
+// @Param Idempotency-Key header string false "Client-generated key that makes retries safe"
+// @Success 201 {object} models.Transaction
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 422 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transactions/{id}/reversals [post]
+func (h *Handler) ReverseTransaction(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return
+	}
+
+	var req models.ReversalRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+
+	reversal, err := h.transactionService.ReverseTransaction(c.Request.Context(), id, req, idempotencyKey)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reversal)
+}
+
+// ListReversals handles transaction reversal listing requests
+// @Summary List reversals
+// @Description List the reversals of a transaction, oldest first
+// @Tags transactions
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {array} models.Transaction
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transactions/{id}/reversals [get]
+func (h *Handler) ListReversals(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return
+	}
+
+	reversals, err := h.transactionService.ListReversals(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reversals)
+}
+
+// ListAccountTransactions handles account transaction history requests
+// @Summary List account transactions
+// @Description List transactions where the account is the source or destination, newest first
+// @Tags accounts
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Number of transactions to skip"
+// @Success 200 {array} models.Transaction
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /accounts/{account_id}/transactions [get]
+func (h *Handler) ListAccountTransactions(c *gin.Context) {
+	accountIDStr := c.Param("account_id")
+
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "account_id", "must be an integer")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultPageSize)))
+	if err != nil {
+		writeParamError(c, "limit", "must be an integer")
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		writeParamError(c, "offset", "must be an integer")
 		return
 	}
 
-	err := h.transactionService.CreateTransaction(c.Request.Context(), req)
+	transactions, err := h.transactionService.ListAccountTransactions(c.Request.Context(), accountID, limit, offset)
 	if err != nil {
-		switch {
-		case errors.Is(err, service.ErrInvalidAmount):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount"})
-		case errors.Is(err, service.ErrInsufficientBalance):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
-		case errors.Is(err, service.ErrSameSourceAndDest):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Source and destination accounts must be different"})
-		case errors.Is(err, repository.ErrAccountNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+		writeError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, req)
+	c.JSON(http.StatusOK, transactions)
 }
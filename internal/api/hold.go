@@ -0,0 +1,157 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateHold handles hold requests
+// @Summary Create hold
+// @Description Reserve an amount on an account. The hold reduces the available balance but not the ledger balance until it is captured, voided or expires.
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Param hold body models.HoldRequest true "Hold request"
+// @Success 201 {object} models.Hold
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 422 {object} models.Problem
+// @Failure 429 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /holds [post]
+func (h *Handler) CreateHold(c *gin.Context) {
+	var req models.HoldRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	hold, err := h.holdService.CreateHold(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// GetHold handles hold retrieval requests
+// @Summary Get hold
+// @Description Get hold by ID
+// @Tags holds
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Success 200 {object} models.Hold
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /holds/{id} [get]
+func (h *Handler) GetHold(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return
+	}
+
+	hold, err := h.holdService.GetHold(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// CaptureHold handles hold capture requests
+// @Summary Capture hold
+// @Description Settle a hold as a transfer to the destination account. Omit amount to capture the full hold; capturing less releases the remainder.
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Param capture body models.HoldCaptureRequest true "Capture request"
+// @Success 201 {object} models.Transaction
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 422 {object} models.Problem
+// @Failure 429 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /holds/{id}/capture [post]
+func (h *Handler) CaptureHold(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return
+	}
+
+	var req models.HoldCaptureRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	transaction, err := h.holdService.CaptureHold(c.Request.Context(), id, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// VoidHold handles hold void requests
+// @Summary Void hold
+// @Description Release a hold without moving any money
+// @Tags holds
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Success 200 {object} models.Hold
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /holds/{id}/void [post]
+func (h *Handler) VoidHold(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return
+	}
+
+	hold, err := h.holdService.VoidHold(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/KaranPal130/transfers-system/internal/importer"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	service "github.com/KaranPal130/transfers-system/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportErrors caps the row errors an import response lists.
+const maxImportErrors = 1000
+
+// ImportFile handles bulk import requests
+// @Summary Bulk import accounts or transfers
+// @Description Load the rows of a CSV (with a header row) or JSON Lines request body. Each row gets the checks a single create request would; rows that fail are listed by line and skipped while the rest are written in chunks. With dry_run nothing is kept. Transfers have no natural key, so importing the same file twice posts them twice.
+// @Tags admin
+// @Accept plain
+// @Produce json
+// @Param kind query string true "accounts or transfers"
+// @Param format query string false "csv (default) or jsonl"
+// @Param dry_run query bool false "Validate without writing"
+// @Param chunk_size query int false "Rows per transaction (default 1000)"
+// @Success 200 {object} models.ImportResult
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/imports [post]
+func (h *Handler) ImportFile(c *gin.Context) {
+	kind := c.Query("kind")
+	if kind != models.ImportKindAccounts && kind != models.ImportKindTransfers {
+		writeError(c, service.ErrInvalidImportKind)
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		writeParamError(c, "dry_run", "must be a boolean")
+		return
+	}
+
+	chunkSize, err := strconv.Atoi(c.DefaultQuery("chunk_size", "0"))
+	if err != nil {
+		writeParamError(c, "chunk_size", "must be an integer")
+		return
+	}
+
+	records, err := importer.NewReader(c.Request.Body, c.DefaultQuery("format", importer.FormatCSV))
+	if errors.Is(err, importer.ErrUnknownFormat) {
+		writeError(c, err)
+		return
+	}
+	if err != nil {
+		writeProblem(c, problemInvalidBody, "Invalid import file: "+err.Error(), nil)
+		return
+	}
+
+	result, err := h.importService.Import(c.Request.Context(), records, service.ImportOptions{
+		Kind:      kind,
+		ChunkSize: chunkSize,
+		DryRun:    dryRun,
+		MaxErrors: maxImportErrors,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAccountLimits handles transfer limit lookups
+// @Summary Get account transfer limits
+// @Description Get the transfer limits in force on the account, from its tier and its own overrides, and the headroom left under each
+// @Tags accounts
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Success 200 {object} models.AccountLimits
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /accounts/{account_id}/limits [get]
+func (h *Handler) GetAccountLimits(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	limits, err := h.transactionService.GetAccountLimits(c.Request.Context(), accountID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// UpdateAccountLimits handles transfer limit changes
+// @Summary Set account transfer limits
+// @Description Replace the account's own transfer limits. Omitted limits are taken from the account's tier. Lowering a limit below what the account has already sent only blocks further transfers.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param limits body models.AccountLimitsRequest true "Limits request"
+// @Success 200 {object} models.AccountLimits
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/accounts/{account_id}/limits [put]
+func (h *Handler) UpdateAccountLimits(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.AccountLimitsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	limits, err := h.transactionService.UpdateAccountLimits(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// UpdateAccountTier handles account tier changes
+// @Summary Set account tier
+// @Description Move the account to another tier, whose transfer limits apply from the next transfer on
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param tier body models.AccountTierRequest true "Tier request"
+// @Success 200 {object} models.Account
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/accounts/{account_id}/tier [put]
+func (h *Handler) UpdateAccountTier(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.AccountTierRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	account, err := h.accountService.UpdateTier(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
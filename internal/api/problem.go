@@ -0,0 +1,308 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/auth"
+	"github.com/KaranPal130/transfers-system/internal/importer"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	service "github.com/KaranPal130/transfers-system/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// problemTypeBase prefixes a problem code to form its type URI, which
+// GetProblemType serves.
+const problemTypeBase = "/problems/"
+
+type problemType struct {
+	code   string
+	status int
+	title  string
+	// field is the request field a validation error is usually about. A
+	// service.FieldError overrides it.
+	field string
+}
+
+// Problem types raised by the API layer itself rather than mapped from an
+// error.
+var (
+	problemInvalidBody      = problemType{code: "invalid_request_body", status: http.StatusBadRequest, title: "Invalid request body"}
+	problemInvalidParameter = problemType{code: "invalid_parameter", status: http.StatusBadRequest, title: "Invalid parameter"}
+	problemValidation       = problemType{code: "validation_failed", status: http.StatusBadRequest, title: "Request validation failed"}
+	problemBatchRejected    = problemType{code: "batch_rejected", status: http.StatusUnprocessableEntity, title: "Batch rejected, no legs were posted"}
+	problemNotFound         = problemType{code: "not_found", status: http.StatusNotFound, title: "Not found"}
+	problemInternal         = problemType{code: "internal_error", status: http.StatusInternalServerError, title: "Internal server error"}
+)
+
+// problemTypes is the error registry: every error a handler may meet, with
+// the problem it is reported as. Codes are part of the API and must not
+// change. Errors not listed here are reported as internal_error.
+var problemTypes = []struct {
+	err error
+	problemType
+}{
+	{service.ErrInvalidAccountID, problemType{"invalid_account_id", http.StatusBadRequest, "Invalid account ID", "account_id"}},
+	{service.ErrInvalidInitialBalance, problemType{"invalid_initial_balance", http.StatusBadRequest, "Invalid initial balance", "initial_balance"}},
+	{service.ErrInvalidOverdraftLimit, problemType{"invalid_overdraft_limit", http.StatusBadRequest, "Invalid overdraft limit", "overdraft_limit"}},
+	{service.ErrUnsupportedCurrency, problemType{"unsupported_currency", http.StatusBadRequest, "Unsupported currency", "currency"}},
+	{service.ErrAmountPrecision, problemType{"amount_precision", http.StatusBadRequest, "Amount has more decimal places than the currency allows", "amount"}},
+	{service.ErrAccountAlreadyExists, problemType{"account_already_exists", http.StatusConflict, "Account already exists", ""}},
+	{service.ErrInvalidPagination, problemType{"invalid_pagination", http.StatusBadRequest, "Invalid pagination parameters", ""}},
+	{service.ErrInvalidCursor, problemType{"invalid_cursor", http.StatusBadRequest, "Invalid cursor", "cursor"}},
+	{service.ErrInvalidAccountFilter, problemType{"invalid_filter", http.StatusBadRequest, "Invalid filter", ""}},
+	{service.ErrInvalidTimestamp, problemType{"invalid_timestamp", http.StatusBadRequest, "Invalid timestamp", ""}},
+	{service.ErrInvalidTimeRange, problemType{"invalid_time_range", http.StatusBadRequest, "Invalid time range", ""}},
+	{service.ErrInvalidInterval, problemType{"invalid_interval", http.StatusBadRequest, "Invalid interval", "interval"}},
+	{service.ErrInvalidStatementFormat, problemType{"invalid_format", http.StatusBadRequest, "Invalid format", "format"}},
+	{service.ErrInvalidFreezeMode, problemType{"invalid_freeze_mode", http.StatusBadRequest, "Invalid freeze mode", "mode"}},
+	{service.ErrStatusReasonRequired, problemType{"invalid_status_reason", http.StatusBadRequest, "A reason of at most 500 characters is required", "reason"}},
+	{service.ErrAccountStatusTransition, problemType{"account_status_transition", http.StatusConflict, "Account cannot make that status change", ""}},
+	{service.ErrAccountBalanceNotZero, problemType{"account_balance_not_zero", http.StatusConflict, "Account balance must be zero, or swept to another account, to close it", ""}},
+	{service.ErrAccountHasHolds, problemType{"account_has_holds", http.StatusConflict, "Account has active holds", ""}},
+	{service.ErrInvalidTier, problemType{"invalid_tier", http.StatusBadRequest, "Unknown account tier", "tier"}},
+	{service.ErrTierCurrency, problemType{"tier_currency", http.StatusBadRequest, "Account tier has no limits in the account's currency", "tier"}},
+	{service.ErrInvalidTransferLimits, problemType{"invalid_transfer_limits", http.StatusBadRequest, "Invalid transfer limits", ""}},
+
+	{service.ErrInvalidIdempotencyKey, problemType{"invalid_idempotency_key", http.StatusBadRequest, "Invalid idempotency key", "Idempotency-Key"}},
+	{service.ErrIdempotencyKeyMismatch, problemType{"idempotency_key_mismatch", http.StatusUnprocessableEntity, "Idempotency key was already used with a different request", ""}},
+	{service.ErrIdempotencyKeyConflict, problemType{"idempotency_key_conflict", http.StatusConflict, "A concurrent request with the same idempotency key can no longer be replayed; retry the request", ""}},
+	{service.ErrInvalidAmount, problemType{"invalid_amount", http.StatusBadRequest, "Invalid amount", "amount"}},
+	{service.ErrSameSourceAndDest, problemType{"same_source_and_destination", http.StatusBadRequest, "Source and destination accounts must be different", "destination_account_id"}},
+	{service.ErrInvalidFXMode, problemType{"invalid_fx_mode", http.StatusBadRequest, "Invalid fx_mode", "fx_mode"}},
+	{service.ErrInvalidDescription, problemType{"invalid_description", http.StatusBadRequest, "Invalid description", "description"}},
+	{service.ErrInvalidExternalReference, problemType{"invalid_external_reference", http.StatusBadRequest, "Invalid external reference", "external_reference"}},
+	{service.ErrInvalidMetadata, problemType{"invalid_metadata", http.StatusBadRequest, "Invalid metadata", "metadata"}},
+	{service.ErrDuplicateExternalReference, problemType{"duplicate_external_reference", http.StatusConflict, "External reference was already used by the source account", ""}},
+	{service.ErrCurrencyMismatch, problemType{"currency_mismatch", http.StatusBadRequest, "Source and destination accounts have different currencies", ""}},
+	{service.ErrInsufficientBalance, problemType{"insufficient_balance", http.StatusBadRequest, "Insufficient balance", ""}},
+	{service.ErrAccountFrozen, problemType{"account_frozen", http.StatusConflict, "Account is frozen", ""}},
+	{service.ErrAccountClosed, problemType{"account_closed", http.StatusConflict, "Account is closed", ""}},
+	{service.ErrInvalidBatch, problemType{"invalid_batch", http.StatusBadRequest, "Batch must have between 1 and 1000 legs", "legs"}},
+	{service.ErrInvalidReasonCode, problemType{"invalid_reason_code", http.StatusBadRequest, "Invalid reason code", "reason_code"}},
+	{service.ErrReversalNotAllowed, problemType{"reversal_not_allowed", http.StatusBadRequest, "Reversals cannot be reversed", ""}},
+	{service.ErrReversalExceedsOriginal, problemType{"reversal_exceeds_original", http.StatusBadRequest, "Reversals would exceed the original amount", "amount"}},
+	{service.ErrAmountLimitExceeded, problemType{"amount_limit_exceeded", http.StatusUnprocessableEntity, "Transfer is above the source account's maximum amount", "amount"}},
+	{service.ErrLimitExceeded, problemType{"limit_exceeded", http.StatusTooManyRequests, "Transfer would exceed the source account's limits", "amount"}},
+	{service.ErrTransactionNotPosted, problemType{"transaction_not_posted", http.StatusConflict, "Only posted transactions can be reversed", ""}},
+
+	{service.ErrApprovalRequired, problemType{"approval_required", http.StatusUnprocessableEntity, "Transfers above the approval threshold must be made on their own", "amount"}},
+	{service.ErrNotPendingApproval, problemType{"not_pending_approval", http.StatusConflict, "Transaction is not awaiting approval", ""}},
+	{service.ErrApprovalExpired, problemType{"approval_expired", http.StatusConflict, "Approval window has passed", ""}},
+	{service.ErrSelfApproval, problemType{"self_approval", http.StatusForbidden, "Transfer must be decided by a caller other than the one that made it", ""}},
+	{service.ErrInvalidApprovalReason, problemType{"invalid_approval_reason", http.StatusBadRequest, "Invalid reason", "reason"}},
+
+	{service.ErrRiskDenied, problemType{"risk_denied", http.StatusUnprocessableEntity, "Transfer was denied by risk screening", ""}},
+	{service.ErrRiskReviewRequired, problemType{"risk_review_required", http.StatusUnprocessableEntity, "Transfers held for risk review must be made on their own", "amount"}},
+	{service.ErrInvalidRiskAction, problemType{"invalid_risk_action", http.StatusBadRequest, "Invalid action", "action"}},
+	{service.ErrRiskRulesNotLoaded, problemType{"risk_rules_not_loaded", http.StatusNotFound, "Risk screening is not configured", ""}},
+
+	{service.ErrFXUnavailable, problemType{"fx_unavailable", http.StatusBadRequest, "Currency conversion is not available", ""}},
+	{service.ErrFXRateUnavailable, problemType{"fx_rate_unavailable", http.StatusBadRequest, "Exchange rate unavailable", ""}},
+	{service.ErrSameCurrency, problemType{"same_currency", http.StatusBadRequest, "Source and destination currencies must be different", ""}},
+	{service.ErrFXQuoteMismatch, problemType{"fx_quote_mismatch", http.StatusBadRequest, "FX quote does not match the account currencies", "fx_quote_id"}},
+	{service.ErrFXQuoteExpired, problemType{"fx_quote_expired", http.StatusBadRequest, "FX quote has expired", "fx_quote_id"}},
+	{service.ErrFXQuoteUsed, problemType{"fx_quote_used", http.StatusConflict, "FX quote has already been used", ""}},
+
+	{service.ErrInvalidHoldExpiry, problemType{"invalid_hold_expiry", http.StatusBadRequest, "Invalid hold expiry", "expires_in_seconds"}},
+	{service.ErrCaptureExceedsHold, problemType{"capture_exceeds_hold", http.StatusBadRequest, "Capture amount exceeds the held amount", "amount"}},
+	{service.ErrHoldNotActive, problemType{"hold_not_active", http.StatusConflict, "Hold is no longer active", ""}},
+	{service.ErrHoldExpired, problemType{"hold_expired", http.StatusConflict, "Hold has expired", ""}},
+
+	{service.ErrInvalidFrequency, problemType{"invalid_frequency", http.StatusBadRequest, "Invalid recurrence frequency", "recurrence.frequency"}},
+	{service.ErrInvalidSchedule, problemType{"invalid_schedule", http.StatusBadRequest, "Invalid schedule", ""}},
+	{service.ErrScheduleStatusTransition, problemType{"schedule_status_transition", http.StatusConflict, "Scheduled transfer cannot make that status change", ""}},
+
+	{service.ErrInvalidImportKind, problemType{"invalid_import_kind", http.StatusBadRequest, "Invalid kind", "kind"}},
+	{service.ErrInvalidChunkSize, problemType{"invalid_chunk_size", http.StatusBadRequest, "Invalid chunk_size", "chunk_size"}},
+	{importer.ErrUnknownFormat, problemType{"invalid_format", http.StatusBadRequest, "Invalid format", "format"}},
+
+	{service.ErrUnauthenticated, problemType{"unauthenticated", http.StatusUnauthorized, "Missing, invalid or revoked API key", ""}},
+	{auth.ErrInvalidToken, problemType{"invalid_token", http.StatusUnauthorized, "Invalid bearer token", ""}},
+	{service.ErrInsufficientScope, problemType{"insufficient_scope", http.StatusForbidden, "Caller lacks the scope the request needs", ""}},
+	{service.ErrAccountNotPermitted, problemType{"account_not_permitted", http.StatusForbidden, "Caller may not debit the account", ""}},
+	{service.ErrInvalidAPIKeyName, problemType{"invalid_api_key_name", http.StatusBadRequest, "Invalid API key name", "name"}},
+	{service.ErrInvalidScope, problemType{"invalid_scope", http.StatusBadRequest, "Invalid scope", "scopes"}},
+	{service.ErrInvalidGracePeriod, problemType{"invalid_grace_period", http.StatusBadRequest, "Invalid grace period", "grace_period_seconds"}},
+	{service.ErrAPIKeyInactive, problemType{"api_key_inactive", http.StatusConflict, "API key is revoked or was already rotated", ""}},
+
+	{repository.ErrAccountNotFound, problemType{"account_not_found", http.StatusNotFound, "Account not found", ""}},
+	{repository.ErrTransactionNotFound, problemType{"transaction_not_found", http.StatusNotFound, "Transaction not found", ""}},
+	{repository.ErrBatchNotFound, problemType{"batch_not_found", http.StatusNotFound, "Batch not found", ""}},
+	{repository.ErrFXQuoteNotFound, problemType{"fx_quote_not_found", http.StatusNotFound, "FX quote not found", ""}},
+	{repository.ErrHoldNotFound, problemType{"hold_not_found", http.StatusNotFound, "Hold not found", ""}},
+	{repository.ErrScheduledTransferNotFound, problemType{"scheduled_transfer_not_found", http.StatusNotFound, "Scheduled transfer not found", ""}},
+	{repository.ErrAPIKeyNotFound, problemType{"api_key_not_found", http.StatusNotFound, "API key not found", ""}},
+}
+
+// lookupProblem returns the registered problem type of err. Errors not in the
+// registry are internal errors.
+func lookupProblem(err error) (problemType, bool) {
+	for _, registered := range problemTypes {
+		if errors.Is(err, registered.err) {
+			return registered.problemType, true
+		}
+	}
+	return problemInternal, false
+}
+
+// fieldProblems lists the fields err blames, if it is a validation error.
+// Errors joined with errors.Join are listed one by one.
+func fieldProblems(err error) []models.FieldProblem {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var problems []models.FieldProblem
+		for _, err := range joined.Unwrap() {
+			problems = append(problems, fieldProblems(err)...)
+		}
+		return problems
+	}
+
+	pt, ok := lookupProblem(err)
+	if !ok {
+		return nil
+	}
+
+	field := pt.field
+	var fieldErr *service.FieldError
+	if errors.As(err, &fieldErr) {
+		field = fieldErr.Field
+	}
+	if field == "" {
+		return nil
+	}
+
+	return []models.FieldProblem{{Field: field, Code: pt.code, Detail: pt.title}}
+}
+
+// writeError responds with the problem registered for err. Several joined
+// validation errors are reported together as validation_failed.
+func writeError(c *gin.Context, err error) {
+	pt, ok := lookupProblem(err)
+	if !ok {
+		log.Printf("Request %s: %s %s failed: %v", requestID(c), c.Request.Method, c.Request.URL.Path, err)
+		writeProblem(c, pt, pt.title, nil)
+		return
+	}
+
+	fields := fieldProblems(err)
+	if len(fields) > 1 {
+		writeProblem(c, problemValidation, fmt.Sprintf("%d fields are invalid", len(fields)), fields)
+		return
+	}
+
+	// A limit names itself, and says when to try again if waiting helps.
+	var limitErr *service.LimitError
+	if errors.As(err, &limitErr) {
+		if limitErr.RetryAt != nil {
+			c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(time.Until(*limitErr.RetryAt).Seconds())), 1)))
+		}
+		writeProblem(c, pt, limitErr.Error(), fields)
+		return
+	}
+
+	writeProblem(c, pt, pt.title, fields)
+}
+
+// writeBodyError responds to a request body that could not be decoded,
+// naming the field when the body was JSON of the wrong shape.
+func writeBodyError(c *gin.Context, err error) {
+	detail := "Request body is not valid JSON"
+	var fields []models.FieldProblem
+
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		detail = "Request body is empty"
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		detail = "Request body has a field of the wrong type"
+		fields = []models.FieldProblem{{
+			Field:  typeErr.Field,
+			Code:   "invalid_type",
+			Detail: "must be " + jsonTypeName(typeErr.Type),
+		}}
+	}
+
+	writeProblem(c, problemInvalidBody, detail, fields)
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "a number"
+	}
+}
+
+// writeParamError responds to a path or query parameter that could not be
+// parsed.
+func writeParamError(c *gin.Context, field, detail string) {
+	writeProblem(c, problemInvalidParameter, "Invalid "+field, []models.FieldProblem{{
+		Field:  field,
+		Code:   problemInvalidParameter.code,
+		Detail: detail,
+	}})
+}
+
+func writeProblem(c *gin.Context, pt problemType, detail string, fields []models.FieldProblem) {
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(pt.status, models.Problem{
+		Type:      problemTypeBase + pt.code,
+		Title:     pt.title,
+		Status:    pt.status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      pt.code,
+		RequestID: requestID(c),
+		Errors:    fields,
+	})
+}
+
+// GetProblemType handles problem type lookups
+// @Summary Describe problem type
+// @Description Describe the error code that a problem's type URI ends in
+// @Tags problems
+// @Produce json
+// @Param code path string true "Problem code"
+// @Success 200 {object} models.ProblemType
+// @Failure 404 {object} models.Problem
+// @Router /problems/{code} [get]
+func (h *Handler) GetProblemType(c *gin.Context) {
+	code := c.Param("code")
+
+	types := []problemType{problemInvalidBody, problemInvalidParameter, problemValidation, problemBatchRejected, problemNotFound, problemInternal}
+	for _, registered := range problemTypes {
+		types = append(types, registered.problemType)
+	}
+
+	for _, pt := range types {
+		if pt.code == code {
+			c.JSON(http.StatusOK, models.ProblemType{
+				Type:   problemTypeBase + pt.code,
+				Code:   pt.code,
+				Title:  pt.title,
+				Status: pt.status,
+			})
+			return
+		}
+	}
+
+	writeProblem(c, problemNotFound, "Unknown problem type", nil)
+}
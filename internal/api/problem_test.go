@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	service "github.com/KaranPal130/transfers-system/internal/services"
+)
+
+func TestLimitProblems(t *testing.T) {
+	tests := []struct {
+		limit      string
+		wantCode   string
+		wantStatus int
+	}{
+		{service.LimitMaxAmount, "amount_limit_exceeded", http.StatusUnprocessableEntity},
+		{service.LimitDailyOutbound, "limit_exceeded", http.StatusTooManyRequests},
+		{service.LimitMonthlyOutbound, "limit_exceeded", http.StatusTooManyRequests},
+		{service.LimitMaxCount, "limit_exceeded", http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.limit, func(t *testing.T) {
+			// Batch legs and scheduled runs see the error wrapped.
+			err := fmt.Errorf("leg 0: %w", &service.LimitError{Limit: tt.limit})
+
+			pt, ok := lookupProblem(err)
+			if !ok || pt.code != tt.wantCode || pt.status != tt.wantStatus {
+				t.Errorf("problem = %+v, want %s with %d", pt, tt.wantCode, tt.wantStatus)
+			}
+			if !service.IsTransferRejection(err) {
+				t.Error("not a transfer rejection")
+			}
+		})
+	}
+}
+
+func TestIdempotencyConflictProblem(t *testing.T) {
+	pt, ok := lookupProblem(service.ErrIdempotencyKeyConflict)
+	if !ok || pt.code != "idempotency_key_conflict" || pt.status != http.StatusConflict {
+		t.Errorf("problem = %+v, want idempotency_key_conflict with 409", pt)
+	}
+}
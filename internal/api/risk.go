@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRiskDecisions handles risk decision listing requests
+// @Summary List risk decisions
+// @Description List how transfers were screened against the risk rules, newest first. Denied transfers have no transaction_id, since they were never made.
+// @Tags admin
+// @Produce json
+// @Param source_account_id query int false "Source account ID"
+// @Param transaction_id query int false "Transaction ID"
+// @Param action query string false "allow, review or deny"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Number of decisions to skip"
+// @Success 200 {array} models.RiskDecision
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/risk/decisions [get]
+func (h *Handler) ListRiskDecisions(c *gin.Context) {
+	var req models.RiskDecisionListRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeParamError(c, "query", "source_account_id, transaction_id, limit and offset must be integers")
+		return
+	}
+
+	decisions, err := h.transactionService.ListRiskDecisions(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, decisions)
+}
+
+// GetRiskRules handles risk rules lookups
+// @Summary Get risk rules
+// @Description Describe the version of the risk rules transfers are screened against, which changes when the rules file is edited
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.RiskRules
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/risk/rules [get]
+func (h *Handler) GetRiskRules(c *gin.Context) {
+	rules, err := h.transactionService.RiskRules()
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateScheduledTransfer handles scheduled transfer requests
+// @Summary Schedule transfer
+// @Description Schedule a transfer to run at execute_at (default now) and, with a recurrence, daily, weekly or monthly until end_at or max_occurrences. Funds are checked when each run executes.
+// @Tags scheduled-transfers
+// @Accept json
+// @Produce json
+// @Param schedule body models.ScheduledTransferRequest true "Scheduled transfer request"
+// @Success 201 {object} models.ScheduledTransfer
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /scheduled-transfers [post]
+func (h *Handler) CreateScheduledTransfer(c *gin.Context) {
+	var req models.ScheduledTransferRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+
+	schedule, err := h.scheduleService.CreateSchedule(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// GetScheduledTransfer handles scheduled transfer retrieval requests
+// @Summary Get scheduled transfer
+// @Description Get scheduled transfer by ID
+// @Tags scheduled-transfers
+// @Produce json
+// @Param id path int true "Scheduled transfer ID"
+// @Success 200 {object} models.ScheduledTransfer
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /scheduled-transfers/{id} [get]
+func (h *Handler) GetScheduledTransfer(c *gin.Context) {
+	id, ok := scheduleID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.scheduleService.GetSchedule(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// ListScheduledTransferRuns handles scheduled transfer run listing requests
+// @Summary List scheduled transfer runs
+// @Description List every execution attempt of a scheduled transfer, oldest first
+// @Tags scheduled-transfers
+// @Produce json
+// @Param id path int true "Scheduled transfer ID"
+// @Success 200 {array} models.ScheduledTransferRun
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /scheduled-transfers/{id}/runs [get]
+func (h *Handler) ListScheduledTransferRuns(c *gin.Context) {
+	id, ok := scheduleID(c)
+	if !ok {
+		return
+	}
+
+	runs, err := h.scheduleService.ListRuns(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// PauseScheduledTransfer handles scheduled transfer pause requests
+// @Summary Pause scheduled transfer
+// @Description Stop an active scheduled transfer from running until it is resumed
+// @Tags scheduled-transfers
+// @Produce json
+// @Param id path int true "Scheduled transfer ID"
+// @Success 200 {object} models.ScheduledTransfer
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /scheduled-transfers/{id}/pause [post]
+func (h *Handler) PauseScheduledTransfer(c *gin.Context) {
+	h.changeSchedule(c, h.scheduleService.PauseSchedule)
+}
+
+// ResumeScheduledTransfer handles scheduled transfer resume requests
+// @Summary Resume scheduled transfer
+// @Description Reactivate a paused scheduled transfer. Recurring dates missed while paused are skipped.
+// @Tags scheduled-transfers
+// @Produce json
+// @Param id path int true "Scheduled transfer ID"
+// @Success 200 {object} models.ScheduledTransfer
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /scheduled-transfers/{id}/resume [post]
+func (h *Handler) ResumeScheduledTransfer(c *gin.Context) {
+	h.changeSchedule(c, h.scheduleService.ResumeSchedule)
+}
+
+// CancelScheduledTransfer handles scheduled transfer cancellation requests
+// @Summary Cancel scheduled transfer
+// @Description Stop a scheduled transfer for good
+// @Tags scheduled-transfers
+// @Produce json
+// @Param id path int true "Scheduled transfer ID"
+// @Success 200 {object} models.ScheduledTransfer
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /scheduled-transfers/{id}/cancel [post]
+func (h *Handler) CancelScheduledTransfer(c *gin.Context) {
+	h.changeSchedule(c, h.scheduleService.CancelSchedule)
+}
+
+func (h *Handler) changeSchedule(c *gin.Context, change func(ctx context.Context, id int64) (models.ScheduledTransfer, error)) {
+	id, ok := scheduleID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := change(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func scheduleID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		writeParamError(c, "id", "must be an integer")
+		return 0, false
+	}
+
+	return id, true
+}
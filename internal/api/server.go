@@ -3,6 +3,7 @@ package api
 import (
 	"log"
 
+	"github.com/KaranPal130/transfers-system/internal/auth"
 	"github.com/gin-gonic/gin"
 	ginSwaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -11,12 +12,29 @@ import (
 type Server struct {
 	router  *gin.Engine
 	handler *Handler
+	options ServerOptions
 }
 
-func NewServer(handler *Handler) *Server {
+type ServerOptions struct {
+	// AuthDisabled opens every route. Otherwise each route except the
+	// problem types and the API docs needs an API key or bearer token with
+	// the right scope.
+	AuthDisabled bool
+	// Tokens verifies bearer tokens. Without it only API keys are accepted.
+	Tokens *auth.TokenVerifier
+}
+
+func NewServer(handler *Handler, options ServerOptions) *Server {
+	router := gin.New()
+	router.Use(requestIDMiddleware, gin.Logger(), gin.CustomRecovery(recoverWithProblem))
+	router.NoRoute(func(c *gin.Context) {
+		writeProblem(c, problemNotFound, "No route matches "+c.Request.Method+" "+c.Request.URL.Path, nil)
+	})
+
 	server := &Server{
-		router:  gin.Default(),
+		router:  router,
 		handler: handler,
+		options: options,
 	}
 
 	server.setupRoutes()
@@ -25,9 +43,77 @@ func NewServer(handler *Handler) *Server {
 }
 
 func (s *Server) setupRoutes() {
-	s.router.POST("/accounts", s.handler.CreateAccount)
-	s.router.GET("/accounts/:account_id", s.handler.GetAccount)
-	s.router.POST("/transactions", s.handler.CreateTransaction)
+	read := s.scoped(auth.ScopeAccountsRead)
+	read.GET("/accounts", s.handler.ListAccounts)
+	read.GET("/accounts/:account_id", s.handler.GetAccount)
+	read.GET("/accounts/:account_id/transactions", s.handler.ListAccountTransactions)
+	read.GET("/accounts/:account_id/balance", s.handler.GetAccountBalance)
+	read.GET("/accounts/:account_id/balance-history", s.handler.GetAccountBalanceHistory)
+	read.GET("/accounts/:account_id/statement", s.handler.GetAccountStatement)
+	read.GET("/accounts/:account_id/reconciliation", s.handler.ReconcileAccount)
+	read.GET("/accounts/:account_id/status-history", s.handler.ListAccountStatusChanges)
+	read.GET("/accounts/:account_id/limits", s.handler.GetAccountLimits)
+	read.GET("/transactions", s.handler.FindTransactions)
+	read.GET("/transactions/batch/:id", s.handler.GetBatch)
+	read.GET("/transactions/:id", s.handler.GetTransaction)
+	read.GET("/transactions/:id/reversals", s.handler.ListReversals)
+	read.GET("/transactions/:id/approvals", s.handler.ListApprovalEvents)
+	read.GET("/fx/quotes/:id", s.handler.GetFXQuote)
+	read.GET("/holds/:id", s.handler.GetHold)
+	read.GET("/scheduled-transfers/:id", s.handler.GetScheduledTransfer)
+	read.GET("/scheduled-transfers/:id/runs", s.handler.ListScheduledTransferRuns)
+
+	write := s.scoped(auth.ScopeAccountsWrite)
+	write.POST("/accounts", s.handler.CreateAccount)
+
+	transfers := s.scoped(auth.ScopeTransfersWrite)
+	transfers.POST("/transactions", s.handler.CreateTransaction)
+	transfers.POST("/transactions/batch", s.handler.CreateBatch)
+	transfers.POST("/transactions/:id/reversals", s.handler.ReverseTransaction)
+	transfers.POST("/fx/quotes", s.handler.CreateFXQuote)
+	transfers.POST("/holds", s.handler.CreateHold)
+	transfers.POST("/holds/:id/capture", s.handler.CaptureHold)
+	transfers.POST("/holds/:id/void", s.handler.VoidHold)
+	transfers.POST("/scheduled-transfers", s.handler.CreateScheduledTransfer)
+	transfers.POST("/scheduled-transfers/:id/pause", s.handler.PauseScheduledTransfer)
+	transfers.POST("/scheduled-transfers/:id/resume", s.handler.ResumeScheduledTransfer)
+	transfers.POST("/scheduled-transfers/:id/cancel", s.handler.CancelScheduledTransfer)
+
+	approvals := s.scoped(auth.ScopeTransfersApprove)
+	approvals.POST("/transactions/:id/approve", s.handler.ApproveTransaction)
+	approvals.POST("/transactions/:id/reject", s.handler.RejectTransaction)
+
+	admin := s.scoped(auth.ScopeAdmin)
+	admin.PUT("/admin/accounts/:account_id/overdraft", s.handler.UpdateOverdraftLimit)
+	admin.PUT("/admin/accounts/:account_id/limits", s.handler.UpdateAccountLimits)
+	admin.PUT("/admin/accounts/:account_id/tier", s.handler.UpdateAccountTier)
+	admin.POST("/admin/accounts/:account_id/freeze", s.handler.FreezeAccount)
+	admin.POST("/admin/accounts/:account_id/unfreeze", s.handler.UnfreezeAccount)
+	admin.POST("/admin/accounts/:account_id/close", s.handler.CloseAccount)
+	admin.POST("/admin/imports", s.handler.ImportFile)
+	admin.POST("/admin/api-keys", s.handler.CreateAPIKey)
+	admin.GET("/admin/api-keys", s.handler.ListAPIKeys)
+	admin.POST("/admin/api-keys/:id/rotate", s.handler.RotateAPIKey)
+	admin.POST("/admin/api-keys/:id/revoke", s.handler.RevokeAPIKey)
+	admin.GET("/admin/risk/decisions", s.handler.ListRiskDecisions)
+	admin.GET("/admin/risk/rules", s.handler.GetRiskRules)
+
+	s.router.GET("/problems/:code", s.handler.GetProblemType)
+}
+
+// scoped returns a group of routes that need scope, or no restriction at all
+// when authentication is disabled.
+func (s *Server) scoped(scope string) *gin.RouterGroup {
+	if s.options.AuthDisabled {
+		return s.router.Group("")
+	}
+	return s.router.Group("", s.authenticate, requireScope(scope))
+}
+
+// recoverWithProblem answers a request whose handler panicked. gin has
+// already logged the panic.
+func recoverWithProblem(c *gin.Context, _ any) {
+	writeProblem(c, problemInternal, problemInternal.title, nil)
 }
 
 func (s *Server) Start(addr string) error {
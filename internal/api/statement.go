@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/KaranPal130/transfers-system/internal/statement"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAccountStatement handles account statement requests
+// @Summary Export account statement
+// @Description Stream a statement of the transactions booked after from and up to to, between the opening and closing balances, as CSV, JSON Lines or ISO 20022 camt.053 XML
+// @Tags accounts
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/xml
+// @Param account_id path int true "Account ID"
+// @Param from query string false "RFC 3339 start, exclusive (default 30 days before to)"
+// @Param to query string false "RFC 3339 end, inclusive (default now)"
+// @Param format query string false "csv (default), jsonl or camt053"
+// @Success 200 {string} string "Statement"
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /accounts/{account_id}/statement [get]
+func (h *Handler) GetAccountStatement(c *gin.Context) {
+	accountID, ok := accountIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.StatementRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeProblem(c, problemInvalidParameter, "Invalid query parameters", nil)
+		return
+	}
+
+	st, err := h.statementService.PrepareStatement(c.Request.Context(), accountID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s.%s", accountID, st.To.UTC().Format("20060102"), statement.FileExtension(st.Format))
+	c.Header("Content-Type", statement.ContentType(st.Format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	if err := h.statementService.WriteStatement(c.Request.Context(), st, c.Writer); err != nil {
+		// The status line is already out, so all that is left is to cut the
+		// statement short.
+		log.Printf("Statement for account %d failed: %v", accountID, err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,58 @@
+// Package auth describes who is calling the API and what they may do.
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Scopes an API caller can be granted. ScopeAdmin implies every other scope.
+const (
+	ScopeAccountsRead   = "accounts:read"
+	ScopeAccountsWrite  = "accounts:write"
+	ScopeTransfersWrite = "transfers:write"
+	// ScopeTransfersApprove lets a caller approve or reject transfers
+	// awaiting approval.
+	ScopeTransfersApprove = "transfers:approve"
+	ScopeAdmin            = "admin"
+)
+
+var scopes = []string{ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersWrite, ScopeTransfersApprove, ScopeAdmin}
+
+// ValidScope reports whether scope is one of the known scopes.
+func ValidScope(scope string) bool {
+	return slices.Contains(scopes, scope)
+}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// ID names the caller in audit fields such as a transaction's
+	// initiated_by, for example "api_key:42".
+	ID     string
+	Scopes []string
+	// AccountIDs are the only accounts the caller may debit. Empty means
+	// any account.
+	AccountIDs []int64
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+func (p Principal) CanDebit(accountID int64) bool {
+	return len(p.AccountIDs) == 0 || slices.Contains(p.AccountIDs, accountID)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, if any. Work the service
+// starts on its own, such as a scheduled run, has none.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
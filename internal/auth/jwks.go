@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultJWKSRefresh = time.Hour
+
+	// minJWKSReload bounds how often a token signed with an unknown key can
+	// make the key set reload, so bogus tokens cannot flood the source.
+	minJWKSReload = 30 * time.Second
+	maxJWKSSize   = 1 << 20
+)
+
+// jwk is a verification key from a key set. alg is empty when the set does
+// not pin the key to one algorithm.
+type jwk struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// KeySet is a JSON Web Key Set read from a file or URL. It is read again
+// every refresh interval, and early when a token names a key it does not
+// hold, so keys the identity provider rotates in are picked up without a
+// restart. A failed reload keeps the keys already loaded.
+type KeySet struct {
+	load    func(ctx context.Context) ([]byte, error)
+	refresh time.Duration
+
+	// reload serialises reloads so that concurrent requests share one.
+	reload    sync.Mutex
+	mu        sync.RWMutex
+	keys      map[string]jwk
+	loadedAt  time.Time
+	attemptAt time.Time
+}
+
+// NewFileKeySet loads a key set from path.
+func NewFileKeySet(ctx context.Context, path string, refresh time.Duration) (*KeySet, error) {
+	return newKeySet(ctx, refresh, func(context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	})
+}
+
+// NewURLKeySet loads a key set from url, such as an identity provider's
+// jwks_uri.
+func NewURLKeySet(ctx context.Context, url string, refresh time.Duration) (*KeySet, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	return newKeySet(ctx, refresh, func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+		}
+
+		return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	})
+}
+
+func newKeySet(ctx context.Context, refresh time.Duration, load func(ctx context.Context) ([]byte, error)) (*KeySet, error) {
+	ks := &KeySet{load: load, refresh: refresh}
+	if err := ks.loadKeys(ctx); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// key returns the key named kid. An empty kid matches the only key of a set
+// holding just one.
+func (ks *KeySet) key(ctx context.Context, kid string) (jwk, bool) {
+	ks.mu.RLock()
+	key, ok := ks.lookup(kid)
+	stale := time.Since(ks.loadedAt) > ks.refresh
+	canRetry := time.Since(ks.attemptAt) > minJWKSReload
+	ks.mu.RUnlock()
+
+	if (ok && !stale) || !canRetry {
+		return key, ok
+	}
+
+	ks.reload.Lock()
+	defer ks.reload.Unlock()
+
+	// Another request may have reloaded the set while this one waited.
+	ks.mu.RLock()
+	reloaded := time.Since(ks.attemptAt) <= minJWKSReload
+	ks.mu.RUnlock()
+
+	if !reloaded {
+		if err := ks.loadKeys(ctx); err != nil {
+			log.Printf("Failed to reload JWKS, keeping the loaded keys: %v", err)
+		}
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.lookup(kid)
+}
+
+func (ks *KeySet) lookup(kid string) (jwk, bool) {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func (ks *KeySet) loadKeys(ctx context.Context) error {
+	ks.mu.Lock()
+	ks.attemptAt = time.Now()
+	ks.mu.Unlock()
+
+	body, err := ks.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(body)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.loadedAt = time.Now()
+	ks.mu.Unlock()
+	return nil
+}
+
+type rawJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the signature keys of a key set by kid. Encryption keys
+// and key types it does not support are skipped, as RFC 7517 allows.
+func parseJWKS(body []byte) (map[string]jwk, error) {
+	var set struct {
+		Keys []rawJWK `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]jwk, len(set.Keys))
+	for _, raw := range set.Keys {
+		if raw.Use == "enc" {
+			continue
+		}
+
+		key, err := parseJWK(raw)
+		if errors.Is(err, errUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", raw.Kid, err)
+		}
+
+		keys[raw.Kid] = jwk{alg: raw.Alg, key: key}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS holds no signature keys")
+	}
+
+	return keys, nil
+}
+
+var errUnsupportedKey = errors.New("unsupported key type")
+
+func parseJWK(raw rawJWK) (crypto.PublicKey, error) {
+	switch raw.Kty {
+	case "RSA":
+		n, err := decodeBigInt(raw.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(raw.E)
+		if err != nil {
+			return nil, err
+		}
+		if n.BitLen() < 2048 || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("weak or malformed RSA key")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		curve, ok := curves[raw.Crv]
+		if !ok {
+			return nil, errUnsupportedKey
+		}
+		x, err := decodeBigInt(raw.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(raw.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if raw.Crv != "Ed25519" {
+			return nil, errUnsupportedKey
+		}
+		x, err := base64.RawURLEncoding.DecodeString(raw.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("malformed Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, errUnsupportedKey
+	}
+}
+
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("malformed key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
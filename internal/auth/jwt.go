@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken reports a bearer token that failed verification. The
+// wrapping error says why.
+var ErrInvalidToken = errors.New("invalid token")
+
+const (
+	// tokenLeeway absorbs clock skew between the issuer and this server.
+	tokenLeeway = time.Minute
+	// maxSubjectLength keeps jwt:<sub> within initiated_by.
+	maxSubjectLength = 255
+)
+
+// TokenVerifier checks OIDC-style JWTs signed by a key in its key set and
+// issued by issuer for audience.
+type TokenVerifier struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+}
+
+func NewTokenVerifier(keys *KeySet, issuer, audience string) *TokenVerifier {
+	return &TokenVerifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+	}
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type tokenClaims struct {
+	Issuer    string          `json:"iss"`
+	Subject   string          `json:"sub"`
+	Audience  audience        `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scp       json.RawMessage `json:"scp"`
+	// AccountIDs restricts the accounts the caller may debit, like an API
+	// key's account_ids.
+	AccountIDs []int64 `json:"account_ids"`
+}
+
+// audience is the aud claim, which may be a single string or a list.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return errors.New("aud must be a string or a list of strings")
+	}
+	*a = many
+	return nil
+}
+
+// Verify checks the token's signature, issuer, audience and validity period
+// and returns the principal it describes. Scopes come from the space
+// separated scope claim or the scp list; ones this service does not know,
+// such as openid, are ignored.
+func (v *TokenVerifier) Verify(ctx context.Context, token string) (Principal, error) {
+	claims, err := v.verify(ctx, token)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	var scopes []string
+	for _, scope := range claims.scopes() {
+		if ValidScope(scope) && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return Principal{
+		ID:         "jwt:" + claims.Subject,
+		Scopes:     scopes,
+		AccountIDs: claims.AccountIDs,
+	}, nil
+}
+
+func (v *TokenVerifier) verify(ctx context.Context, token string) (tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return tokenClaims{}, errors.New("malformed token")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return tokenClaims{}, errors.New("malformed token header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return tokenClaims{}, errors.New("malformed token signature")
+	}
+
+	key, ok := v.keys.key(ctx, header.Kid)
+	if !ok {
+		return tokenClaims{}, fmt.Errorf("unknown signing key %q", header.Kid)
+	}
+
+	if key.alg != "" && key.alg != header.Alg {
+		return tokenClaims{}, errors.New("token algorithm does not match its key")
+	}
+
+	if err := verifySignature(header.Alg, key.key, parts[0]+"."+parts[1], signature); err != nil {
+		return tokenClaims{}, err
+	}
+
+	var claims tokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return tokenClaims{}, errors.New("malformed token claims")
+	}
+
+	now := time.Now()
+	switch {
+	case claims.Issuer != v.issuer:
+		return tokenClaims{}, errors.New("unexpected issuer")
+	case !slices.Contains(claims.Audience, v.audience):
+		return tokenClaims{}, errors.New("token is not meant for this audience")
+	case claims.ExpiresAt == nil:
+		return tokenClaims{}, errors.New("token has no expiry")
+	case now.Add(-tokenLeeway).After(numericDate(*claims.ExpiresAt)):
+		return tokenClaims{}, errors.New("token has expired")
+	case claims.NotBefore != nil && now.Add(tokenLeeway).Before(numericDate(*claims.NotBefore)):
+		return tokenClaims{}, errors.New("token is not valid yet")
+	case claims.Subject == "" || len(claims.Subject) > maxSubjectLength:
+		return tokenClaims{}, errors.New("token has no valid subject")
+	}
+
+	return claims, nil
+}
+
+func (c tokenClaims) scopes() []string {
+	scopes := strings.Fields(c.Scope)
+
+	var list []string
+	if err := json.Unmarshal(c.Scp, &list); err == nil {
+		return append(scopes, list...)
+	}
+
+	var spaced string
+	if err := json.Unmarshal(c.Scp, &spaced); err == nil {
+		return append(scopes, strings.Fields(spaced)...)
+	}
+
+	return scopes
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func numericDate(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// ecdsaCurves is the curve each ES algorithm is defined for.
+var ecdsaCurves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+	"ES512": "P-521",
+}
+
+// verifySignature checks a JWS signature. alg none, and any algorithm that
+// does not suit the key, is refused.
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	invalid := errors.New("invalid token signature")
+
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(pub, []byte(signingInput), signature) {
+			return invalid
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported token algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		var err error
+		switch alg[0] {
+		case 'R':
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+		case 'P':
+			err = rsa.VerifyPSS(pub, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		default:
+			return invalid
+		}
+		if err != nil {
+			return invalid
+		}
+		return nil
+
+	case *ecdsa.PublicKey:
+		if pub.Curve.Params().Name != ecdsaCurves[alg] {
+			return invalid
+		}
+
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return invalid
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return invalid
+		}
+		return nil
+
+	default:
+		return invalid
+	}
+}
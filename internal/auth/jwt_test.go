@@ -0,0 +1,370 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://issuer.example"
+	testAudience = "transfers-api"
+)
+
+var (
+	testKeysOnce sync.Once
+	testRSAKey   *rsa.PrivateKey
+	testECKey    *ecdsa.PrivateKey
+	testEdKey    ed25519.PrivateKey
+)
+
+// testKeys generates the keys once, since RSA key generation is slow.
+func testKeys(t *testing.T) (*rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	testKeysOnce.Do(func() {
+		var err error
+		if testRSAKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			panic(err)
+		}
+		if testECKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			panic(err)
+		}
+		if _, testEdKey, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			panic(err)
+		}
+	})
+
+	return testRSAKey, testECKey, testEdKey
+}
+
+// publicJWK encodes the public half of key as a JWK named kid.
+func publicJWK(kid, alg string, key crypto.Signer) map[string]string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := map[string]string{"kid": kid, "use": "sig"}
+	if alg != "" {
+		jwk["alg"] = alg
+	}
+
+	switch pub := key.Public().(type) {
+	case *rsa.PublicKey:
+		jwk["kty"] = "RSA"
+		jwk["n"] = b64(pub.N.Bytes())
+		jwk["e"] = b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk["kty"] = "EC"
+		jwk["crv"] = pub.Curve.Params().Name
+		jwk["x"] = b64(pub.X.FillBytes(make([]byte, size)))
+		jwk["y"] = b64(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk["kty"] = "OKP"
+		jwk["crv"] = "Ed25519"
+		jwk["x"] = b64(pub)
+	}
+
+	return jwk
+}
+
+func jwks(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// signToken signs claims with key under the given header alg, whether or not
+// the two suit each other.
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256Sum(signingInput)
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest)
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, sha256Sum(signingInput))
+		if err == nil {
+			size := (k.Curve.Params().BitSize + 7) / 8
+			signature = make([]byte, 2*size)
+			r.FillBytes(signature[:size])
+			s.FillBytes(signature[size:])
+		}
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, []byte(signingInput))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func sha256Sum(s string) []byte {
+	h := crypto.SHA256.New()
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+func validClaims() map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":         testIssuer,
+		"sub":         "user-1",
+		"aud":         testAudience,
+		"exp":         now.Add(time.Hour).Unix(),
+		"nbf":         now.Add(-time.Minute).Unix(),
+		"scope":       "openid transfers:write",
+		"account_ids": []int64{7},
+	}
+}
+
+func withClaims(changes map[string]any) map[string]any {
+	claims := validClaims()
+	for name, value := range changes {
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+	}
+	return claims
+}
+
+// testKeySet serves whichever body is current, counting loads.
+type testKeySet struct {
+	mu    sync.Mutex
+	body  []byte
+	loads int
+}
+
+func (s *testKeySet) set(body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+}
+
+func (s *testKeySet) load(context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	return s.body, nil
+}
+
+func (s *testKeySet) loadCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loads
+}
+
+func newTestVerifier(t *testing.T, source *testKeySet) (*TokenVerifier, *KeySet) {
+	t.Helper()
+
+	keys, err := newKeySet(context.Background(), time.Hour, source.load)
+	if err != nil {
+		t.Fatalf("load key set: %v", err)
+	}
+	return NewTokenVerifier(keys, testIssuer, testAudience), keys
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, ecKey, edKey := testKeys(t)
+
+	source := &testKeySet{body: jwks(t,
+		publicJWK("rsa", "", rsaKey),
+		publicJWK("ec", "ES256", ecKey),
+		publicJWK("ed", "", edKey),
+		publicJWK("pinned", "PS256", rsaKey),
+	)}
+	verifier, _ := newTestVerifier(t, source)
+
+	now := time.Now()
+	noneToken := func() string {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"rsa"}`))
+		payload, _ := json.Marshal(validClaims())
+		return header + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "RS256", token: signToken(t, "RS256", "rsa", rsaKey, validClaims())},
+		{name: "ES256", token: signToken(t, "ES256", "ec", ecKey, validClaims())},
+		{name: "EdDSA", token: signToken(t, "EdDSA", "ed", edKey, validClaims())},
+		{name: "audience list", token: signToken(t, "RS256", "rsa", rsaKey, withClaims(map[string]any{"aud": []string{"other", testAudience}}))},
+		{name: "expired within leeway", token: signToken(t, "RS256", "rsa", rsaKey, withClaims(map[string]any{"exp": now.Add(-tokenLeeway / 2).Unix()}))},
+
+		{name: "expired", token: signToken(t, "RS256", "rsa", rsaKey, withClaims(map[string]any{"exp": now.Add(-2 * tokenLeeway).Unix()})), wantErr: "token has expired"},
+		{name: "not valid yet", token: signToken(t, "RS256", "rsa", rsaKey, withClaims(map[string]any{"nbf": now.Add(2 * tokenLeeway).Unix()})), wantErr: "token is not valid yet"},
+		{name: "no expiry", token: signToken(t, "RS256", "rsa", rsaKey, withClaims(map[string]any{"exp": nil})), wantErr: "token has no expiry"},
+		{name: "wrong issuer", token: signToken(t, "RS256", "rsa", rsaKey, withClaims(map[string]any{"iss": "https://evil.example"})), wantErr: "unexpected issuer"},
+		{name: "wrong audience", token: signToken(t, "RS256", "rsa", rsaKey, withClaims(map[string]any{"aud": "other"})), wantErr: "not meant for this audience"},
+		{name: "no subject", token: signToken(t, "RS256", "rsa", rsaKey, withClaims(map[string]any{"sub": nil})), wantErr: "no valid subject"},
+
+		{name: "alg none", token: noneToken(), wantErr: `unsupported token algorithm "none"`},
+		{name: "HMAC with an RSA key", token: signToken(t, "HS256", "rsa", rsaKey, validClaims()), wantErr: `unsupported token algorithm "HS256"`},
+		{name: "ES256 header on an RSA key", token: signToken(t, "ES256", "rsa", rsaKey, validClaims()), wantErr: "invalid token signature"},
+		{name: "RS256 header on an EC key", token: signToken(t, "RS256", "ed", edKey, validClaims()), wantErr: "invalid token signature"},
+		{name: "alg other than the key's", token: signToken(t, "RS256", "pinned", rsaKey, validClaims()), wantErr: "does not match its key"},
+		{name: "signed by another key", token: signToken(t, "ES256", "ec", mustECKey(t), validClaims()), wantErr: "invalid token signature"},
+		{name: "malformed", token: "not-a-token", wantErr: "malformed token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(context.Background(), tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				if principal.ID != "jwt:user-1" {
+					t.Errorf("ID = %q, want jwt:user-1", principal.ID)
+				}
+				if len(principal.Scopes) != 1 || principal.Scopes[0] != ScopeTransfersWrite {
+					t.Errorf("Scopes = %v, want only %s", principal.Scopes, ScopeTransfersWrite)
+				}
+				if len(principal.AccountIDs) != 1 || principal.AccountIDs[0] != 7 {
+					t.Errorf("AccountIDs = %v, want [7]", principal.AccountIDs)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidToken) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want ErrInvalidToken saying %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func mustECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestUnknownKeyReloadIsRateLimited(t *testing.T) {
+	_, ecKey, _ := testKeys(t)
+	newKey := mustECKey(t)
+
+	source := &testKeySet{body: jwks(t, publicJWK("old", "", ecKey))}
+	verifier, keys := newTestVerifier(t, source)
+
+	// The set was just loaded, so an unknown kid does not load it again.
+	token := signToken(t, "ES256", "new", newKey, validClaims())
+	if _, err := verifier.Verify(context.Background(), token); err == nil || !strings.Contains(err.Error(), "unknown signing key") {
+		t.Fatalf("err = %v, want unknown signing key", err)
+	}
+	if got := source.loadCount(); got != 1 {
+		t.Fatalf("loads = %d, want 1", got)
+	}
+
+	// Once the last attempt is old enough, an unknown kid reloads the set
+	// and finds the key rotated in.
+	source.set(jwks(t, publicJWK("old", "", ecKey), publicJWK("new", "", newKey)))
+	keys.mu.Lock()
+	keys.attemptAt = time.Now().Add(-2 * minJWKSReload)
+	keys.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), token); err != nil {
+		t.Fatalf("Verify after reload: %v", err)
+	}
+	if got := source.loadCount(); got != 2 {
+		t.Fatalf("loads = %d, want 2", got)
+	}
+
+	// Bogus kids right after that do not reload again.
+	for range 5 {
+		bogus := signToken(t, "ES256", "bogus", newKey, validClaims())
+		if _, err := verifier.Verify(context.Background(), bogus); err == nil {
+			t.Fatal("token with an unknown kid verified")
+		}
+	}
+	if got := source.loadCount(); got != 2 {
+		t.Errorf("loads = %d after bogus kids, want 2", got)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	_, oldKey, _ := testKeys(t)
+	newKey := mustECKey(t)
+
+	source := &testKeySet{body: jwks(t, publicJWK("old", "", oldKey))}
+	verifier, keys := newTestVerifier(t, source)
+
+	oldToken := signToken(t, "ES256", "old", oldKey, validClaims())
+	newToken := signToken(t, "ES256", "new", newKey, validClaims())
+
+	if _, err := verifier.Verify(context.Background(), oldToken); err != nil {
+		t.Fatalf("old key before rotation: %v", err)
+	}
+
+	// The provider retires the old key; the set is due for its periodic
+	// refresh.
+	source.set(jwks(t, publicJWK("new", "", newKey)))
+	keys.mu.Lock()
+	keys.loadedAt = time.Now().Add(-2 * keys.refresh)
+	keys.attemptAt = time.Now().Add(-2 * minJWKSReload)
+	keys.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), oldToken); err == nil {
+		t.Error("token signed by the retired key still verified")
+	}
+	if _, err := verifier.Verify(context.Background(), newToken); err != nil {
+		t.Errorf("new key after rotation: %v", err)
+	}
+}
+
+func TestFailedReloadKeepsKeys(t *testing.T) {
+	_, ecKey, _ := testKeys(t)
+
+	source := &testKeySet{body: jwks(t, publicJWK("k", "", ecKey))}
+	verifier, keys := newTestVerifier(t, source)
+
+	source.set([]byte("not json"))
+	keys.mu.Lock()
+	keys.loadedAt = time.Now().Add(-2 * keys.refresh)
+	keys.attemptAt = time.Now().Add(-2 * minJWKSReload)
+	keys.mu.Unlock()
+
+	token := signToken(t, "ES256", "k", ecKey, validClaims())
+	if _, err := verifier.Verify(context.Background(), token); err != nil {
+		t.Fatalf("Verify after a failed reload: %v", err)
+	}
+	if got := source.loadCount(); got != 2 {
+		t.Errorf("loads = %d, want 2", got)
+	}
+}
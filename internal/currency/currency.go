@@ -0,0 +1,90 @@
+// Package currency holds the ISO 4217 currencies the ledger supports and their
+// minor-unit precision.
+package currency
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+type Currency struct {
+	Code string
+	// MinorUnits is the number of decimal places amounts may carry.
+	MinorUnits int32
+}
+
+// minorUnits maps supported ISO 4217 codes to their minor units.
+var minorUnits = map[string]int32{
+	"AED": 2,
+	"ARS": 2,
+	"AUD": 2,
+	"BHD": 3,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CLP": 0,
+	"CNY": 2,
+	"COP": 2,
+	"CZK": 2,
+	"DKK": 2,
+	"EGP": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"HUF": 2,
+	"IDR": 2,
+	"ILS": 2,
+	"INR": 2,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KES": 2,
+	"KRW": 0,
+	"KWD": 3,
+	"MXN": 2,
+	"MYR": 2,
+	"NGN": 2,
+	"NOK": 2,
+	"NZD": 2,
+	"OMR": 3,
+	"PHP": 2,
+	"PKR": 2,
+	"PLN": 2,
+	"QAR": 2,
+	"RON": 2,
+	"SAR": 2,
+	"SEK": 2,
+	"SGD": 2,
+	"THB": 2,
+	"TND": 3,
+	"TRY": 2,
+	"TWD": 2,
+	"UAH": 2,
+	"USD": 2,
+	"VND": 0,
+	"ZAR": 2,
+}
+
+// Lookup returns the currency for an ISO 4217 code, ignoring case.
+func Lookup(code string) (Currency, bool) {
+	code = strings.ToUpper(code)
+
+	units, ok := minorUnits[code]
+	if !ok {
+		return Currency{}, false
+	}
+
+	return Currency{Code: code, MinorUnits: units}, true
+}
+
+// Fits reports whether amount has no more decimal places than the currency
+// allows.
+func (c Currency) Fits(amount decimal.Decimal) bool {
+	return amount.Equal(amount.Truncate(c.MinorUnits))
+}
+
+// Round rounds amount half away from zero to the currency's minor units.
+func (c Currency) Round(amount decimal.Decimal) decimal.Decimal {
+	return amount.Round(c.MinorUnits)
+}
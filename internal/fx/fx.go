@@ -0,0 +1,100 @@
+// Package fx provides exchange rates for cross-currency transfers.
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/KaranPal130/transfers-system/internal/currency"
+	"github.com/shopspring/decimal"
+)
+
+// inversePrecision is the number of decimal places kept when a rate is derived
+// by inverting the opposite pair.
+const inversePrecision = 10
+
+var (
+	ErrRateUnavailable = errors.New("exchange rate unavailable")
+)
+
+// RateProvider returns how many units of the to currency one unit of the from
+// currency buys.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (decimal.Decimal, error)
+}
+
+// StaticProvider serves rates from a fixed table, so it works offline. A pair
+// missing from the table is derived from its inverse when that is present.
+type StaticProvider struct {
+	rates map[string]decimal.Decimal
+}
+
+// NewStaticProvider builds a provider from rates keyed by "FROM/TO", for
+// example {"USD/EUR": 0.92}.
+func NewStaticProvider(rates map[string]decimal.Decimal) (*StaticProvider, error) {
+	provider := &StaticProvider{
+		rates: make(map[string]decimal.Decimal, len(rates)),
+	}
+
+	for pair, rate := range rates {
+		from, to, ok := strings.Cut(strings.ToUpper(pair), "/")
+		if !ok {
+			return nil, fmt.Errorf("rate %q: expected FROM/TO", pair)
+		}
+
+		if _, ok := currency.Lookup(from); !ok {
+			return nil, fmt.Errorf("rate %q: unsupported currency %s", pair, from)
+		}
+		if _, ok := currency.Lookup(to); !ok {
+			return nil, fmt.Errorf("rate %q: unsupported currency %s", pair, to)
+		}
+
+		if !rate.IsPositive() {
+			return nil, fmt.Errorf("rate %q: must be positive", pair)
+		}
+
+		provider.rates[from+"/"+to] = rate
+	}
+
+	return provider, nil
+}
+
+// LoadStaticProvider reads a JSON rate table such as
+//
+//	{"rates": {"USD/EUR": "0.92", "EUR/GBP": "0.85"}}
+func LoadStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Rates map[string]decimal.Decimal `json:"rates"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return NewStaticProvider(file.Rates)
+}
+
+func (p *StaticProvider) Rate(ctx context.Context, from, to string) (decimal.Decimal, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+
+	if rate, ok := p.rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+
+	if rate, ok := p.rates[to+"/"+from]; ok {
+		return decimal.NewFromInt(1).DivRound(rate, inversePrecision), nil
+	}
+
+	return decimal.Zero, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, from, to)
+}
@@ -0,0 +1,172 @@
+// Package importer reads bulk import files: CSV with a header row, or JSON
+// Lines holding one flat object per line. Either way each row comes out as a
+// record of named string fields, read one at a time.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// MaxLineLength bounds a JSON Lines row.
+const MaxLineLength = 1 << 20
+
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Record is one row of an import file.
+type Record struct {
+	// Line is the line the row starts on, counting from 1.
+	Line   int
+	Fields map[string]string
+	// Err is set when the row could not be parsed. The reader carries on with
+	// the next row.
+	Err error
+}
+
+// Reader returns the records of a file in order, then io.EOF. Any other error
+// means the file cannot be read further.
+type Reader interface {
+	Next() (Record, error)
+}
+
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		return newJSONLReader(r), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// FormatFromName returns the format a file name's extension implies.
+func FormatFromName(name string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV, true
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, true
+	default:
+		return "", false
+	}
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header row: %w", err)
+	}
+
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\uFEFF")
+		}
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	return &csvReader{r: cr, header: header}, nil
+}
+
+func (r *csvReader) Next() (Record, error) {
+	values, err := r.r.Read()
+	if err == io.EOF {
+		return Record{}, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Record{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	line, _ := r.r.FieldPos(0)
+	record := Record{Line: line, Fields: make(map[string]string, len(values))}
+	for i, value := range values {
+		record.Fields[r.header[i]] = strings.TrimSpace(value)
+	}
+
+	return record, nil
+}
+
+type jsonlReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), MaxLineLength)
+	return &jsonlReader{s: s}
+}
+
+func (r *jsonlReader) Next() (Record, error) {
+	for r.s.Scan() {
+		r.line++
+
+		data := bytes.TrimSpace(r.s.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		record := Record{Line: r.line}
+
+		var object map[string]any
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&object); err != nil {
+			record.Err = fmt.Errorf("invalid JSON: %w", err)
+			return record, nil
+		}
+
+		record.Fields = make(map[string]string, len(object))
+		for name, value := range object {
+			switch value := value.(type) {
+			case nil:
+			case string:
+				record.Fields[name] = strings.TrimSpace(value)
+			case json.Number:
+				record.Fields[name] = value.String()
+			case bool:
+				record.Fields[name] = strconv.FormatBool(value)
+			default:
+				record.Err = fmt.Errorf("field %s must be a string, number or boolean", name)
+				return record, nil
+			}
+		}
+
+		return record, nil
+	}
+
+	if err := r.s.Err(); err != nil {
+		return Record{}, err
+	}
+
+	return Record{}, io.EOF
+}
@@ -0,0 +1,181 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readAll returns every record of the file, failing the test on an error
+// that stops the reader.
+func readAll(t *testing.T, format, data string) []Record {
+	t.Helper()
+
+	r, err := NewReader(strings.NewReader(data), format)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+
+	var records []Record
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestReaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   []Record
+		// wantErrs holds the text of each record's error, or "" for none.
+		wantErrs []string
+	}{
+		{
+			name:   "csv header is normalized",
+			format: FormatCSV,
+			data:   "\uFEFF Account_ID ,INITIAL_BALANCE\n1, 10 \n",
+			want: []Record{
+				{Line: 2, Fields: map[string]string{"account_id": "1", "initial_balance": "10"}},
+			},
+			wantErrs: []string{""},
+		},
+		{
+			name:   "csv rows spanning lines",
+			format: FormatCSV,
+			data:   "id,description\n1,\"two\nlines\"\n2,one\n",
+			want: []Record{
+				{Line: 2, Fields: map[string]string{"id": "1", "description": "two\nlines"}},
+				{Line: 4, Fields: map[string]string{"id": "2", "description": "one"}},
+			},
+			wantErrs: []string{"", ""},
+		},
+		{
+			name:   "csv parse errors skip the row",
+			format: FormatCSV,
+			data:   "id,amount\n1,5\"0\n2\n3,7\n",
+			want: []Record{
+				{Line: 2},
+				{Line: 3},
+				{Line: 4, Fields: map[string]string{"id": "3", "amount": "7"}},
+			},
+			wantErrs: []string{csv.ErrBareQuote.Error(), csv.ErrFieldCount.Error(), ""},
+		},
+		{
+			name:   "jsonl values",
+			format: FormatJSONL,
+			data:   `{"id": 1, "amount": "  2.50 ", "flag": true, "note": null}` + "\n",
+			want: []Record{
+				{Line: 1, Fields: map[string]string{"id": "1", "amount": "2.50", "flag": "true"}},
+			},
+			wantErrs: []string{""},
+		},
+		{
+			name:   "jsonl blank lines are counted",
+			format: FormatJSONL,
+			data:   "\n{\"id\": 1}\n   \n\n{\"id\": 2.5}",
+			want: []Record{
+				{Line: 2, Fields: map[string]string{"id": "1"}},
+				{Line: 5, Fields: map[string]string{"id": "2.5"}},
+			},
+			wantErrs: []string{"", ""},
+		},
+		{
+			name:   "jsonl bad rows",
+			format: FormatJSONL,
+			data:   "{\"id\": 1\n[1, 2]\n{\"id\": {\"n\": 1}}\n{\"id\": 4}\n",
+			want: []Record{
+				{Line: 1},
+				{Line: 2},
+				{Line: 3},
+				{Line: 4, Fields: map[string]string{"id": "4"}},
+			},
+			wantErrs: []string{
+				"invalid JSON: unexpected EOF",
+				"invalid JSON: json: cannot unmarshal array into Go value of type map[string]interface {}",
+				"field id must be a string, number or boolean",
+				"",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := readAll(t, tt.format, tt.data)
+			if len(records) != len(tt.want) {
+				t.Fatalf("got %d records %+v, want %d", len(records), records, len(tt.want))
+			}
+
+			for i, record := range records {
+				var gotErr string
+				if record.Err != nil {
+					gotErr = record.Err.Error()
+				}
+				if gotErr != tt.wantErrs[i] {
+					t.Errorf("record %d: err = %q, want %q", i, gotErr, tt.wantErrs[i])
+				}
+				if record.Line != tt.want[i].Line {
+					t.Errorf("record %d: line = %d, want %d", i, record.Line, tt.want[i].Line)
+				}
+				if record.Err == nil && !reflect.DeepEqual(record.Fields, tt.want[i].Fields) {
+					t.Errorf("record %d: fields = %q, want %q", i, record.Fields, tt.want[i].Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVReaderNeedsHeader(t *testing.T) {
+	if _, err := NewReader(strings.NewReader(""), FormatCSV); err == nil || err.Error() != "missing header row" {
+		t.Fatalf("err = %v, want missing header row", err)
+	}
+}
+
+func TestJSONLReaderRejectsLongLines(t *testing.T) {
+	r, err := NewReader(strings.NewReader(`{"id": "`+strings.Repeat("x", MaxLineLength)+`"}`), FormatJSONL)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+
+	// A line too long to scan stops the reader rather than failing the row.
+	if _, err := r.Next(); err == nil || err == io.EOF {
+		t.Fatalf("err = %v, want a read error", err)
+	}
+}
+
+func TestNewReaderRejectsUnknownFormat(t *testing.T) {
+	if _, err := NewReader(strings.NewReader(""), "xlsx"); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("err = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestFormatFromName(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		ok     bool
+	}{
+		{"accounts.csv", FormatCSV, true},
+		{"ACCOUNTS.CSV", FormatCSV, true},
+		{"transfers.jsonl", FormatJSONL, true},
+		{"transfers.ndjson", FormatJSONL, true},
+		{"transfers.json", "", false},
+		{"transfers", "", false},
+	}
+
+	for _, tt := range tests {
+		if format, ok := FormatFromName(tt.name); format != tt.format || ok != tt.ok {
+			t.Errorf("FormatFromName(%q) = %q, %t, want %q, %t", tt.name, format, ok, tt.format, tt.ok)
+		}
+	}
+}
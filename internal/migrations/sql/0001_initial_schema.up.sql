@@ -0,0 +1,50 @@
+-- accounts table
+CREATE TABLE accounts (
+    account_id BIGINT PRIMARY KEY,
+    balance DECIMAL(20, 5) NOT NULL
+);
+
+-- transactions table
+CREATE TABLE transactions (
+    id SERIAL PRIMARY KEY,
+    source_account_id BIGINT REFERENCES accounts(account_id),
+    destination_account_id BIGINT REFERENCES accounts(account_id),
+    amount DECIMAL(20, 5) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_accounts_account_id ON accounts(account_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_source_account_id ON transactions(source_account_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_destination_account_id ON transactions(destination_account_id);
+
+-- idempotency keys for POST /transactions
+CREATE TABLE idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- double-entry journal; accounts.balance is a cached projection of postings
+CREATE TABLE journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL,
+    transaction_id INTEGER REFERENCES transactions(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- account_id 0 is the system contra account and has no row in accounts
+CREATE TABLE postings (
+    id BIGSERIAL PRIMARY KEY,
+    journal_entry_id BIGINT NOT NULL REFERENCES journal_entries(id),
+    account_id BIGINT NOT NULL,
+    amount DECIMAL(20, 5) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_transaction_id ON journal_entries(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_postings_account_id_created_at ON postings(account_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_postings_journal_entry_id ON postings(journal_entry_id);
@@ -0,0 +1,9 @@
+-- existing rows predate multi-currency support and are all USD
+ALTER TABLE accounts ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE accounts ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE transactions ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE transactions ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE postings ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE postings ALTER COLUMN currency DROP DEFAULT;
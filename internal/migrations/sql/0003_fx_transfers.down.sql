@@ -0,0 +1,7 @@
+ALTER TABLE transactions
+    DROP COLUMN fx_quote_id,
+    DROP COLUMN fx_rate,
+    DROP COLUMN destination_currency,
+    DROP COLUMN destination_amount;
+
+DROP TABLE IF EXISTS fx_quotes;
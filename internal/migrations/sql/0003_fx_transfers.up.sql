@@ -0,0 +1,24 @@
+-- locked exchange rates; transaction_id is set once a transfer uses the quote
+CREATE TABLE fx_quotes (
+    id VARCHAR(64) PRIMARY KEY,
+    source_currency CHAR(3) NOT NULL,
+    destination_currency CHAR(3) NOT NULL,
+    rate DECIMAL(20, 10) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    transaction_id INTEGER REFERENCES transactions(id)
+);
+
+-- the credited leg of a transfer; it differs from the debited leg only for
+-- cross-currency transfers
+ALTER TABLE transactions
+    ADD COLUMN destination_amount DECIMAL(20, 5),
+    ADD COLUMN destination_currency CHAR(3),
+    ADD COLUMN fx_rate DECIMAL(20, 10),
+    ADD COLUMN fx_quote_id VARCHAR(64) REFERENCES fx_quotes(id);
+
+UPDATE transactions SET destination_amount = amount, destination_currency = currency;
+
+ALTER TABLE transactions
+    ALTER COLUMN destination_amount SET NOT NULL,
+    ALTER COLUMN destination_currency SET NOT NULL;
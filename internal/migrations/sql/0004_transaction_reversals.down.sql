@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_transactions_reversal_of;
+
+ALTER TABLE transactions
+    DROP COLUMN reversal_status,
+    DROP COLUMN reversed_amount,
+    DROP COLUMN reason_code,
+    DROP COLUMN reversal_of,
+    DROP COLUMN kind;
@@ -0,0 +1,8 @@
+ALTER TABLE transactions
+    ADD COLUMN kind VARCHAR(16) NOT NULL DEFAULT 'transfer',
+    ADD COLUMN reversal_of INTEGER REFERENCES transactions(id),
+    ADD COLUMN reason_code VARCHAR(32),
+    ADD COLUMN reversed_amount DECIMAL(20, 5) NOT NULL DEFAULT 0,
+    ADD COLUMN reversal_status VARCHAR(16) NOT NULL DEFAULT 'none';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_reversal_of ON transactions(reversal_of);
@@ -0,0 +1,17 @@
+-- funds reserved on an account; only active, unexpired holds reduce the
+-- available balance
+CREATE TABLE holds (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(20, 5) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    captured_amount DECIMAL(20, 5) NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    transaction_id INTEGER REFERENCES transactions(id),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_holds_active_account_id ON holds(account_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_holds_active_expires_at ON holds(expires_at) WHERE status = 'active';
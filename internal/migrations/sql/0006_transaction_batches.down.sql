@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_transactions_batch_id;
+
+ALTER TABLE transactions
+    DROP COLUMN batch_id;
+
+DROP TABLE IF EXISTS transaction_batches;
@@ -0,0 +1,11 @@
+-- transfers posted together, all or nothing
+CREATE TABLE transaction_batches (
+    id BIGSERIAL PRIMARY KEY,
+    leg_count INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE transactions
+    ADD COLUMN batch_id BIGINT REFERENCES transaction_batches(id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_batch_id ON transactions(batch_id);
@@ -0,0 +1,33 @@
+-- standing transfer instructions picked up by the in-process scheduler
+CREATE TABLE scheduled_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    source_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    destination_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(20, 5) NOT NULL,
+    fx_mode VARCHAR(16) NOT NULL DEFAULT 'none',
+    execute_at TIMESTAMPTZ NOT NULL,
+    frequency VARCHAR(16),
+    end_at TIMESTAMPTZ,
+    max_occurrences INTEGER,
+    occurrences INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    next_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers(next_run_at) WHERE status = 'active';
+
+-- one row per execution attempt
+CREATE TABLE scheduled_transfer_runs (
+    id BIGSERIAL PRIMARY KEY,
+    scheduled_transfer_id BIGINT NOT NULL REFERENCES scheduled_transfers(id),
+    occurrence INTEGER NOT NULL,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    transaction_id INTEGER REFERENCES transactions(id),
+    error TEXT,
+    executed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_runs_scheduled_transfer_id ON scheduled_transfer_runs(scheduled_transfer_id);
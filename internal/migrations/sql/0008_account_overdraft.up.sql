@@ -0,0 +1,3 @@
+-- how far below zero transfers may take the balance
+ALTER TABLE accounts
+    ADD COLUMN overdraft_limit DECIMAL(20, 5) NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0);
@@ -0,0 +1,15 @@
+ALTER TABLE accounts
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active';
+
+-- audit trail of account status transitions
+CREATE TABLE account_status_changes (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    reason TEXT NOT NULL,
+    sweep_transaction_id INTEGER REFERENCES transactions(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_status_changes_account_id ON account_status_changes(account_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_accounts_balance;
+DROP INDEX IF EXISTS idx_accounts_created_at;
+
+ALTER TABLE accounts
+    DROP COLUMN created_at;
@@ -0,0 +1,6 @@
+ALTER TABLE accounts
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- keyset pagination for GET /accounts
+CREATE INDEX IF NOT EXISTS idx_accounts_created_at ON accounts(created_at, account_id);
+CREATE INDEX IF NOT EXISTS idx_accounts_balance ON accounts(balance, account_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_transactions_external_reference;
+
+ALTER TABLE transactions
+    DROP COLUMN metadata,
+    DROP COLUMN external_reference,
+    DROP COLUMN description;
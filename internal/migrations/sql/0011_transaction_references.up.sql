@@ -0,0 +1,10 @@
+ALTER TABLE transactions
+    ADD COLUMN description VARCHAR(500),
+    ADD COLUMN external_reference VARCHAR(128),
+    ADD COLUMN metadata JSONB;
+
+-- external references are unique per source account; the index also serves
+-- GET /transactions?external_reference=
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_external_reference
+    ON transactions(external_reference, source_account_id)
+    WHERE external_reference IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE scheduled_transfers DROP COLUMN initiated_by;
+ALTER TABLE transactions DROP COLUMN initiated_by;
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    -- prefix is the start of the key, kept to tell keys apart; only the
+    -- SHA-256 hash of the whole key is stored
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    -- the accounts the key may debit; NULL means any account
+    account_ids BIGINT[],
+    rotated_from BIGINT REFERENCES api_keys(id),
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE transactions ADD COLUMN initiated_by VARCHAR(64);
+ALTER TABLE scheduled_transfers ADD COLUMN initiated_by VARCHAR(64);
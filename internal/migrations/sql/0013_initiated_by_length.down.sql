@@ -0,0 +1,2 @@
+ALTER TABLE scheduled_transfers ALTER COLUMN initiated_by TYPE VARCHAR(64);
+ALTER TABLE transactions ALTER COLUMN initiated_by TYPE VARCHAR(64);
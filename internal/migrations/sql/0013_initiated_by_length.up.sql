@@ -0,0 +1,3 @@
+-- room for jwt:<sub>, where an OIDC subject may be up to 255 characters
+ALTER TABLE transactions ALTER COLUMN initiated_by TYPE VARCHAR(300);
+ALTER TABLE scheduled_transfers ALTER COLUMN initiated_by TYPE VARCHAR(300);
@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS transaction_approval_events;
+
+DROP INDEX IF EXISTS idx_transactions_external_reference;
+DROP INDEX IF EXISTS idx_transactions_pending_approval;
+
+-- transfers that never posted have no journal entry and go with the column
+DELETE FROM idempotency_keys
+    WHERE transaction_id IN (SELECT id FROM transactions WHERE status <> 'posted');
+UPDATE scheduled_transfer_runs SET transaction_id = NULL
+    WHERE transaction_id IN (SELECT id FROM transactions WHERE status <> 'posted');
+DELETE FROM transactions WHERE status <> 'posted';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_external_reference
+    ON transactions(external_reference, source_account_id)
+    WHERE external_reference IS NOT NULL;
+
+ALTER TABLE transactions
+    DROP COLUMN posted_at,
+    DROP COLUMN approval_expires_at,
+    DROP COLUMN status;
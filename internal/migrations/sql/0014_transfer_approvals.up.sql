@@ -0,0 +1,31 @@
+-- transfers above the approval threshold wait as pending_approval until a
+-- second caller approves them; only then are they posted to the journal
+ALTER TABLE transactions
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'posted',
+    ADD COLUMN approval_expires_at TIMESTAMPTZ,
+    ADD COLUMN posted_at TIMESTAMPTZ;
+
+UPDATE transactions SET posted_at = created_at;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_pending_approval
+    ON transactions(approval_expires_at)
+    WHERE status = 'pending_approval';
+
+-- a rejected or expired transfer gives its external reference back
+DROP INDEX IF EXISTS idx_transactions_external_reference;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_external_reference
+    ON transactions(external_reference, source_account_id)
+    WHERE external_reference IS NOT NULL AND status IN ('posted', 'pending_approval');
+
+-- audit trail of each transfer's approval chain
+CREATE TABLE IF NOT EXISTS transaction_approval_events (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(300),
+    reason VARCHAR(500),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_approval_events_transaction_id
+    ON transaction_approval_events(transaction_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_transactions_outbound;
+
+DROP TABLE IF EXISTS account_limits;
+
+ALTER TABLE accounts
+    DROP COLUMN tier;
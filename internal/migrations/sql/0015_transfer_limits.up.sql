@@ -0,0 +1,19 @@
+-- each account draws its transfer limits from its tier, with optional
+-- per-account overrides; a NULL limit is taken from the tier
+ALTER TABLE accounts
+    ADD COLUMN tier VARCHAR(32) NOT NULL DEFAULT 'standard';
+
+CREATE TABLE IF NOT EXISTS account_limits (
+    account_id BIGINT PRIMARY KEY REFERENCES accounts(account_id),
+    max_amount DECIMAL(20, 5),
+    daily_outbound DECIMAL(20, 5),
+    monthly_outbound DECIMAL(20, 5),
+    max_count INTEGER,
+    count_window_seconds BIGINT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- outbound totals are summed per source account since the start of a window
+CREATE INDEX IF NOT EXISTS idx_transactions_outbound
+    ON transactions(source_account_id, created_at)
+    WHERE kind = 'transfer';
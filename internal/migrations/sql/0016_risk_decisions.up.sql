@@ -0,0 +1,20 @@
+-- how each transfer fared against the risk rules; denied transfers were
+-- never made, so they have no transaction
+CREATE TABLE IF NOT EXISTS risk_decisions (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id INTEGER REFERENCES transactions(id),
+    source_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    destination_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(20, 5) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    score INTEGER NOT NULL,
+    hits JSONB NOT NULL,
+    rules_version VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_transaction_id
+    ON risk_decisions(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_source_account_id
+    ON risk_decisions(source_account_id, id);
@@ -1,13 +1,113 @@
 package models
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	AccountStatusActive = "active"
+	// AccountStatusFrozenDebit rejects debits but still accepts credits.
+	AccountStatusFrozenDebit = "frozen_debit"
+	// AccountStatusFrozenAll rejects debits and credits.
+	AccountStatusFrozenAll = "frozen_all"
+	// AccountStatusClosed is final.
+	AccountStatusClosed = "closed"
+)
+
+// Freeze modes.
+const (
+	FreezeDebit = "debit"
+	FreezeAll   = "all"
+)
 
 type Account struct {
 	AccountID int64           `json:"account_id"`
+	Status    string          `json:"status"`
 	Balance   decimal.Decimal `json:"balance"`
+	// AvailableBalance is Balance less the amount reserved by active holds.
+	// It is derived, not stored.
+	AvailableBalance decimal.Decimal `json:"available_balance"`
+	Currency         string          `json:"currency"`
+	// OverdraftLimit is how far below zero transfers may take Balance.
+	OverdraftLimit decimal.Decimal `json:"overdraft_limit"`
+	// Headroom is how much can still be debited: AvailableBalance plus
+	// OverdraftLimit. It is derived, not stored.
+	Headroom decimal.Decimal `json:"headroom"`
+	// Tier selects the transfer limits the account starts from.
+	Tier      string    `json:"tier"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccountListRequest holds the query parameters of GET /accounts.
+type AccountListRequest struct {
+	Status   string `form:"status"`
+	Currency string `form:"currency"`
+	// MinBalance and MaxBalance bound the ledger balance, inclusive.
+	MinBalance string `form:"min_balance"`
+	MaxBalance string `form:"max_balance"`
+	// CreatedFrom is inclusive and CreatedTo exclusive, both RFC 3339.
+	CreatedFrom string `form:"created_from"`
+	CreatedTo   string `form:"created_to"`
+	// Sort is account_id, balance or created_at, prefixed with - for
+	// descending order. Ties are broken by account ID.
+	Sort   string `form:"sort"`
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+type AccountPage struct {
+	Accounts []Account `json:"accounts"`
+	// NextCursor fetches the next page when passed back as cursor along with
+	// the same sort. It is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type AccountCreateRequest struct {
 	AccountID      int64  `json:"account_id"`
 	InitialBalance string `json:"initial_balance"`
+	// Currency is an ISO 4217 code and defaults to USD.
+	Currency string `json:"currency,omitempty"`
+	// OverdraftLimit defaults to zero, which allows no overdraft.
+	OverdraftLimit string `json:"overdraft_limit,omitempty"`
+	// Tier defaults to standard.
+	Tier string `json:"tier,omitempty"`
+}
+
+type OverdraftLimitRequest struct {
+	OverdraftLimit string `json:"overdraft_limit"`
+}
+
+type AccountTierRequest struct {
+	Tier string `json:"tier"`
+}
+
+type FreezeAccountRequest struct {
+	// Mode is FreezeDebit or FreezeAll (the default).
+	Mode   string `json:"mode,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type UnfreezeAccountRequest struct {
+	Reason string `json:"reason"`
+}
+
+type CloseAccountRequest struct {
+	Reason string `json:"reason"`
+	// SweepToAccountID receives any remaining balance. Without it the
+	// balance must already be zero.
+	SweepToAccountID *int64 `json:"sweep_to_account_id,omitempty"`
+}
+
+// AccountStatusChange is the audit record of one status transition.
+type AccountStatusChange struct {
+	ID         int64  `json:"id"`
+	AccountID  int64  `json:"account_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	Reason     string `json:"reason"`
+	// SweepTransactionID is the transfer that emptied the account on close.
+	SweepTransactionID *int64    `json:"sweep_transaction_id,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
 }
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+type APIKeyRequest struct {
+	Name string `json:"name"`
+	// Scopes are any of accounts:read, accounts:write, transfers:write and
+	// admin, which implies the others.
+	Scopes []string `json:"scopes"`
+	// AccountIDs restricts the accounts the key may debit. Empty means any
+	// account.
+	AccountIDs []int64 `json:"account_ids,omitempty"`
+}
+
+type APIKeyRotateRequest struct {
+	// GracePeriodSeconds keeps the old key working for a while after the
+	// rotation, so clients can switch over. Zero revokes it at once.
+	GracePeriodSeconds int64 `json:"grace_period_seconds,omitempty"`
+}
+
+// APIKey describes a key. The key itself is never stored, only its hash.
+type APIKey struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	KeyHash    string   `json:"-"`
+	Scopes     []string `json:"scopes"`
+	AccountIDs []int64  `json:"account_ids,omitempty"`
+	// RotatedFrom is the key this one replaced.
+	RotatedFrom *int64 `json:"rotated_from,omitempty"`
+	// ExpiresAt is set on a key that was rotated with a grace period.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Active reports whether the key can still authenticate at now.
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
+
+// IssuedAPIKey is a newly minted key. Secret is only ever returned here.
+type IssuedAPIKey struct {
+	APIKey
+	Secret string `json:"secret"`
+}
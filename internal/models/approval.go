@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Approval event actions.
+const (
+	ApprovalActionRequested = "requested"
+	ApprovalActionApproved  = "approved"
+	ApprovalActionRejected  = "rejected"
+	ApprovalActionExpired   = "expired"
+)
+
+type ApprovalDecisionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// ApprovalEvent is the audit record of one step in a transfer's approval
+// chain.
+type ApprovalEvent struct {
+	ID            int64  `json:"id"`
+	TransactionID int64  `json:"transaction_id"`
+	Action        string `json:"action"`
+	// Actor names the caller that took the step. It is empty for expiry,
+	// which the service does on its own.
+	Actor     string    `json:"actor,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type BatchTransactionRequest struct {
+	// Legs are applied in order, so a later leg may spend funds credited by
+	// an earlier one.
+	Legs []TransactionRequest `json:"legs"`
+}
+
+// TransactionBatch groups transfers that were posted atomically: either every
+// leg posted or none did.
+type TransactionBatch struct {
+	ID        int64            `json:"id"`
+	LegCount  int              `json:"leg_count"`
+	CreatedAt time.Time        `json:"created_at"`
+	Legs      []BatchLegResult `json:"legs"`
+}
+
+type BatchLegResult struct {
+	Index       int          `json:"index"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type FXQuoteRequest struct {
+	SourceCurrency      string `json:"source_currency"`
+	DestinationCurrency string `json:"destination_currency"`
+}
+
+// FXQuote locks an exchange rate until ExpiresAt. A quote can be used by a
+// single transfer.
+type FXQuote struct {
+	ID                  string          `json:"id"`
+	SourceCurrency      string          `json:"source_currency"`
+	DestinationCurrency string          `json:"destination_currency"`
+	Rate                decimal.Decimal `json:"rate"`
+	CreatedAt           time.Time       `json:"created_at"`
+	ExpiresAt           time.Time       `json:"expires_at"`
+	TransactionID       *int64          `json:"transaction_id,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusVoided   = "voided"
+	HoldStatusExpired  = "expired"
+)
+
+type HoldRequest struct {
+	AccountID int64  `json:"account_id"`
+	Amount    string `json:"amount"`
+	// ExpiresInSeconds defaults to the server's hold TTL.
+	ExpiresInSeconds int64 `json:"expires_in_seconds,omitempty"`
+}
+
+type HoldCaptureRequest struct {
+	DestinationAccountID int64 `json:"destination_account_id"`
+	// Amount defaults to the full held amount. Any remainder is released.
+	Amount string `json:"amount,omitempty"`
+}
+
+// Hold reserves Amount on an account until it is captured, voided or reaches
+// ExpiresAt. While active it reduces the account's available balance but not
+// its ledger balance.
+type Hold struct {
+	ID             int64           `json:"id"`
+	AccountID      int64           `json:"account_id"`
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	CapturedAmount decimal.Decimal `json:"captured_amount"`
+	Status         string          `json:"status"`
+	TransactionID  *int64          `json:"transaction_id,omitempty"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
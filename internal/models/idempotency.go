@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type IdempotencyKey struct {
+	Key           string    `json:"key"`
+	RequestHash   string    `json:"request_hash"`
+	TransactionID int64     `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
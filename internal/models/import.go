@@ -0,0 +1,26 @@
+package models
+
+// Kinds of rows a bulk import loads.
+const (
+	ImportKindAccounts  = "accounts"
+	ImportKindTransfers = "transfers"
+)
+
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportResult struct {
+	Kind   string `json:"kind"`
+	DryRun bool   `json:"dry_run"`
+	// Rows counts the rows read, Imported those loaded (or, in a dry run,
+	// that would have been) and Failed those rejected.
+	Rows     int              `json:"rows"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+	// ErrorsTruncated is set when Errors was cut short; Failed still counts
+	// every rejected row.
+	ErrorsTruncated bool `json:"errors_truncated,omitempty"`
+}
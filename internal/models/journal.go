@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SystemAccountID is the contra account for money that enters or leaves the
+// ledger, such as opening balances. It never exists in the accounts table.
+const SystemAccountID int64 = 0
+
+const (
+	JournalEntryOpeningBalance = "opening_balance"
+	JournalEntryTransfer       = "transfer"
+	JournalEntryReversal       = "reversal"
+)
+
+type JournalEntry struct {
+	ID            int64     `json:"id"`
+	Kind          string    `json:"kind"`
+	TransactionID *int64    `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	Postings      []Posting `json:"postings"`
+}
+
+// Posting is one leg of a journal entry. A positive amount credits the account
+// (increases its balance) and a negative amount debits it. The postings of an
+// entry always sum to zero.
+type Posting struct {
+	ID             int64           `json:"id"`
+	JournalEntryID int64           `json:"journal_entry_id"`
+	AccountID      int64           `json:"account_id"`
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type BalanceReconciliation struct {
+	AccountID     int64           `json:"account_id"`
+	CachedBalance decimal.Decimal `json:"cached_balance"`
+	LedgerBalance decimal.Decimal `json:"ledger_balance"`
+	Drift         decimal.Decimal `json:"drift"`
+	InSync        bool            `json:"in_sync"`
+}
+
+// AccountBalance is an account's balance at a point in time, as recorded by
+// the journal.
+type AccountBalance struct {
+	AccountID int64           `json:"account_id"`
+	Currency  string          `json:"currency"`
+	AsOf      time.Time       `json:"as_of"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+// BalanceHistoryRequest holds the query parameters of
+// GET /accounts/{account_id}/balance-history.
+type BalanceHistoryRequest struct {
+	// From and To are RFC 3339 and default to the last 30 days.
+	From string `form:"from"`
+	To   string `form:"to"`
+	// Interval is hour, day or week. Without one the history lists every
+	// posting.
+	Interval string `form:"interval"`
+}
+
+// BalanceHistory covers the postings made after From and up to To.
+type BalanceHistory struct {
+	AccountID      int64                 `json:"account_id"`
+	Currency       string                `json:"currency"`
+	From           time.Time             `json:"from"`
+	To             time.Time             `json:"to"`
+	Interval       string                `json:"interval,omitempty"`
+	OpeningBalance decimal.Decimal       `json:"opening_balance"`
+	ClosingBalance decimal.Decimal       `json:"closing_balance"`
+	Entries        []BalanceHistoryEntry `json:"entries"`
+}
+
+// BalanceHistoryEntry is one posting and the balance after it or, with an
+// interval, one period ending at At and the balance at its end.
+type BalanceHistoryEntry struct {
+	At             time.Time       `json:"at"`
+	JournalEntryID *int64          `json:"journal_entry_id,omitempty"`
+	Kind           string          `json:"kind,omitempty"`
+	TransactionID  *int64          `json:"transaction_id,omitempty"`
+	Change         decimal.Decimal `json:"change"`
+	Balance        decimal.Decimal `json:"balance"`
+}
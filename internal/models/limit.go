@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultAccountTier is the tier of accounts created without one.
+const DefaultAccountTier = "standard"
+
+// TransferLimits caps an account's outbound transfers, in the account's
+// currency. A nil limit is not enforced.
+type TransferLimits struct {
+	// MaxAmount caps a single transfer.
+	MaxAmount *decimal.Decimal `json:"max_amount,omitempty"`
+	// DailyOutbound and MonthlyOutbound cap the total sent per UTC calendar
+	// day and month.
+	DailyOutbound   *decimal.Decimal `json:"daily_outbound,omitempty"`
+	MonthlyOutbound *decimal.Decimal `json:"monthly_outbound,omitempty"`
+	// MaxCount caps the transfers sent in any rolling window of
+	// CountWindowSeconds.
+	MaxCount           *int64 `json:"max_count,omitempty"`
+	CountWindowSeconds *int64 `json:"count_window_seconds,omitempty"`
+}
+
+// AccountLimitsRequest sets an account's own limits, replacing any it had.
+// An omitted limit is taken from the account's tier.
+type AccountLimitsRequest struct {
+	MaxAmount          string `json:"max_amount,omitempty"`
+	DailyOutbound      string `json:"daily_outbound,omitempty"`
+	MonthlyOutbound    string `json:"monthly_outbound,omitempty"`
+	MaxCount           *int64 `json:"max_count,omitempty"`
+	CountWindowSeconds *int64 `json:"count_window_seconds,omitempty"`
+}
+
+// AccountLimits shows the limits in force on an account and how much of each
+// is left. Limits that are not enforced are omitted.
+type AccountLimits struct {
+	AccountID int64  `json:"account_id"`
+	Tier      string `json:"tier"`
+	Currency  string `json:"currency"`
+	// Overrides are the account's own limits, which take precedence over
+	// its tier's.
+	Overrides TransferLimits      `json:"overrides"`
+	MaxAmount *decimal.Decimal    `json:"max_amount,omitempty"`
+	Daily     *OutboundLimitUsage `json:"daily,omitempty"`
+	Monthly   *OutboundLimitUsage `json:"monthly,omitempty"`
+	Count     *CountLimitUsage    `json:"count,omitempty"`
+}
+
+type OutboundLimitUsage struct {
+	Limit decimal.Decimal `json:"limit"`
+	// Used counts posted transfers and those awaiting approval.
+	Used      decimal.Decimal `json:"used"`
+	Remaining decimal.Decimal `json:"remaining"`
+	ResetsAt  time.Time       `json:"resets_at"`
+}
+
+type CountLimitUsage struct {
+	Limit         int64 `json:"limit"`
+	Used          int64 `json:"used"`
+	Remaining     int64 `json:"remaining"`
+	WindowSeconds int64 `json:"window_seconds"`
+	// ResetsAt is when the earliest transfer counted leaves the window.
+	ResetsAt *time.Time `json:"resets_at,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// Problem is an RFC 7807 problem details body, sent as
+// application/problem+json with every error response.
+type Problem struct {
+	// Type identifies the kind of problem; GET it for a description.
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request that failed.
+	Instance string `json:"instance,omitempty"`
+	// Code is the last segment of Type, for clients that switch on it.
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+	// Errors lists each offending field of a request that failed validation.
+	Errors []FieldProblem `json:"errors,omitempty"`
+}
+
+type FieldProblem struct {
+	// Field names the body field, query or path parameter or header at fault.
+	// Batch legs are addressed as legs[i].
+	Field  string `json:"field,omitempty"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// ProblemType describes one code a Problem can carry.
+type ProblemType struct {
+	Type   string `json:"type"`
+	Code   string `json:"code"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+}
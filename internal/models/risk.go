@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Risk screening outcomes, in increasing severity.
+const (
+	RiskActionAllow = "allow"
+	// RiskActionReview holds the transfer for approval by a second caller.
+	RiskActionReview = "review"
+	RiskActionDeny   = "deny"
+)
+
+// RiskDecision records how a transfer was screened.
+type RiskDecision struct {
+	ID int64 `json:"id"`
+	// TransactionID is empty for transfers that were never made, such as
+	// denied ones.
+	TransactionID        *int64          `json:"transaction_id,omitempty"`
+	SourceAccountID      int64           `json:"source_account_id"`
+	DestinationAccountID int64           `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	Action               string          `json:"action"`
+	Score                int             `json:"score"`
+	// Hits lists the rules that fired.
+	Hits []RiskRuleHit `json:"hits"`
+	// RulesVersion identifies the rules file the transfer was screened
+	// against.
+	RulesVersion string    `json:"rules_version"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RiskRuleHit struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+// RiskDecisionListRequest holds the query parameters of GET
+// /admin/risk/decisions. Zero values do not filter.
+type RiskDecisionListRequest struct {
+	SourceAccountID int64  `form:"source_account_id"`
+	TransactionID   int64  `form:"transaction_id"`
+	Action          string `form:"action"`
+	Limit           int    `form:"limit"`
+	Offset          int    `form:"offset"`
+}
+
+// RiskRules describes the risk rules in use.
+type RiskRules struct {
+	Version     string            `json:"version"`
+	LoadedAt    time.Time         `json:"loaded_at"`
+	ReviewScore int               `json:"review_score,omitempty"`
+	DenyScore   int               `json:"deny_score,omitempty"`
+	Rules       []RiskRuleSummary `json:"rules"`
+}
+
+type RiskRuleSummary struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Action string `json:"action"`
+	Score  int    `json:"score"`
+}
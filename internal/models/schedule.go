@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCancelled = "cancelled"
+	ScheduleStatusCompleted = "completed"
+)
+
+const (
+	ScheduleRunSucceeded = "succeeded"
+	ScheduleRunFailed    = "failed"
+)
+
+type ScheduledTransferRequest struct {
+	SourceAccountID      int64  `json:"source_account_id"`
+	DestinationAccountID int64  `json:"destination_account_id"`
+	Amount               string `json:"amount"`
+	FXMode               string `json:"fx_mode,omitempty"`
+	// ExecuteAt is the first run and defaults to now.
+	ExecuteAt *time.Time `json:"execute_at,omitempty"`
+	// Recurrence repeats the transfer; without it the transfer runs once.
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+}
+
+type Recurrence struct {
+	// Frequency is FrequencyDaily, FrequencyWeekly or FrequencyMonthly.
+	// Monthly runs on the day of month of the first run, or the last day of
+	// shorter months.
+	Frequency string `json:"frequency"`
+	// EndAt and MaxOccurrences optionally bound the series; it ends at
+	// whichever comes first.
+	EndAt          *time.Time `json:"end_at,omitempty"`
+	MaxOccurrences int        `json:"max_occurrences,omitempty"`
+}
+
+// ScheduledTransfer is a standing instruction to run a transfer once or on a
+// recurring basis. Occurrences counts the dates of the series that have been
+// dealt with, whether they ran, failed or were skipped while paused.
+type ScheduledTransfer struct {
+	ID                   int64           `json:"id"`
+	SourceAccountID      int64           `json:"source_account_id"`
+	DestinationAccountID int64           `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	FXMode               string          `json:"fx_mode"`
+	ExecuteAt            time.Time       `json:"execute_at"`
+	Frequency            string          `json:"frequency,omitempty"`
+	EndAt                *time.Time      `json:"end_at,omitempty"`
+	MaxOccurrences       int             `json:"max_occurrences,omitempty"`
+	Occurrences          int             `json:"occurrences"`
+	Status               string          `json:"status"`
+	// NextRunAt is when the executor next picks the transfer up. It is unset
+	// once the schedule is cancelled or completed.
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	// InitiatedBy is the API caller that created the schedule. Its runs are
+	// recorded as initiated by it too.
+	InitiatedBy string    `json:"initiated_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ScheduledTransferRun records one execution attempt of a scheduled transfer.
+type ScheduledTransferRun struct {
+	ID                  int64     `json:"id"`
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	Occurrence          int       `json:"occurrence"`
+	ScheduledFor        time.Time `json:"scheduled_for"`
+	Status              string    `json:"status"`
+	TransactionID       *int64    `json:"transaction_id,omitempty"`
+	Error               string    `json:"error,omitempty"`
+	ExecutedAt          time.Time `json:"executed_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Statement line types.
+const (
+	StatementLineOpeningBalance = "opening_balance"
+	StatementLineDebit          = "debit"
+	StatementLineCredit         = "credit"
+	StatementLineClosingBalance = "closing_balance"
+)
+
+// StatementRequest holds the query parameters of
+// GET /accounts/{account_id}/statement.
+type StatementRequest struct {
+	// From and To are RFC 3339 and default to the last 30 days.
+	From string `form:"from"`
+	To   string `form:"to"`
+	// Format is csv (the default), jsonl or camt053.
+	Format string `form:"format"`
+}
+
+// Statement describes a statement covering the transactions booked after From
+// and up to To. Its lines are streamed separately.
+type Statement struct {
+	AccountID      int64           `json:"account_id"`
+	Currency       string          `json:"currency"`
+	Format         string          `json:"format"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+	ClosingBalance decimal.Decimal `json:"closing_balance"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// StatementLine is one debit or credit of a statement, or its opening or
+// closing balance.
+type StatementLine struct {
+	Type                  string    `json:"type"`
+	BookedAt              time.Time `json:"booked_at"`
+	TransactionID         *int64    `json:"transaction_id,omitempty"`
+	Kind                  string    `json:"kind,omitempty"`
+	CounterpartyAccountID *int64    `json:"counterparty_account_id,omitempty"`
+	Reference             string    `json:"reference,omitempty"`
+	ExternalReference     string    `json:"external_reference,omitempty"`
+	// Amount is unsigned; Type says which way it moved. It is zero on balance
+	// lines.
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+	// Balance is the running balance after the line.
+	Balance decimal.Decimal `json:"balance"`
+}
@@ -1,14 +1,105 @@
 package models
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// FXModeNone rejects transfers between accounts of different currencies.
+	FXModeNone = "none"
+	// FXModeConvert allows a transfer to convert between currencies.
+	FXModeConvert = "convert"
+)
+
+const (
+	TransactionKindTransfer = "transfer"
+	TransactionKindReversal = "reversal"
+)
+
+const (
+	TransactionStatusPosted = "posted"
+	// TransactionStatusPendingApproval is a transfer above the approval
+	// threshold that has not moved any money yet.
+	TransactionStatusPendingApproval = "pending_approval"
+	TransactionStatusRejected        = "rejected"
+	TransactionStatusExpired         = "expired"
+)
+
+const (
+	ReversalStatusNone    = "none"
+	ReversalStatusPartial = "partial"
+	ReversalStatusFull    = "full"
+)
+
+// Reversal reason codes.
+const (
+	ReasonCustomerRequest = "customer_request"
+	ReasonDuplicate       = "duplicate"
+	ReasonFraud           = "fraud"
+	ReasonProcessingError = "processing_error"
+	ReasonOther           = "other"
+)
+
 type TransactionRequest struct {
 	SourceAccountID      int64  `json:"source_account_id"`
 	DestinationAccountID int64  `json:"destination_account_id"`
 	Amount               string `json:"amount"`
+	// FXMode is FXModeNone (the default) or FXModeConvert.
+	FXMode string `json:"fx_mode,omitempty"`
+	// FXQuoteID converts at a previously quoted rate. Without it a
+	// conversion uses the current rate.
+	FXQuoteID string `json:"fx_quote_id,omitempty"`
+	// Description is a free-text memo shown on statements.
+	Description string `json:"description,omitempty"`
+	// ExternalReference ties the transfer to a record elsewhere, such as an
+	// invoice or order number. It must be unique per source account.
+	ExternalReference string         `json:"external_reference,omitempty"`
+	Metadata          map[string]any `json:"metadata,omitempty"`
+}
+
+type ReversalRequest struct {
+	// Amount is returned to the original source account, in its currency.
+	// It defaults to everything not yet reversed.
+	Amount     string `json:"amount,omitempty"`
+	ReasonCode string `json:"reason_code"`
 }
 
 type Transaction struct {
-	ID                   int64  `json:"id"`
-	SourceAccountID      int64  `json:"source_account_id"`
-	DestinationAccountID int64  `json:"destination_account_id"`
-	Amount               string `json:"amount"`
+	ID                   int64           `json:"id"`
+	Kind                 string          `json:"kind"`
+	Status               string          `json:"status"`
+	SourceAccountID      int64           `json:"source_account_id"`
+	DestinationAccountID int64           `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	// DestinationAmount and DestinationCurrency describe the credited leg.
+	// They equal Amount and Currency unless the transfer converted currency.
+	DestinationAmount   decimal.Decimal  `json:"destination_amount"`
+	DestinationCurrency string           `json:"destination_currency"`
+	FXRate              *decimal.Decimal `json:"fx_rate,omitempty"`
+	FXQuoteID           *string          `json:"fx_quote_id,omitempty"`
+	// ReversalOf and ReasonCode are set on reversals and link them to the
+	// transaction they undo.
+	ReversalOf *int64 `json:"reversal_of,omitempty"`
+	ReasonCode string `json:"reason_code,omitempty"`
+	// ReversedAmount is the part of Amount that reversals have returned to
+	// the source so far.
+	ReversedAmount decimal.Decimal `json:"reversed_amount"`
+	ReversalStatus string          `json:"reversal_status"`
+	// BatchID is set on the legs of a batch transfer.
+	BatchID           *int64         `json:"batch_id,omitempty"`
+	Description       string         `json:"description,omitempty"`
+	ExternalReference string         `json:"external_reference,omitempty"`
+	Metadata          map[string]any `json:"metadata,omitempty"`
+	// InitiatedBy names the API caller that made the transaction, such as
+	// "api_key:42". It is empty when authentication is disabled.
+	InitiatedBy string `json:"initiated_by,omitempty"`
+	// ApprovalExpiresAt is when a transfer awaiting approval expires.
+	ApprovalExpiresAt *time.Time `json:"approval_expires_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	// PostedAt is when the money moved. It is unset until a transfer
+	// awaiting approval is approved.
+	PostedAt *time.Time `json:"posted_at,omitempty"`
 }
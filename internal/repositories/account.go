@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/KaranPal130/transfers-system/internal/models"
 	"github.com/shopspring/decimal"
@@ -11,67 +14,319 @@ import (
 
 var (
 	ErrAccountNotFound = errors.New("Account not Found")
+	ErrAccountExists   = errors.New("Account already exists")
 )
 
+const accountColumns = `account_id, status, balance, currency, overdraft_limit, tier, created_at`
+
+// Orders AccountStore.List can sort by.
+const (
+	AccountSortID        = "account_id"
+	AccountSortBalance   = "balance"
+	AccountSortCreatedAt = "created_at"
+)
+
+// AccountQuery selects a page of accounts ordered by SortBy and then by
+// account ID. Nil and empty fields do not filter.
+type AccountQuery struct {
+	Status      string
+	Currency    string
+	MinBalance  *decimal.Decimal
+	MaxBalance  *decimal.Decimal
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	SortBy      string
+	Descending  bool
+	// After, when set, starts the page after the account with these sort
+	// keys, so rows inserted meanwhile never shift the page.
+	After *models.Account
+	Limit int
+}
+
 type AccountRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewAccountRepository(db *sql.DB) *AccountRepository {
+func NewAccountRepository(db DBTX) *AccountRepository {
 	return &AccountRepository{
 		db: db,
 	}
 }
 
 func (r *AccountRepository) Create(ctx context.Context, account models.Account) error {
-	query := `INSERT INTO accounts (account_id, balance) VALUES ($1, $2)`
-	_, err := r.db.ExecContext(ctx, query, account.AccountID, account.Balance)
+	query := `INSERT INTO accounts (account_id, status, balance, currency, overdraft_limit, tier) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.ExecContext(ctx, query, account.AccountID, account.Status, account.Balance.String(), account.Currency, account.OverdraftLimit.String(), account.Tier)
+	if hasSQLState(err, sqlStateUniqueViolation) {
+		return ErrAccountExists
+	}
 	return err
 }
 
 func (r *AccountRepository) GetByID(ctx context.Context, accountID int64) (models.Account, error) {
-	query := `SELECT account_id, balance FROM accounts WHERE account_id = $1`
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE account_id = $1`
+	return scanAccount(r.db.QueryRowContext(ctx, query, accountID))
+}
 
-	var account models.Account
-	var balanceStr string
+func (r *AccountRepository) GetByIDForUpdate(ctx context.Context, accountID int64) (models.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE account_id = $1 FOR UPDATE`
+	return scanAccount(r.db.QueryRowContext(ctx, query, accountID))
+}
 
-	err := r.db.QueryRowContext(ctx, query, accountID).Scan(&account.AccountID, &balanceStr)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return models.Account{}, ErrAccountNotFound
+func (r *AccountRepository) List(ctx context.Context, q AccountQuery) ([]models.Account, error) {
+	var conditions []string
+	var args []any
+
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Status != "" {
+		conditions = append(conditions, "status = "+arg(q.Status))
+	}
+	if q.Currency != "" {
+		conditions = append(conditions, "currency = "+arg(q.Currency))
+	}
+	if q.MinBalance != nil {
+		conditions = append(conditions, "balance >= "+arg(q.MinBalance.String()))
+	}
+	if q.MaxBalance != nil {
+		conditions = append(conditions, "balance <= "+arg(q.MaxBalance.String()))
+	}
+	if q.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+arg(*q.CreatedFrom))
+	}
+	if q.CreatedTo != nil {
+		conditions = append(conditions, "created_at < "+arg(*q.CreatedTo))
+	}
+
+	op, direction := ">", "ASC"
+	if q.Descending {
+		op, direction = "<", "DESC"
+	}
+
+	if q.After != nil {
+		switch q.SortBy {
+		case AccountSortBalance:
+			conditions = append(conditions, fmt.Sprintf("(balance, account_id) %s (%s::numeric, %s)", op, arg(q.After.Balance.String()), arg(q.After.AccountID)))
+		case AccountSortCreatedAt:
+			conditions = append(conditions, fmt.Sprintf("(created_at, account_id) %s (%s::timestamptz, %s)", op, arg(q.After.CreatedAt), arg(q.After.AccountID)))
+		default:
+			conditions = append(conditions, fmt.Sprintf("account_id %s %s", op, arg(q.After.AccountID)))
 		}
-		return models.Account{}, err
 	}
 
-	account.Balance, err = decimal.NewFromString(balanceStr)
+	orderBy := "account_id " + direction
+	switch q.SortBy {
+	case AccountSortBalance, AccountSortCreatedAt:
+		orderBy = q.SortBy + " " + direction + ", " + orderBy
+	}
+
+	query := `SELECT ` + accountColumns + ` FROM accounts`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY ` + orderBy + ` LIMIT ` + arg(q.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return models.Account{}, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return account, nil
+	accounts := []models.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
 }
 
-func (r *AccountRepository) GetByIDForUpdate(ctx context.Context, tx *sql.Tx, accountID int64) (models.Account, error) {
-    query := `SELECT account_id, balance FROM accounts WHERE account_id = $1 FOR UPDATE`
-    var account models.Account
-    var balanceStr string
-    err := tx.QueryRowContext(ctx, query, accountID).Scan(&account.AccountID, &balanceStr)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            return models.Account{}, ErrAccountNotFound
-        }
-        return models.Account{}, err
-    }
-    account.Balance, err = decimal.NewFromString(balanceStr)
-    if err != nil {
-        return models.Account{}, err
-    }
-    return account, nil
-}
-
-func (r *AccountRepository) UpdateBalance(ctx context.Context, tx *sql.Tx, accountID int64, newBalance decimal.Decimal) error {
+func (r *AccountRepository) UpdateBalance(ctx context.Context, accountID int64, newBalance decimal.Decimal) error {
 	query := `UPDATE accounts SET balance = $1 WHERE account_id = $2`
-	result, err := tx.ExecContext(ctx, query, newBalance.String(), accountID)
+	return r.update(ctx, query, newBalance.String(), accountID)
+}
+
+func (r *AccountRepository) UpdateOverdraftLimit(ctx context.Context, accountID int64, limit decimal.Decimal) error {
+	query := `UPDATE accounts SET overdraft_limit = $1 WHERE account_id = $2`
+	return r.update(ctx, query, limit.String(), accountID)
+}
+
+func (r *AccountRepository) UpdateTier(ctx context.Context, accountID int64, tier string) error {
+	query := `UPDATE accounts SET tier = $1 WHERE account_id = $2`
+	return r.update(ctx, query, tier, accountID)
+}
+
+func (r *AccountRepository) GetLimits(ctx context.Context, accountID int64) (models.TransferLimits, error) {
+	query := `
+		SELECT max_amount, daily_outbound, monthly_outbound, max_count, count_window_seconds
+		FROM account_limits
+		WHERE account_id = $1
+	`
+
+	var maxAmount, dailyOutbound, monthlyOutbound sql.NullString
+	var maxCount, countWindowSeconds sql.NullInt64
+
+	err := r.db.QueryRowContext(ctx, query, accountID).Scan(&maxAmount, &dailyOutbound, &monthlyOutbound, &maxCount, &countWindowSeconds)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TransferLimits{}, nil
+	}
+	if err != nil {
+		return models.TransferLimits{}, err
+	}
+
+	var limits models.TransferLimits
+	if limits.MaxAmount, err = nullDecimal(maxAmount); err != nil {
+		return models.TransferLimits{}, err
+	}
+	if limits.DailyOutbound, err = nullDecimal(dailyOutbound); err != nil {
+		return models.TransferLimits{}, err
+	}
+	if limits.MonthlyOutbound, err = nullDecimal(monthlyOutbound); err != nil {
+		return models.TransferLimits{}, err
+	}
+	if maxCount.Valid {
+		limits.MaxCount = &maxCount.Int64
+	}
+	if countWindowSeconds.Valid {
+		limits.CountWindowSeconds = &countWindowSeconds.Int64
+	}
+
+	return limits, nil
+}
+
+func (r *AccountRepository) SetLimits(ctx context.Context, accountID int64, limits models.TransferLimits) error {
+	query := `
+		INSERT INTO account_limits (account_id, max_amount, daily_outbound, monthly_outbound, max_count, count_window_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (account_id) DO UPDATE
+		SET max_amount = EXCLUDED.max_amount,
+			daily_outbound = EXCLUDED.daily_outbound,
+			monthly_outbound = EXCLUDED.monthly_outbound,
+			max_count = EXCLUDED.max_count,
+			count_window_seconds = EXCLUDED.count_window_seconds,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	var maxCount, countWindowSeconds sql.NullInt64
+	if limits.MaxCount != nil {
+		maxCount = sql.NullInt64{Int64: *limits.MaxCount, Valid: true}
+	}
+	if limits.CountWindowSeconds != nil {
+		countWindowSeconds = sql.NullInt64{Int64: *limits.CountWindowSeconds, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		accountID,
+		nullableDecimal(limits.MaxAmount),
+		nullableDecimal(limits.DailyOutbound),
+		nullableDecimal(limits.MonthlyOutbound),
+		maxCount,
+		countWindowSeconds,
+	)
+	if hasSQLState(err, sqlStateForeignKeyViolation) {
+		return ErrAccountNotFound
+	}
+
+	return err
+}
+
+func nullableDecimal(d *decimal.Decimal) sql.NullString {
+	if d == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: d.String(), Valid: true}
+}
+
+func nullDecimal(s sql.NullString) (*decimal.Decimal, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+
+	d, err := decimal.NewFromString(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *AccountRepository) UpdateStatus(ctx context.Context, accountID int64, status string) error {
+	query := `UPDATE accounts SET status = $1 WHERE account_id = $2`
+	return r.update(ctx, query, status, accountID)
+}
+
+func (r *AccountRepository) CreateStatusChange(ctx context.Context, change models.AccountStatusChange) (models.AccountStatusChange, error) {
+	query := `
+		INSERT INTO account_status_changes (account_id, from_status, to_status, reason, sweep_transaction_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		change.AccountID,
+		change.FromStatus,
+		change.ToStatus,
+		change.Reason,
+		change.SweepTransactionID,
+	).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return models.AccountStatusChange{}, err
+	}
+
+	return change, nil
+}
+
+// ListStatusChanges returns the account's status history, oldest first.
+func (r *AccountRepository) ListStatusChanges(ctx context.Context, accountID int64) ([]models.AccountStatusChange, error) {
+	query := `
+		SELECT id, account_id, from_status, to_status, reason, sweep_transaction_id, created_at
+		FROM account_status_changes
+		WHERE account_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.AccountStatusChange{}
+	for rows.Next() {
+		var change models.AccountStatusChange
+		var sweepTransactionID sql.NullInt64
+
+		err := rows.Scan(
+			&change.ID,
+			&change.AccountID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.Reason,
+			&sweepTransactionID,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if sweepTransactionID.Valid {
+			change.SweepTransactionID = &sweepTransactionID.Int64
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+func (r *AccountRepository) update(ctx context.Context, query string, args ...any) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -86,4 +341,29 @@ func (r *AccountRepository) UpdateBalance(ctx context.Context, tx *sql.Tx, accou
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func scanAccount(row rowScanner) (models.Account, error) {
+	var account models.Account
+	var balanceStr, overdraftLimitStr string
+
+	err := row.Scan(&account.AccountID, &account.Status, &balanceStr, &account.Currency, &overdraftLimitStr, &account.Tier, &account.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Account{}, ErrAccountNotFound
+		}
+		return models.Account{}, err
+	}
+
+	account.Balance, err = decimal.NewFromString(balanceStr)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	account.OverdraftLimit, err = decimal.NewFromString(overdraftLimitStr)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return account, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key not Found")
+	ErrAPIKeyExists   = errors.New("API key already exists")
+)
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, account_ids, rotated_from, expires_at, revoked_at, created_at`
+
+type APIKeyRepository struct {
+	db DBTX
+}
+
+func NewAPIKeyRepository(db DBTX) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, account_ids, rotated_from, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + apiKeyColumns
+
+	var accountIDs any
+	if len(key.AccountIDs) > 0 {
+		accountIDs = pq.Array(key.AccountIDs)
+	}
+
+	key, err := scanAPIKey(r.db.QueryRowContext(
+		ctx,
+		query,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(key.Scopes),
+		accountIDs,
+		key.RotatedFrom,
+		key.ExpiresAt,
+	))
+	if hasSQLState(err, sqlStateUniqueViolation) {
+		return models.APIKey{}, ErrAPIKeyExists
+	}
+
+	return key, err
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id int64) (models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *APIKeyRepository) GetByIDForUpdate(ctx context.Context, id int64) (models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1 FOR UPDATE`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+}
+
+func (r *APIKeyRepository) GetByRotatedFrom(ctx context.Context, id int64) (models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE rotated_from = $1`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *APIKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepository) Update(ctx context.Context, key models.APIKey) error {
+	query := `UPDATE api_keys SET expires_at = $1, revoked_at = $2 WHERE id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, key.ExpiresAt, key.RevokedAt, key.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (models.APIKey, error) {
+	var key models.APIKey
+	var accountIDs pq.Int64Array
+	var rotatedFrom sql.NullInt64
+	var expiresAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&accountIDs,
+		&rotatedFrom,
+		&expiresAt,
+		&revokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.APIKey{}, ErrAPIKeyNotFound
+		}
+		return models.APIKey{}, err
+	}
+
+	key.AccountIDs = accountIDs
+
+	if rotatedFrom.Valid {
+		key.RotatedFrom = &rotatedFrom.Int64
+	}
+
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+)
+
+type ApprovalRepository struct {
+	db DBTX
+}
+
+func NewApprovalRepository(db DBTX) *ApprovalRepository {
+	return &ApprovalRepository{
+		db: db,
+	}
+}
+
+func (r *ApprovalRepository) CreateEvent(ctx context.Context, event models.ApprovalEvent) (models.ApprovalEvent, error) {
+	query := `
+		INSERT INTO transaction_approval_events (transaction_id, action, actor, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		event.TransactionID,
+		event.Action,
+		sql.NullString{String: event.Actor, Valid: event.Actor != ""},
+		sql.NullString{String: event.Reason, Valid: event.Reason != ""},
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return models.ApprovalEvent{}, err
+	}
+
+	return event, nil
+}
+
+func (r *ApprovalRepository) ListEvents(ctx context.Context, transactionID int64) ([]models.ApprovalEvent, error) {
+	query := `
+		SELECT id, transaction_id, action, actor, reason, created_at
+		FROM transaction_approval_events
+		WHERE transaction_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.ApprovalEvent{}
+	for rows.Next() {
+		var event models.ApprovalEvent
+		var actor, reason sql.NullString
+
+		err := rows.Scan(
+			&event.ID,
+			&event.TransactionID,
+			&event.Action,
+			&actor,
+			&reason,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Actor = actor.String
+		event.Reason = reason.String
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
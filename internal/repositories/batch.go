@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+)
+
+var (
+	ErrBatchNotFound = errors.New("Batch not Found")
+)
+
+type BatchRepository struct {
+	db DBTX
+}
+
+func NewBatchRepository(db DBTX) *BatchRepository {
+	return &BatchRepository{
+		db: db,
+	}
+}
+
+func (r *BatchRepository) Create(ctx context.Context, batch models.TransactionBatch) (models.TransactionBatch, error) {
+	query := `
+		INSERT INTO transaction_batches (leg_count)
+		VALUES ($1)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, batch.LegCount).Scan(&batch.ID, &batch.CreatedAt)
+	if err != nil {
+		return models.TransactionBatch{}, err
+	}
+
+	return batch, nil
+}
+
+func (r *BatchRepository) GetByID(ctx context.Context, id int64) (models.TransactionBatch, error) {
+	query := `SELECT id, leg_count, created_at FROM transaction_batches WHERE id = $1`
+
+	var batch models.TransactionBatch
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&batch.ID, &batch.LegCount, &batch.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.TransactionBatch{}, ErrBatchNotFound
+		}
+		return models.TransactionBatch{}, err
+	}
+
+	return batch, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+// BulkRepository loads rows with COPY, which needs a transaction, so db must
+// be a *sql.Tx. Because COPY cannot return generated IDs, they are drawn from
+// the tables' sequences up front.
+type BulkRepository struct {
+	db DBTX
+}
+
+func NewBulkRepository(db DBTX) *BulkRepository {
+	return &BulkRepository{
+		db: db,
+	}
+}
+
+func (r *BulkRepository) CopyAccounts(ctx context.Context, accounts []models.Account) error {
+	columns := []string{"account_id", "status", "balance", "currency", "overdraft_limit", "tier", "created_at"}
+
+	err := r.copy(ctx, "accounts", columns, len(accounts), func(i int) []any {
+		account := accounts[i]
+		return []any{
+			account.AccountID,
+			account.Status,
+			account.Balance.String(),
+			account.Currency,
+			account.OverdraftLimit.String(),
+			account.Tier,
+			account.CreatedAt,
+		}
+	})
+	if hasSQLState(err, sqlStateUniqueViolation) {
+		return ErrAccountExists
+	}
+
+	return err
+}
+
+func (r *BulkRepository) CopyTransactions(ctx context.Context, transactions []models.Transaction) ([]models.Transaction, error) {
+	ids, err := r.nextIDs(ctx, "transactions_id_seq", len(transactions))
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []string{
+		"id", "kind", "source_account_id", "destination_account_id", "amount", "currency",
+		"destination_amount", "destination_currency", "fx_rate", "fx_quote_id",
+		"reversal_of", "reason_code", "batch_id", "description", "external_reference",
+		"metadata", "initiated_by", "status", "created_at", "posted_at",
+	}
+
+	metadata := make([]sql.NullString, len(transactions))
+	for i, transaction := range transactions {
+		metadata[i], err = encodeMetadata(transaction.Metadata)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = r.copy(ctx, "transactions", columns, len(transactions), func(i int) []any {
+		transaction := &transactions[i]
+		transaction.ID = ids[i]
+		transaction.ReversedAmount = decimal.Zero
+		transaction.ReversalStatus = models.ReversalStatusNone
+		transaction.Status = models.TransactionStatusPosted
+		postedAt := transaction.CreatedAt
+		transaction.PostedAt = &postedAt
+
+		var fxRate sql.NullString
+		if transaction.FXRate != nil {
+			fxRate = sql.NullString{String: transaction.FXRate.String(), Valid: true}
+		}
+
+		return []any{
+			transaction.ID,
+			transaction.Kind,
+			transaction.SourceAccountID,
+			transaction.DestinationAccountID,
+			transaction.Amount.String(),
+			transaction.Currency,
+			transaction.DestinationAmount.String(),
+			transaction.DestinationCurrency,
+			fxRate,
+			transaction.FXQuoteID,
+			transaction.ReversalOf,
+			sql.NullString{String: transaction.ReasonCode, Valid: transaction.ReasonCode != ""},
+			transaction.BatchID,
+			sql.NullString{String: transaction.Description, Valid: transaction.Description != ""},
+			sql.NullString{String: transaction.ExternalReference, Valid: transaction.ExternalReference != ""},
+			metadata[i],
+			sql.NullString{String: transaction.InitiatedBy, Valid: transaction.InitiatedBy != ""},
+			transaction.Status,
+			transaction.CreatedAt,
+			transaction.CreatedAt,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (r *BulkRepository) CopyJournalEntries(ctx context.Context, entries []models.JournalEntry) ([]models.JournalEntry, error) {
+	entryIDs, err := r.nextIDs(ctx, "journal_entries_id_seq", len(entries))
+	if err != nil {
+		return nil, err
+	}
+
+	var postings []*models.Posting
+	for i := range entries {
+		entry := &entries[i]
+		entry.ID = entryIDs[i]
+
+		for j := range entry.Postings {
+			posting := &entry.Postings[j]
+			posting.JournalEntryID = entry.ID
+			posting.CreatedAt = entry.CreatedAt
+			postings = append(postings, posting)
+		}
+	}
+
+	postingIDs, err := r.nextIDs(ctx, "postings_id_seq", len(postings))
+	if err != nil {
+		return nil, err
+	}
+
+	entryColumns := []string{"id", "kind", "transaction_id", "created_at"}
+	err = r.copy(ctx, "journal_entries", entryColumns, len(entries), func(i int) []any {
+		entry := entries[i]
+		return []any{entry.ID, entry.Kind, entry.TransactionID, entry.CreatedAt}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	postingColumns := []string{"id", "journal_entry_id", "account_id", "amount", "currency", "created_at"}
+	err = r.copy(ctx, "postings", postingColumns, len(postings), func(i int) []any {
+		posting := postings[i]
+		posting.ID = postingIDs[i]
+		return []any{
+			posting.ID,
+			posting.JournalEntryID,
+			posting.AccountID,
+			posting.Amount.String(),
+			posting.Currency,
+			posting.CreatedAt,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// copy streams n rows, built by row, into table with COPY FROM STDIN.
+func (r *BulkRepository) copy(ctx context.Context, table string, columns []string, n int, row func(i int) []any) error {
+	if n == 0 {
+		return nil
+	}
+
+	stmt, err := r.db.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := 0; i < n; i++ {
+		if _, err := stmt.ExecContext(ctx, row(i)...); err != nil {
+			return err
+		}
+	}
+
+	// An Exec without arguments ends the COPY and reports any row the server
+	// rejected.
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
+
+func (r *BulkRepository) nextIDs(ctx context.Context, sequence string, n int) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT nextval($1::regclass) FROM generate_series(1, $2)`, sequence, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0, n)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
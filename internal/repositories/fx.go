@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrFXQuoteNotFound = errors.New("FX quote not Found")
+)
+
+type FXQuoteRepository struct {
+	db DBTX
+}
+
+func NewFXQuoteRepository(db DBTX) *FXQuoteRepository {
+	return &FXQuoteRepository{
+		db: db,
+	}
+}
+
+func (r *FXQuoteRepository) Create(ctx context.Context, quote models.FXQuote) (models.FXQuote, error) {
+	query := `
+		INSERT INTO fx_quotes (id, source_currency, destination_currency, rate, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		quote.ID,
+		quote.SourceCurrency,
+		quote.DestinationCurrency,
+		quote.Rate.String(),
+		quote.ExpiresAt,
+	).Scan(&quote.CreatedAt)
+	if err != nil {
+		return models.FXQuote{}, err
+	}
+
+	return quote, nil
+}
+
+func (r *FXQuoteRepository) GetByID(ctx context.Context, id string) (models.FXQuote, error) {
+	return r.get(ctx, `
+		SELECT id, source_currency, destination_currency, rate, created_at, expires_at, transaction_id
+		FROM fx_quotes
+		WHERE id = $1
+	`, id)
+}
+
+func (r *FXQuoteRepository) GetByIDForUpdate(ctx context.Context, id string) (models.FXQuote, error) {
+	return r.get(ctx, `
+		SELECT id, source_currency, destination_currency, rate, created_at, expires_at, transaction_id
+		FROM fx_quotes
+		WHERE id = $1
+		FOR UPDATE
+	`, id)
+}
+
+func (r *FXQuoteRepository) get(ctx context.Context, query, id string) (models.FXQuote, error) {
+	var quote models.FXQuote
+	var rateStr string
+	var transactionID sql.NullInt64
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&quote.ID,
+		&quote.SourceCurrency,
+		&quote.DestinationCurrency,
+		&rateStr,
+		&quote.CreatedAt,
+		&quote.ExpiresAt,
+		&transactionID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.FXQuote{}, ErrFXQuoteNotFound
+		}
+		return models.FXQuote{}, err
+	}
+
+	quote.Rate, err = decimal.NewFromString(rateStr)
+	if err != nil {
+		return models.FXQuote{}, err
+	}
+
+	if transactionID.Valid {
+		quote.TransactionID = &transactionID.Int64
+	}
+
+	return quote, nil
+}
+
+// MarkUsed records the transaction that consumed the quote.
+func (r *FXQuoteRepository) MarkUsed(ctx context.Context, id string, transactionID int64) error {
+	query := `UPDATE fx_quotes SET transaction_id = $1 WHERE id = $2`
+	result, err := r.db.ExecContext(ctx, query, transactionID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrFXQuoteNotFound
+	}
+
+	return nil
+}
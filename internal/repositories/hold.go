@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrHoldNotFound = errors.New("Hold not Found")
+)
+
+const holdColumns = `id, account_id, amount, currency, captured_amount, status, transaction_id, expires_at, created_at, updated_at`
+
+type HoldRepository struct {
+	db DBTX
+}
+
+func NewHoldRepository(db DBTX) *HoldRepository {
+	return &HoldRepository{
+		db: db,
+	}
+}
+
+func (r *HoldRepository) Create(ctx context.Context, hold models.Hold) (models.Hold, error) {
+	query := `
+		INSERT INTO holds (account_id, amount, currency, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + holdColumns
+
+	return scanHold(r.db.QueryRowContext(
+		ctx,
+		query,
+		hold.AccountID,
+		hold.Amount.String(),
+		hold.Currency,
+		hold.Status,
+		hold.ExpiresAt,
+	))
+}
+
+func (r *HoldRepository) GetByID(ctx context.Context, id int64) (models.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE id = $1`
+	return scanHold(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *HoldRepository) GetByIDForUpdate(ctx context.Context, id int64) (models.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE id = $1 FOR UPDATE`
+	return scanHold(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *HoldRepository) Update(ctx context.Context, hold models.Hold) error {
+	query := `
+		UPDATE holds
+		SET status = $1, captured_amount = $2, transaction_id = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`
+	result, err := r.db.ExecContext(ctx, query, hold.Status, hold.CapturedAmount.String(), hold.TransactionID, hold.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrHoldNotFound
+	}
+
+	return nil
+}
+
+func (r *HoldRepository) SumActive(ctx context.Context, accountID int64) (decimal.Decimal, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM holds
+		WHERE account_id = $1 AND status = 'active' AND expires_at > CURRENT_TIMESTAMP
+	`
+
+	var sumStr string
+	if err := r.db.QueryRowContext(ctx, query, accountID).Scan(&sumStr); err != nil {
+		return decimal.Zero, err
+	}
+
+	return decimal.NewFromString(sumStr)
+}
+
+// ExpireDue skips holds another unit of work has locked, such as one being
+// captured right now; the next run picks them up if they are still active.
+func (r *HoldRepository) ExpireDue(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE holds
+		SET status = 'expired', updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM holds
+			WHERE status = 'active' AND expires_at <= CURRENT_TIMESTAMP
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func scanHold(row rowScanner) (models.Hold, error) {
+	var hold models.Hold
+	var amountStr, capturedStr string
+	var transactionID sql.NullInt64
+
+	err := row.Scan(
+		&hold.ID,
+		&hold.AccountID,
+		&amountStr,
+		&hold.Currency,
+		&capturedStr,
+		&hold.Status,
+		&transactionID,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Hold{}, ErrHoldNotFound
+		}
+		return models.Hold{}, err
+	}
+
+	hold.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	hold.CapturedAmount, err = decimal.NewFromString(capturedStr)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	if transactionID.Valid {
+		hold.TransactionID = &transactionID.Int64
+	}
+
+	return hold, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+)
+
+var (
+	ErrIdempotencyKeyNotFound = errors.New("Idempotency key not Found")
+	ErrIdempotencyKeyExists   = errors.New("Idempotency key already exists")
+)
+
+type IdempotencyRepository struct {
+	db DBTX
+}
+
+func NewIdempotencyRepository(db DBTX) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: db,
+	}
+}
+
+// Get returns the unexpired record stored under key.
+func (r *IdempotencyRepository) Get(ctx context.Context, key string) (models.IdempotencyKey, error) {
+	query := `
+		SELECT key, request_hash, transaction_id, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1 AND expires_at > CURRENT_TIMESTAMP
+	`
+
+	var record models.IdempotencyKey
+	err := r.db.QueryRowContext(ctx, query, key).Scan(
+		&record.Key,
+		&record.RequestHash,
+		&record.TransactionID,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.IdempotencyKey{}, ErrIdempotencyKeyNotFound
+		}
+		return models.IdempotencyKey{}, err
+	}
+
+	return record, nil
+}
+
+// Create stores the key in the same unit of work as the transfer it protects,
+// so that both commit or roll back together. An expired record under the same
+// key is replaced. If another transaction already holds the key, Create waits
+// for it to finish and returns ErrIdempotencyKeyExists if it committed.
+func (r *IdempotencyRepository) Create(ctx context.Context, record models.IdempotencyKey, ttl time.Duration) error {
+	deleteQuery := `DELETE FROM idempotency_keys WHERE key = $1 AND expires_at <= CURRENT_TIMESTAMP`
+	if _, err := r.db.ExecContext(ctx, deleteQuery, record.Key); err != nil {
+		return err
+	}
+
+	insertQuery := `
+		INSERT INTO idempotency_keys (key, request_hash, transaction_id, expires_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP + $4 * INTERVAL '1 second')
+		ON CONFLICT (key) DO NOTHING
+	`
+	result, err := r.db.ExecContext(ctx, insertQuery, record.Key, record.RequestHash, record.TransactionID, ttl.Seconds())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrIdempotencyKeyExists
+	}
+
+	return nil
+}
+
+// DeleteExpired removes every record whose retention window has passed.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= CURRENT_TIMESTAMP`
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
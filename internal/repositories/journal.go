@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+type JournalRepository struct {
+	db DBTX
+}
+
+func NewJournalRepository(db DBTX) *JournalRepository {
+	return &JournalRepository{
+		db: db,
+	}
+}
+
+func (r *JournalRepository) CreateEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error) {
+	entryQuery := `
+		INSERT INTO journal_entries (kind, transaction_id)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, entryQuery, entry.Kind, entry.TransactionID).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	postingQuery := `
+		INSERT INTO postings (journal_entry_id, account_id, amount, currency, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	for i := range entry.Postings {
+		posting := &entry.Postings[i]
+		posting.JournalEntryID = entry.ID
+		posting.CreatedAt = entry.CreatedAt
+
+		err := r.db.QueryRowContext(
+			ctx,
+			postingQuery,
+			posting.JournalEntryID,
+			posting.AccountID,
+			posting.Amount.String(),
+			posting.Currency,
+			posting.CreatedAt,
+		).Scan(&posting.ID)
+		if err != nil {
+			return models.JournalEntry{}, err
+		}
+	}
+
+	return entry, nil
+}
+
+// SumPostings returns the account balance as recorded by the journal.
+func (r *JournalRepository) SumPostings(ctx context.Context, accountID int64) (decimal.Decimal, error) {
+	query := `SELECT COALESCE(SUM(amount), 0) FROM postings WHERE account_id = $1`
+
+	var sumStr string
+	if err := r.db.QueryRowContext(ctx, query, accountID).Scan(&sumStr); err != nil {
+		return decimal.Zero, err
+	}
+
+	return decimal.NewFromString(sumStr)
+}
+
+func (r *JournalRepository) SumPostingsUntil(ctx context.Context, accountID int64, until time.Time) (decimal.Decimal, error) {
+	query := `SELECT COALESCE(SUM(amount), 0) FROM postings WHERE account_id = $1 AND created_at <= $2`
+
+	var sumStr string
+	if err := r.db.QueryRowContext(ctx, query, accountID, until).Scan(&sumStr); err != nil {
+		return decimal.Zero, err
+	}
+
+	return decimal.NewFromString(sumStr)
+}
+
+func (r *JournalRepository) SumPostingsPostedUntil(ctx context.Context, accountID int64, until time.Time) (decimal.Decimal, error) {
+	query := `
+		SELECT COALESCE(SUM(p.amount), 0)
+		FROM postings p
+		JOIN journal_entries e ON e.id = p.journal_entry_id
+		LEFT JOIN transactions t ON t.id = e.transaction_id
+		WHERE p.account_id = $1 AND COALESCE(t.posted_at, p.created_at) <= $2
+	`
+
+	var sumStr string
+	if err := r.db.QueryRowContext(ctx, query, accountID, until).Scan(&sumStr); err != nil {
+		return decimal.Zero, err
+	}
+
+	return decimal.NewFromString(sumStr)
+}
+
+func (r *JournalRepository) ListEntries(ctx context.Context, accountID int64, after, until time.Time) ([]models.JournalEntry, error) {
+	query := `
+		SELECT e.id, e.kind, e.transaction_id, e.created_at, p.id, p.amount, p.currency, p.created_at
+		FROM postings p
+		JOIN journal_entries e ON e.id = p.journal_entry_id
+		WHERE p.account_id = $1 AND p.created_at > $2 AND p.created_at <= $3
+		ORDER BY p.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID, after, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.JournalEntry{}
+	for rows.Next() {
+		var entry models.JournalEntry
+		var transactionID sql.NullInt64
+		var posting models.Posting
+		var amountStr string
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Kind,
+			&transactionID,
+			&entry.CreatedAt,
+			&posting.ID,
+			&amountStr,
+			&posting.Currency,
+			&posting.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		posting.Amount, err = decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, err
+		}
+		posting.JournalEntryID = entry.ID
+		posting.AccountID = accountID
+
+		if n := len(entries); n > 0 && entries[n-1].ID == entry.ID {
+			entries[n-1].Postings = append(entries[n-1].Postings, posting)
+			continue
+		}
+
+		if transactionID.Valid {
+			entry.TransactionID = &transactionID.Int64
+		}
+		entry.Postings = []models.Posting{posting}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+type accountStore struct {
+	store
+}
+
+func (s *accountStore) Create(ctx context.Context, account models.Account) error {
+	return s.run(func(t *tx) error {
+		// Holding the row lock makes a concurrent insert of the same ID wait
+		// for this unit of work, like a unique index does in Postgres.
+		if err := t.lock(ctx, rowKey("accounts", account.AccountID)); err != nil {
+			return err
+		}
+
+		accounts := viewOf(t, s.db.accounts)
+		if _, ok := accounts.get(account.AccountID); ok {
+			return repository.ErrAccountExists
+		}
+
+		account.CreatedAt = t.now
+		accounts.put(account.AccountID, account)
+		return nil
+	})
+}
+
+func (s *accountStore) GetByID(ctx context.Context, accountID int64) (models.Account, error) {
+	var account models.Account
+	err := s.run(func(t *tx) error {
+		var ok bool
+		account, ok = viewOf(t, s.db.accounts).get(accountID)
+		if !ok {
+			return repository.ErrAccountNotFound
+		}
+		return nil
+	})
+	return account, err
+}
+
+func (s *accountStore) GetByIDForUpdate(ctx context.Context, accountID int64) (models.Account, error) {
+	var account models.Account
+	err := s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("accounts", accountID)); err != nil {
+			return err
+		}
+
+		var ok bool
+		account, ok = viewOf(t, s.db.accounts).get(accountID)
+		if !ok {
+			return repository.ErrAccountNotFound
+		}
+		return nil
+	})
+	return account, err
+}
+
+func (s *accountStore) List(ctx context.Context, q repository.AccountQuery) ([]models.Account, error) {
+	compare := func(a, b models.Account) int {
+		var c int
+		switch q.SortBy {
+		case repository.AccountSortBalance:
+			c = a.Balance.Cmp(b.Balance)
+		case repository.AccountSortCreatedAt:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		if c == 0 {
+			c = cmp.Compare(a.AccountID, b.AccountID)
+		}
+		if q.Descending {
+			c = -c
+		}
+		return c
+	}
+
+	var accounts []models.Account
+	err := s.run(func(t *tx) error {
+		accounts = viewOf(t, s.db.accounts).filter(func(account models.Account) bool {
+			switch {
+			case q.Status != "" && account.Status != q.Status,
+				q.Currency != "" && account.Currency != q.Currency,
+				q.MinBalance != nil && account.Balance.LessThan(*q.MinBalance),
+				q.MaxBalance != nil && account.Balance.GreaterThan(*q.MaxBalance),
+				q.CreatedFrom != nil && account.CreatedAt.Before(*q.CreatedFrom),
+				q.CreatedTo != nil && !account.CreatedAt.Before(*q.CreatedTo),
+				q.After != nil && compare(account, *q.After) <= 0:
+				return false
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(accounts, compare)
+	if len(accounts) > q.Limit {
+		accounts = accounts[:q.Limit]
+	}
+
+	return accounts, nil
+}
+
+func (s *accountStore) UpdateBalance(ctx context.Context, accountID int64, newBalance decimal.Decimal) error {
+	return s.update(ctx, accountID, func(account *models.Account) {
+		account.Balance = newBalance
+	})
+}
+
+func (s *accountStore) UpdateOverdraftLimit(ctx context.Context, accountID int64, limit decimal.Decimal) error {
+	return s.update(ctx, accountID, func(account *models.Account) {
+		account.OverdraftLimit = limit
+	})
+}
+
+func (s *accountStore) UpdateStatus(ctx context.Context, accountID int64, status string) error {
+	return s.update(ctx, accountID, func(account *models.Account) {
+		account.Status = status
+	})
+}
+
+func (s *accountStore) UpdateTier(ctx context.Context, accountID int64, tier string) error {
+	return s.update(ctx, accountID, func(account *models.Account) {
+		account.Tier = tier
+	})
+}
+
+func (s *accountStore) GetLimits(ctx context.Context, accountID int64) (models.TransferLimits, error) {
+	var limits models.TransferLimits
+	err := s.run(func(t *tx) error {
+		limits, _ = viewOf(t, s.db.accountLimits).get(accountID)
+		return nil
+	})
+	return limits, err
+}
+
+func (s *accountStore) SetLimits(ctx context.Context, accountID int64, limits models.TransferLimits) error {
+	return s.run(func(t *tx) error {
+		if _, ok := viewOf(t, s.db.accounts).get(accountID); !ok {
+			return repository.ErrAccountNotFound
+		}
+
+		if err := t.lock(ctx, rowKey("account_limits", accountID)); err != nil {
+			return err
+		}
+
+		viewOf(t, s.db.accountLimits).put(accountID, limits)
+		return nil
+	})
+}
+
+func (s *accountStore) CreateStatusChange(ctx context.Context, change models.AccountStatusChange) (models.AccountStatusChange, error) {
+	err := s.run(func(t *tx) error {
+		if _, ok := viewOf(t, s.db.accounts).get(change.AccountID); !ok {
+			return repository.ErrAccountNotFound
+		}
+
+		change.ID = s.db.nextID("account_status_changes")
+		change.CreatedAt = t.now
+
+		viewOf(t, s.db.statusChanges).put(change.ID, change)
+		return nil
+	})
+	if err != nil {
+		return models.AccountStatusChange{}, err
+	}
+
+	return change, nil
+}
+
+func (s *accountStore) ListStatusChanges(ctx context.Context, accountID int64) ([]models.AccountStatusChange, error) {
+	var changes []models.AccountStatusChange
+	err := s.run(func(t *tx) error {
+		changes = viewOf(t, s.db.statusChanges).filter(func(change models.AccountStatusChange) bool {
+			return change.AccountID == accountID
+		})
+		return nil
+	})
+	return changes, err
+}
+
+// update locks the account row and applies fn to it, like an UPDATE statement.
+func (s *accountStore) update(ctx context.Context, accountID int64, fn func(account *models.Account)) error {
+	return s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("accounts", accountID)); err != nil {
+			return err
+		}
+
+		accounts := viewOf(t, s.db.accounts)
+		account, ok := accounts.get(accountID)
+		if !ok {
+			return repository.ErrAccountNotFound
+		}
+
+		fn(&account)
+		accounts.put(accountID, account)
+		return nil
+	})
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+type apiKeyStore struct {
+	store
+}
+
+func (s *apiKeyStore) Create(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	err := s.run(func(t *tx) error {
+		// Stands in for the unique index on key_hash.
+		if err := t.lock(ctx, rowKey("api_keys.key_hash", key.KeyHash)); err != nil {
+			return err
+		}
+
+		keys := viewOf(t, s.db.apiKeys)
+		existing := keys.filter(func(k models.APIKey) bool {
+			return k.KeyHash == key.KeyHash
+		})
+		if len(existing) > 0 {
+			return repository.ErrAPIKeyExists
+		}
+
+		key.ID = s.db.nextID("api_keys")
+		key.CreatedAt = t.now
+
+		keys.put(key.ID, key)
+		return nil
+	})
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+func (s *apiKeyStore) GetByID(ctx context.Context, id int64) (models.APIKey, error) {
+	var key models.APIKey
+	err := s.run(func(t *tx) error {
+		var ok bool
+		key, ok = viewOf(t, s.db.apiKeys).get(id)
+		if !ok {
+			return repository.ErrAPIKeyNotFound
+		}
+		return nil
+	})
+	return key, err
+}
+
+func (s *apiKeyStore) GetByIDForUpdate(ctx context.Context, id int64) (models.APIKey, error) {
+	var key models.APIKey
+	err := s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("api_keys", id)); err != nil {
+			return err
+		}
+
+		var ok bool
+		key, ok = viewOf(t, s.db.apiKeys).get(id)
+		if !ok {
+			return repository.ErrAPIKeyNotFound
+		}
+		return nil
+	})
+	return key, err
+}
+
+func (s *apiKeyStore) GetByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	var key models.APIKey
+	err := s.run(func(t *tx) error {
+		keys := viewOf(t, s.db.apiKeys).filter(func(k models.APIKey) bool {
+			return k.KeyHash == keyHash
+		})
+		if len(keys) == 0 {
+			return repository.ErrAPIKeyNotFound
+		}
+
+		key = keys[0]
+		return nil
+	})
+	return key, err
+}
+
+func (s *apiKeyStore) GetByRotatedFrom(ctx context.Context, id int64) (models.APIKey, error) {
+	var key models.APIKey
+	err := s.run(func(t *tx) error {
+		keys := viewOf(t, s.db.apiKeys).filter(func(k models.APIKey) bool {
+			return k.RotatedFrom != nil && *k.RotatedFrom == id
+		})
+		if len(keys) == 0 {
+			return repository.ErrAPIKeyNotFound
+		}
+
+		key = keys[0]
+		return nil
+	})
+	return key, err
+}
+
+func (s *apiKeyStore) List(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := s.run(func(t *tx) error {
+		keys = viewOf(t, s.db.apiKeys).filter(func(models.APIKey) bool { return true })
+		return nil
+	})
+	return keys, err
+}
+
+func (s *apiKeyStore) Update(ctx context.Context, key models.APIKey) error {
+	return s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("api_keys", key.ID)); err != nil {
+			return err
+		}
+
+		keys := viewOf(t, s.db.apiKeys)
+		current, ok := keys.get(key.ID)
+		if !ok {
+			return repository.ErrAPIKeyNotFound
+		}
+
+		current.ExpiresAt = key.ExpiresAt
+		current.RevokedAt = key.RevokedAt
+		keys.put(key.ID, current)
+		return nil
+	})
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+type approvalStore struct {
+	store
+}
+
+func (s *approvalStore) CreateEvent(ctx context.Context, event models.ApprovalEvent) (models.ApprovalEvent, error) {
+	err := s.run(func(t *tx) error {
+		if _, ok := viewOf(t, s.db.transactions).get(event.TransactionID); !ok {
+			return repository.ErrTransactionNotFound
+		}
+
+		event.ID = s.db.nextID("transaction_approval_events")
+		event.CreatedAt = t.now
+
+		viewOf(t, s.db.approvalEvents).put(event.ID, event)
+		return nil
+	})
+	if err != nil {
+		return models.ApprovalEvent{}, err
+	}
+
+	return event, nil
+}
+
+func (s *approvalStore) ListEvents(ctx context.Context, transactionID int64) ([]models.ApprovalEvent, error) {
+	var events []models.ApprovalEvent
+	err := s.run(func(t *tx) error {
+		events = viewOf(t, s.db.approvalEvents).filter(func(event models.ApprovalEvent) bool {
+			return event.TransactionID == transactionID
+		})
+		return nil
+	})
+	return events, err
+}
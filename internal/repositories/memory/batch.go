@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+type batchStore struct {
+	store
+}
+
+func (s *batchStore) Create(ctx context.Context, batch models.TransactionBatch) (models.TransactionBatch, error) {
+	err := s.run(func(t *tx) error {
+		batch.ID = s.db.nextID("transaction_batches")
+		batch.CreatedAt = t.now
+		batch.Legs = nil
+
+		viewOf(t, s.db.batches).put(batch.ID, batch)
+		return nil
+	})
+	if err != nil {
+		return models.TransactionBatch{}, err
+	}
+
+	return batch, nil
+}
+
+func (s *batchStore) GetByID(ctx context.Context, id int64) (models.TransactionBatch, error) {
+	var batch models.TransactionBatch
+	err := s.run(func(t *tx) error {
+		var ok bool
+		batch, ok = viewOf(t, s.db.batches).get(id)
+		if !ok {
+			return repository.ErrBatchNotFound
+		}
+		return nil
+	})
+	return batch, err
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+type bulkStore struct {
+	store
+}
+
+func (s *bulkStore) CopyAccounts(ctx context.Context, accounts []models.Account) error {
+	return s.run(func(t *tx) error {
+		view := viewOf(t, s.db.accounts)
+		for _, account := range accounts {
+			if err := t.lock(ctx, rowKey("accounts", account.AccountID)); err != nil {
+				return err
+			}
+
+			if _, ok := view.get(account.AccountID); ok {
+				return repository.ErrAccountExists
+			}
+
+			view.put(account.AccountID, account)
+		}
+		return nil
+	})
+}
+
+func (s *bulkStore) CopyTransactions(ctx context.Context, transactions []models.Transaction) ([]models.Transaction, error) {
+	transactionStore := &transactionStore{s.store}
+
+	err := s.run(func(t *tx) error {
+		view := viewOf(t, s.db.transactions)
+		for i := range transactions {
+			transaction := &transactions[i]
+			if err := transactionStore.checkExternalReference(ctx, t, *transaction); err != nil {
+				return err
+			}
+
+			transaction.ID = s.db.nextID("transactions")
+			transaction.ReversedAmount = decimal.Zero
+			transaction.ReversalStatus = models.ReversalStatusNone
+			transaction.Status = models.TransactionStatusPosted
+			postedAt := transaction.CreatedAt
+			transaction.PostedAt = &postedAt
+
+			view.put(transaction.ID, *transaction)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (s *bulkStore) CopyJournalEntries(ctx context.Context, entries []models.JournalEntry) ([]models.JournalEntry, error) {
+	err := s.run(func(t *tx) error {
+		journal := viewOf(t, s.db.journal)
+		postings := viewOf(t, s.db.postings)
+
+		for i := range entries {
+			entry := &entries[i]
+			entry.ID = s.db.nextID("journal_entries")
+			entry.Postings = append([]models.Posting(nil), entry.Postings...)
+
+			for j := range entry.Postings {
+				posting := &entry.Postings[j]
+				posting.ID = s.db.nextID("postings")
+				posting.JournalEntryID = entry.ID
+				posting.CreatedAt = entry.CreatedAt
+
+				postings.put(posting.ID, *posting)
+			}
+
+			journal.put(entry.ID, *entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
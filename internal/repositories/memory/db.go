@@ -0,0 +1,367 @@
+// Package memory is an in-memory implementation of the repository stores,
+// intended for tests and local demos.
+//
+// It mirrors the transactional behaviour of the Postgres backend: a unit of
+// work sees its own uncommitted writes, other units of work only see committed
+// data, and GetByIDForUpdate (as well as any write to an existing row) holds a
+// row lock until the unit of work commits or rolls back. A lock wait that lasts
+// longer than LockTimeout is treated as a deadlock and reported as
+// repository.ErrTxConflict, which the services retry.
+//
+// Queries scan whole tables, so the backend is not meant for large data sets.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+// LockTimeout is how long a unit of work waits for a row lock before giving up
+// with repository.ErrTxConflict.
+const LockTimeout = time.Second
+
+type DB struct {
+	// mu guards the committed rows of every table.
+	mu    sync.RWMutex
+	locks lockManager
+
+	seqMu sync.Mutex
+	seqs  map[string]int64
+
+	accounts       *table[int64, models.Account]
+	accountLimits  *table[int64, models.TransferLimits]
+	statusChanges  *table[int64, models.AccountStatusChange]
+	transactions   *table[int64, models.Transaction]
+	journal        *table[int64, models.JournalEntry]
+	postings       *table[int64, models.Posting]
+	idempotency    *table[string, models.IdempotencyKey]
+	fxQuotes       *table[string, models.FXQuote]
+	holds          *table[int64, models.Hold]
+	batches        *table[int64, models.TransactionBatch]
+	schedules      *table[int64, models.ScheduledTransfer]
+	scheduleRuns   *table[int64, models.ScheduledTransferRun]
+	apiKeys        *table[int64, models.APIKey]
+	approvalEvents *table[int64, models.ApprovalEvent]
+	riskDecisions  *table[int64, models.RiskDecision]
+}
+
+func New() *DB {
+	db := &DB{
+		locks: lockManager{held: make(map[string]chan struct{})},
+		seqs:  make(map[string]int64),
+	}
+
+	db.accounts = newTable[int64, models.Account](db)
+	db.accountLimits = newTable[int64, models.TransferLimits](db)
+	db.statusChanges = newTable[int64, models.AccountStatusChange](db)
+	db.transactions = newTable[int64, models.Transaction](db)
+	db.journal = newTable[int64, models.JournalEntry](db)
+	db.postings = newTable[int64, models.Posting](db)
+	db.idempotency = newTable[string, models.IdempotencyKey](db)
+	db.fxQuotes = newTable[string, models.FXQuote](db)
+	db.holds = newTable[int64, models.Hold](db)
+	db.batches = newTable[int64, models.TransactionBatch](db)
+	db.schedules = newTable[int64, models.ScheduledTransfer](db)
+	db.scheduleRuns = newTable[int64, models.ScheduledTransferRun](db)
+	db.apiKeys = newTable[int64, models.APIKey](db)
+	db.approvalEvents = newTable[int64, models.ApprovalEvent](db)
+	db.riskDecisions = newTable[int64, models.RiskDecision](db)
+
+	return db
+}
+
+// Stores returns stores that run every call as its own unit of work.
+func (db *DB) Stores() repository.Stores {
+	return db.stores(nil)
+}
+
+func (db *DB) Do(ctx context.Context, fn func(stores repository.Stores) error) error {
+	t := db.begin()
+	defer t.rollback()
+
+	if err := fn(db.stores(t)); err != nil {
+		return err
+	}
+
+	t.commit()
+	return nil
+}
+
+func (db *DB) stores(t *tx) repository.Stores {
+	s := store{db: db, tx: t}
+	return repository.Stores{
+		Accounts:     &accountStore{s},
+		Transactions: &transactionStore{s},
+		Journal:      &journalStore{s},
+		Idempotency:  &idempotencyStore{s},
+		FXQuotes:     &fxQuoteStore{s},
+		Holds:        &holdStore{s},
+		Batches:      &batchStore{s},
+		Schedules:    &scheduleStore{s},
+		APIKeys:      &apiKeyStore{s},
+		Approvals:    &approvalStore{s},
+		Risk:         &riskStore{s},
+		Bulk:         &bulkStore{s},
+	}
+}
+
+// nextID mimics a Postgres sequence: IDs are never reused, even when the unit
+// of work that drew them rolls back.
+func (db *DB) nextID(sequence string) int64 {
+	db.seqMu.Lock()
+	defer db.seqMu.Unlock()
+
+	db.seqs[sequence]++
+	return db.seqs[sequence]
+}
+
+// store is embedded in every store implementation. A nil tx means each call
+// runs in its own unit of work.
+type store struct {
+	db *DB
+	tx *tx
+}
+
+func (s store) run(fn func(t *tx) error) error {
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+
+	t := s.db.begin()
+	defer t.rollback()
+
+	if err := fn(t); err != nil {
+		return err
+	}
+
+	t.commit()
+	return nil
+}
+
+type tx struct {
+	db *DB
+	// now is the unit of work's start time, like CURRENT_TIMESTAMP in Postgres.
+	now    time.Time
+	views  map[any]committer
+	locked []string
+	done   bool
+}
+
+func (db *DB) begin() *tx {
+	return &tx{
+		db:    db,
+		now:   time.Now().UTC(),
+		views: make(map[any]committer),
+	}
+}
+
+func (t *tx) commit() {
+	t.db.mu.Lock()
+	for _, v := range t.views {
+		v.commit()
+	}
+	t.db.mu.Unlock()
+
+	t.release()
+}
+
+func (t *tx) rollback() {
+	t.release()
+}
+
+func (t *tx) release() {
+	if t.done {
+		return
+	}
+	t.done = true
+
+	for _, key := range t.locked {
+		t.db.locks.release(key)
+	}
+}
+
+// lock takes the named row lock for the rest of the unit of work, waiting up to
+// LockTimeout for another unit of work to release it.
+func (t *tx) lock(ctx context.Context, key string) error {
+	if slices.Contains(t.locked, key) {
+		return nil
+	}
+
+	if err := t.db.locks.acquire(ctx, key); err != nil {
+		return err
+	}
+
+	t.locked = append(t.locked, key)
+	return nil
+}
+
+// tryLock takes the named row lock only if it is free, like SKIP LOCKED.
+func (t *tx) tryLock(key string) bool {
+	if slices.Contains(t.locked, key) {
+		return true
+	}
+
+	if !t.db.locks.tryAcquire(key) {
+		return false
+	}
+
+	t.locked = append(t.locked, key)
+	return true
+}
+
+func rowKey(table string, id any) string {
+	return fmt.Sprintf("%s/%v", table, id)
+}
+
+type lockManager struct {
+	mu   sync.Mutex
+	held map[string]chan struct{}
+}
+
+func (m *lockManager) slot(key string) chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch, ok := m.held[key]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		m.held[key] = ch
+	}
+	return ch
+}
+
+func (m *lockManager) acquire(ctx context.Context, key string) error {
+	timer := time.NewTimer(LockTimeout)
+	defer timer.Stop()
+
+	select {
+	case m.slot(key) <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return fmt.Errorf("%w: timed out waiting for lock on %s", repository.ErrTxConflict, key)
+	}
+}
+
+func (m *lockManager) tryAcquire(key string) bool {
+	select {
+	case m.slot(key) <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *lockManager) release(key string) {
+	<-m.slot(key)
+}
+
+// table holds the committed rows of one table, guarded by DB.mu.
+type table[K cmp.Ordered, V any] struct {
+	db   *DB
+	rows map[K]V
+}
+
+func newTable[K cmp.Ordered, V any](db *DB) *table[K, V] {
+	return &table[K, V]{
+		db:   db,
+		rows: make(map[K]V),
+	}
+}
+
+type committer interface {
+	commit()
+}
+
+// view is a unit of work's picture of a table: the committed rows overlaid
+// with its own uncommitted writes.
+type view[K cmp.Ordered, V any] struct {
+	table   *table[K, V]
+	writes  map[K]V
+	deletes map[K]struct{}
+}
+
+func viewOf[K cmp.Ordered, V any](t *tx, tbl *table[K, V]) *view[K, V] {
+	if v, ok := t.views[tbl]; ok {
+		return v.(*view[K, V])
+	}
+
+	v := &view[K, V]{
+		table:   tbl,
+		writes:  make(map[K]V),
+		deletes: make(map[K]struct{}),
+	}
+	t.views[tbl] = v
+	return v
+}
+
+func (v *view[K, V]) get(key K) (V, bool) {
+	if _, ok := v.deletes[key]; ok {
+		var zero V
+		return zero, false
+	}
+
+	if row, ok := v.writes[key]; ok {
+		return row, true
+	}
+
+	v.table.db.mu.RLock()
+	defer v.table.db.mu.RUnlock()
+
+	row, ok := v.table.rows[key]
+	return row, ok
+}
+
+func (v *view[K, V]) put(key K, row V) {
+	delete(v.deletes, key)
+	v.writes[key] = row
+}
+
+func (v *view[K, V]) delete(key K) {
+	delete(v.writes, key)
+	v.deletes[key] = struct{}{}
+}
+
+// filter returns the visible rows matching keep, ordered by key.
+func (v *view[K, V]) filter(keep func(row V) bool) []V {
+	v.table.db.mu.RLock()
+	keys := make([]K, 0, len(v.table.rows)+len(v.writes))
+	for key := range v.table.rows {
+		if _, ok := v.writes[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	v.table.db.mu.RUnlock()
+
+	for key := range v.writes {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	rows := []V{}
+	for _, key := range keys {
+		row, ok := v.get(key)
+		if ok && keep(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+func (v *view[K, V]) commit() {
+	for key := range v.deletes {
+		delete(v.table.rows, key)
+	}
+	for key, row := range v.writes {
+		v.table.rows[key] = row
+	}
+}
@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+func createAccount(t *testing.T, db *DB, id int64) {
+	t.Helper()
+
+	err := db.Stores().Accounts.Create(context.Background(), models.Account{
+		AccountID: id,
+		Status:    models.AccountStatusActive,
+		Balance:   decimal.NewFromInt(100),
+		Currency:  "USD",
+		Tier:      models.DefaultAccountTier,
+	})
+	if err != nil {
+		t.Fatalf("create account %d: %v", id, err)
+	}
+}
+
+// holdLock locks the account in a unit of work of its own until release is
+// closed. It returns once the lock is held.
+func holdLock(t *testing.T, db *DB, accountID int64, release <-chan struct{}) <-chan error {
+	t.Helper()
+
+	locked := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- db.Do(context.Background(), func(stores repository.Stores) error {
+			if _, err := stores.Accounts.GetByIDForUpdate(context.Background(), accountID); err != nil {
+				close(locked)
+				return err
+			}
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+
+	<-locked
+	return done
+}
+
+func TestLockWaitTimesOutAsConflict(t *testing.T) {
+	db := New()
+	createAccount(t, db, 1)
+
+	release := make(chan struct{})
+	holder := holdLock(t, db, 1, release)
+
+	start := time.Now()
+	err := db.Do(context.Background(), func(stores repository.Stores) error {
+		_, err := stores.Accounts.GetByIDForUpdate(context.Background(), 1)
+		return err
+	})
+	if !errors.Is(err, repository.ErrTxConflict) {
+		t.Fatalf("got %v, want ErrTxConflict", err)
+	}
+	if waited := time.Since(start); waited < LockTimeout {
+		t.Errorf("gave up after %s, before LockTimeout", waited)
+	}
+
+	close(release)
+	if err := <-holder; err != nil {
+		t.Fatalf("lock holder: %v", err)
+	}
+
+	// The lock is free again once its holder commits.
+	err = db.Do(context.Background(), func(stores repository.Stores) error {
+		_, err := stores.Accounts.GetByIDForUpdate(context.Background(), 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("lock after release: %v", err)
+	}
+}
+
+func TestLockWaitEndsWithContext(t *testing.T) {
+	db := New()
+	createAccount(t, db, 1)
+
+	release := make(chan struct{})
+	defer close(release)
+	holdLock(t, db, 1, release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := db.Do(ctx, func(stores repository.Stores) error {
+		_, err := stores.Accounts.GetByIDForUpdate(ctx, 1)
+		return err
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRollbackDiscardsWrites(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	failed := errors.New("failed")
+
+	err := db.Do(ctx, func(stores repository.Stores) error {
+		if err := stores.Accounts.Create(ctx, models.Account{AccountID: 1, Status: models.AccountStatusActive, Currency: "USD"}); err != nil {
+			return err
+		}
+
+		// The unit of work sees its own write.
+		if _, err := stores.Accounts.GetByID(ctx, 1); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("got %v, want the unit of work's error", err)
+	}
+
+	if _, err := db.Stores().Accounts.GetByID(ctx, 1); !errors.Is(err, repository.ErrAccountNotFound) {
+		t.Fatalf("got %v after rollback, want ErrAccountNotFound", err)
+	}
+}
+
+func TestUncommittedWritesAreNotVisible(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+
+	written := make(chan struct{})
+	checked := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- db.Do(ctx, func(stores repository.Stores) error {
+			err := stores.Accounts.Create(ctx, models.Account{AccountID: 1, Status: models.AccountStatusActive, Currency: "USD"})
+			close(written)
+			<-checked
+			return err
+		})
+	}()
+
+	<-written
+	_, err := db.Stores().Accounts.GetByID(ctx, 1)
+	close(checked)
+	if !errors.Is(err, repository.ErrAccountNotFound) {
+		t.Fatalf("got %v before commit, want ErrAccountNotFound", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := db.Stores().Accounts.GetByID(ctx, 1); err != nil {
+		t.Fatalf("after commit: %v", err)
+	}
+}
+
+func TestClaimDueSkipsLockedSchedules(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+
+	due := time.Now().Add(-time.Minute)
+	for range 2 {
+		_, err := db.Stores().Schedules.Create(ctx, models.ScheduledTransfer{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(1),
+			ExecuteAt:            due,
+			Status:               models.ScheduleStatusActive,
+			NextRunAt:            &due,
+		})
+		if err != nil {
+			t.Fatalf("create schedule: %v", err)
+		}
+	}
+
+	claimed := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- db.Do(ctx, func(stores repository.Stores) error {
+			schedules, err := stores.Schedules.ClaimDue(ctx, 1)
+			if err == nil && len(schedules) != 1 {
+				err = errors.New("first claim got no schedule")
+			}
+			close(claimed)
+			<-release
+			return err
+		})
+	}()
+
+	<-claimed
+	start := time.Now()
+	var second []models.ScheduledTransfer
+	err := db.Do(ctx, func(stores repository.Stores) error {
+		var err error
+		second, err = stores.Schedules.ClaimDue(ctx, 10)
+		return err
+	})
+	close(release)
+
+	if err != nil {
+		t.Fatalf("second claim: %v", err)
+	}
+	if waited := time.Since(start); waited >= LockTimeout {
+		t.Errorf("second claim waited %s for a locked schedule", waited)
+	}
+	if len(second) != 1 || second[0].ID != 2 {
+		t.Errorf("second claim got %+v, want only schedule 2", second)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+}
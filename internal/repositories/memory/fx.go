@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+type fxQuoteStore struct {
+	store
+}
+
+func (s *fxQuoteStore) Create(ctx context.Context, quote models.FXQuote) (models.FXQuote, error) {
+	err := s.run(func(t *tx) error {
+		quote.CreatedAt = t.now
+		viewOf(t, s.db.fxQuotes).put(quote.ID, quote)
+		return nil
+	})
+	if err != nil {
+		return models.FXQuote{}, err
+	}
+
+	return quote, nil
+}
+
+func (s *fxQuoteStore) GetByID(ctx context.Context, id string) (models.FXQuote, error) {
+	var quote models.FXQuote
+	err := s.run(func(t *tx) error {
+		var ok bool
+		quote, ok = viewOf(t, s.db.fxQuotes).get(id)
+		if !ok {
+			return repository.ErrFXQuoteNotFound
+		}
+		return nil
+	})
+	return quote, err
+}
+
+func (s *fxQuoteStore) GetByIDForUpdate(ctx context.Context, id string) (models.FXQuote, error) {
+	var quote models.FXQuote
+	err := s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("fx_quotes", id)); err != nil {
+			return err
+		}
+
+		var ok bool
+		quote, ok = viewOf(t, s.db.fxQuotes).get(id)
+		if !ok {
+			return repository.ErrFXQuoteNotFound
+		}
+		return nil
+	})
+	return quote, err
+}
+
+func (s *fxQuoteStore) MarkUsed(ctx context.Context, id string, transactionID int64) error {
+	return s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("fx_quotes", id)); err != nil {
+			return err
+		}
+
+		quotes := viewOf(t, s.db.fxQuotes)
+		quote, ok := quotes.get(id)
+		if !ok {
+			return repository.ErrFXQuoteNotFound
+		}
+
+		quote.TransactionID = &transactionID
+		quotes.put(id, quote)
+		return nil
+	})
+}
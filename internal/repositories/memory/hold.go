@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+type holdStore struct {
+	store
+}
+
+func (s *holdStore) Create(ctx context.Context, hold models.Hold) (models.Hold, error) {
+	err := s.run(func(t *tx) error {
+		hold.ID = s.db.nextID("holds")
+		hold.CapturedAmount = decimal.Zero
+		hold.CreatedAt = t.now
+		hold.UpdatedAt = t.now
+
+		viewOf(t, s.db.holds).put(hold.ID, hold)
+		return nil
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+func (s *holdStore) GetByID(ctx context.Context, id int64) (models.Hold, error) {
+	var hold models.Hold
+	err := s.run(func(t *tx) error {
+		var ok bool
+		hold, ok = viewOf(t, s.db.holds).get(id)
+		if !ok {
+			return repository.ErrHoldNotFound
+		}
+		return nil
+	})
+	return hold, err
+}
+
+func (s *holdStore) GetByIDForUpdate(ctx context.Context, id int64) (models.Hold, error) {
+	var hold models.Hold
+	err := s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("holds", id)); err != nil {
+			return err
+		}
+
+		var ok bool
+		hold, ok = viewOf(t, s.db.holds).get(id)
+		if !ok {
+			return repository.ErrHoldNotFound
+		}
+		return nil
+	})
+	return hold, err
+}
+
+func (s *holdStore) Update(ctx context.Context, hold models.Hold) error {
+	return s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("holds", hold.ID)); err != nil {
+			return err
+		}
+
+		holds := viewOf(t, s.db.holds)
+		current, ok := holds.get(hold.ID)
+		if !ok {
+			return repository.ErrHoldNotFound
+		}
+
+		current.Status = hold.Status
+		current.CapturedAmount = hold.CapturedAmount
+		current.TransactionID = hold.TransactionID
+		current.UpdatedAt = t.now
+		holds.put(hold.ID, current)
+		return nil
+	})
+}
+
+func (s *holdStore) SumActive(ctx context.Context, accountID int64) (decimal.Decimal, error) {
+	sum := decimal.Zero
+	err := s.run(func(t *tx) error {
+		active := viewOf(t, s.db.holds).filter(func(hold models.Hold) bool {
+			return hold.AccountID == accountID &&
+				hold.Status == models.HoldStatusActive &&
+				hold.ExpiresAt.After(t.now)
+		})
+
+		for _, hold := range active {
+			sum = sum.Add(hold.Amount)
+		}
+		return nil
+	})
+	return sum, err
+}
+
+func (s *holdStore) ExpireDue(ctx context.Context) (int64, error) {
+	var expired int64
+	err := s.run(func(t *tx) error {
+		holds := viewOf(t, s.db.holds)
+		due := holds.filter(func(hold models.Hold) bool {
+			return hold.Status == models.HoldStatusActive && !hold.ExpiresAt.After(t.now)
+		})
+
+		for _, hold := range due {
+			// A hold that is being captured or voided right now is left for
+			// the next run.
+			if !t.tryLock(rowKey("holds", hold.ID)) {
+				continue
+			}
+
+			current, ok := holds.get(hold.ID)
+			if !ok || current.Status != models.HoldStatusActive {
+				continue
+			}
+
+			current.Status = models.HoldStatusExpired
+			current.UpdatedAt = t.now
+			holds.put(hold.ID, current)
+			expired++
+		}
+		return nil
+	})
+	return expired, err
+}
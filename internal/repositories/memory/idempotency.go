@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+type idempotencyStore struct {
+	store
+}
+
+func (s *idempotencyStore) Get(ctx context.Context, key string) (models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	err := s.run(func(t *tx) error {
+		var ok bool
+		record, ok = viewOf(t, s.db.idempotency).get(key)
+		if !ok || !record.ExpiresAt.After(t.now) {
+			return repository.ErrIdempotencyKeyNotFound
+		}
+		return nil
+	})
+	return record, err
+}
+
+func (s *idempotencyStore) Create(ctx context.Context, record models.IdempotencyKey, ttl time.Duration) error {
+	return s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("idempotency_keys", record.Key)); err != nil {
+			return err
+		}
+
+		keys := viewOf(t, s.db.idempotency)
+		if existing, ok := keys.get(record.Key); ok && existing.ExpiresAt.After(t.now) {
+			return repository.ErrIdempotencyKeyExists
+		}
+
+		record.CreatedAt = t.now
+		record.ExpiresAt = t.now.Add(ttl)
+		keys.put(record.Key, record)
+		return nil
+	})
+}
+
+func (s *idempotencyStore) DeleteExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+	err := s.run(func(t *tx) error {
+		keys := viewOf(t, s.db.idempotency)
+		expired := keys.filter(func(record models.IdempotencyKey) bool {
+			return !record.ExpiresAt.After(t.now)
+		})
+
+		for _, record := range expired {
+			// A key that is being reused right now is left for the next run.
+			if !t.tryLock(rowKey("idempotency_keys", record.Key)) {
+				continue
+			}
+
+			current, ok := keys.get(record.Key)
+			if ok && !current.ExpiresAt.After(t.now) {
+				keys.delete(record.Key)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+type journalStore struct {
+	store
+}
+
+func (s *journalStore) CreateEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error) {
+	err := s.run(func(t *tx) error {
+		entry.ID = s.db.nextID("journal_entries")
+		entry.CreatedAt = t.now
+		entry.Postings = append([]models.Posting(nil), entry.Postings...)
+
+		postings := viewOf(t, s.db.postings)
+		for i := range entry.Postings {
+			posting := &entry.Postings[i]
+			posting.ID = s.db.nextID("postings")
+			posting.JournalEntryID = entry.ID
+			posting.CreatedAt = entry.CreatedAt
+
+			postings.put(posting.ID, *posting)
+		}
+
+		viewOf(t, s.db.journal).put(entry.ID, entry)
+		return nil
+	})
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func (s *journalStore) SumPostings(ctx context.Context, accountID int64) (decimal.Decimal, error) {
+	sum := decimal.Zero
+	err := s.run(func(t *tx) error {
+		postings := viewOf(t, s.db.postings).filter(func(posting models.Posting) bool {
+			return posting.AccountID == accountID
+		})
+		for _, posting := range postings {
+			sum = sum.Add(posting.Amount)
+		}
+		return nil
+	})
+	return sum, err
+}
+
+func (s *journalStore) SumPostingsUntil(ctx context.Context, accountID int64, until time.Time) (decimal.Decimal, error) {
+	sum := decimal.Zero
+	err := s.run(func(t *tx) error {
+		postings := viewOf(t, s.db.postings).filter(func(posting models.Posting) bool {
+			return posting.AccountID == accountID && !posting.CreatedAt.After(until)
+		})
+		for _, posting := range postings {
+			sum = sum.Add(posting.Amount)
+		}
+		return nil
+	})
+	return sum, err
+}
+
+func (s *journalStore) SumPostingsPostedUntil(ctx context.Context, accountID int64, until time.Time) (decimal.Decimal, error) {
+	sum := decimal.Zero
+	err := s.run(func(t *tx) error {
+		journal := viewOf(t, s.db.journal)
+		transactions := viewOf(t, s.db.transactions)
+
+		postings := viewOf(t, s.db.postings).filter(func(posting models.Posting) bool {
+			return posting.AccountID == accountID
+		})
+		for _, posting := range postings {
+			at := posting.CreatedAt
+			if entry, ok := journal.get(posting.JournalEntryID); ok && entry.TransactionID != nil {
+				if transaction, ok := transactions.get(*entry.TransactionID); ok && transaction.PostedAt != nil {
+					at = *transaction.PostedAt
+				}
+			}
+
+			if !at.After(until) {
+				sum = sum.Add(posting.Amount)
+			}
+		}
+		return nil
+	})
+	return sum, err
+}
+
+func (s *journalStore) ListEntries(ctx context.Context, accountID int64, after, until time.Time) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := s.run(func(t *tx) error {
+		entries = viewOf(t, s.db.journal).filter(func(entry models.JournalEntry) bool {
+			return entry.CreatedAt.After(after) && !entry.CreatedAt.After(until)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// An entry's ID is drawn just before its postings' IDs, so entry order is
+	// posting order.
+	kept := []models.JournalEntry{}
+	for _, entry := range entries {
+		var postings []models.Posting
+		for _, posting := range entry.Postings {
+			if posting.AccountID == accountID {
+				postings = append(postings, posting)
+			}
+		}
+
+		if len(postings) > 0 {
+			entry.Postings = postings
+			kept = append(kept, entry)
+		}
+	}
+
+	return kept, nil
+}
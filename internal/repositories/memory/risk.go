@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+type riskStore struct {
+	store
+}
+
+func (s *riskStore) CreateDecision(ctx context.Context, decision models.RiskDecision) (models.RiskDecision, error) {
+	err := s.run(func(t *tx) error {
+		if decision.TransactionID != nil {
+			if _, ok := viewOf(t, s.db.transactions).get(*decision.TransactionID); !ok {
+				return repository.ErrTransactionNotFound
+			}
+		}
+
+		decision.ID = s.db.nextID("risk_decisions")
+		decision.CreatedAt = t.now
+		decision.Hits = slices.Clone(decision.Hits)
+
+		viewOf(t, s.db.riskDecisions).put(decision.ID, decision)
+		return nil
+	})
+	if err != nil {
+		return models.RiskDecision{}, err
+	}
+
+	return decision, nil
+}
+
+func (s *riskStore) ListDecisions(ctx context.Context, q repository.RiskDecisionQuery) ([]models.RiskDecision, error) {
+	var decisions []models.RiskDecision
+	err := s.run(func(t *tx) error {
+		decisions = viewOf(t, s.db.riskDecisions).filter(func(decision models.RiskDecision) bool {
+			switch {
+			case q.SourceAccountID != 0 && decision.SourceAccountID != q.SourceAccountID,
+				q.TransactionID != 0 && (decision.TransactionID == nil || *decision.TransactionID != q.TransactionID),
+				q.Action != "" && decision.Action != q.Action:
+				return false
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first, matching ORDER BY id DESC.
+	slices.Reverse(decisions)
+
+	return page(decisions, q.Limit, q.Offset), nil
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+type scheduleStore struct {
+	store
+}
+
+func (s *scheduleStore) Create(ctx context.Context, schedule models.ScheduledTransfer) (models.ScheduledTransfer, error) {
+	err := s.run(func(t *tx) error {
+		schedule.ID = s.db.nextID("scheduled_transfers")
+		schedule.Occurrences = 0
+		schedule.CreatedAt = t.now
+		schedule.UpdatedAt = t.now
+
+		viewOf(t, s.db.schedules).put(schedule.ID, schedule)
+		return nil
+	})
+	if err != nil {
+		return models.ScheduledTransfer{}, err
+	}
+
+	return schedule, nil
+}
+
+func (s *scheduleStore) GetByID(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	var schedule models.ScheduledTransfer
+	err := s.run(func(t *tx) error {
+		var ok bool
+		schedule, ok = viewOf(t, s.db.schedules).get(id)
+		if !ok {
+			return repository.ErrScheduledTransferNotFound
+		}
+		return nil
+	})
+	return schedule, err
+}
+
+func (s *scheduleStore) GetByIDForUpdate(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	var schedule models.ScheduledTransfer
+	err := s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("scheduled_transfers", id)); err != nil {
+			return err
+		}
+
+		var ok bool
+		schedule, ok = viewOf(t, s.db.schedules).get(id)
+		if !ok {
+			return repository.ErrScheduledTransferNotFound
+		}
+		return nil
+	})
+	return schedule, err
+}
+
+func (s *scheduleStore) Update(ctx context.Context, schedule models.ScheduledTransfer) error {
+	return s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("scheduled_transfers", schedule.ID)); err != nil {
+			return err
+		}
+
+		schedules := viewOf(t, s.db.schedules)
+		current, ok := schedules.get(schedule.ID)
+		if !ok {
+			return repository.ErrScheduledTransferNotFound
+		}
+
+		current.Status = schedule.Status
+		current.Occurrences = schedule.Occurrences
+		current.NextRunAt = schedule.NextRunAt
+		current.UpdatedAt = t.now
+		schedules.put(schedule.ID, current)
+		return nil
+	})
+}
+
+func (s *scheduleStore) ClaimDue(ctx context.Context, limit int) ([]models.ScheduledTransfer, error) {
+	claimed := []models.ScheduledTransfer{}
+	err := s.run(func(t *tx) error {
+		schedules := viewOf(t, s.db.schedules)
+		isDue := func(schedule models.ScheduledTransfer) bool {
+			return schedule.Status == models.ScheduleStatusActive &&
+				schedule.NextRunAt != nil &&
+				!schedule.NextRunAt.After(t.now)
+		}
+
+		due := schedules.filter(isDue)
+		slices.SortStableFunc(due, func(a, b models.ScheduledTransfer) int {
+			return a.NextRunAt.Compare(*b.NextRunAt)
+		})
+
+		for _, schedule := range due {
+			if len(claimed) == limit {
+				break
+			}
+
+			if !t.tryLock(rowKey("scheduled_transfers", schedule.ID)) {
+				continue
+			}
+
+			// Another unit of work may have run it before we got the lock.
+			current, ok := schedules.get(schedule.ID)
+			if ok && isDue(current) {
+				claimed = append(claimed, current)
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+func (s *scheduleStore) CreateRun(ctx context.Context, run models.ScheduledTransferRun) (models.ScheduledTransferRun, error) {
+	err := s.run(func(t *tx) error {
+		run.ID = s.db.nextID("scheduled_transfer_runs")
+		run.ExecutedAt = t.now
+
+		viewOf(t, s.db.scheduleRuns).put(run.ID, run)
+		return nil
+	})
+	if err != nil {
+		return models.ScheduledTransferRun{}, err
+	}
+
+	return run, nil
+}
+
+func (s *scheduleStore) ListRuns(ctx context.Context, scheduleID int64) ([]models.ScheduledTransferRun, error) {
+	var runs []models.ScheduledTransferRun
+	err := s.run(func(t *tx) error {
+		runs = viewOf(t, s.db.scheduleRuns).filter(func(run models.ScheduledTransferRun) bool {
+			return run.ScheduledTransferID == scheduleID
+		})
+		return nil
+	})
+	return runs, err
+}
@@ -0,0 +1,340 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+type transactionStore struct {
+	store
+}
+
+func (s *transactionStore) Create(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	err := s.run(func(t *tx) error {
+		if err := s.checkExternalReference(ctx, t, transaction); err != nil {
+			return err
+		}
+
+		transaction.ID = s.db.nextID("transactions")
+		transaction.CreatedAt = t.now
+		transaction.ReversedAmount = decimal.Zero
+		transaction.ReversalStatus = models.ReversalStatusNone
+		if transaction.Status == "" {
+			transaction.Status = models.TransactionStatusPosted
+		}
+		transaction.PostedAt = postedAt(t, transaction.Status)
+
+		viewOf(t, s.db.transactions).put(transaction.ID, transaction)
+		return nil
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+func (s *transactionStore) GetByID(ctx context.Context, id int64) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := s.run(func(t *tx) error {
+		var ok bool
+		transaction, ok = viewOf(t, s.db.transactions).get(id)
+		if !ok {
+			return repository.ErrTransactionNotFound
+		}
+		return nil
+	})
+	return transaction, err
+}
+
+func (s *transactionStore) GetByIDForUpdate(ctx context.Context, id int64) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("transactions", id)); err != nil {
+			return err
+		}
+
+		var ok bool
+		transaction, ok = viewOf(t, s.db.transactions).get(id)
+		if !ok {
+			return repository.ErrTransactionNotFound
+		}
+		return nil
+	})
+	return transaction, err
+}
+
+// checkExternalReference stands in for the unique index Postgres keeps on
+// (external_reference, source_account_id). The lock is held until the unit
+// of work ends, so a concurrent insert of the same pair waits and then fails.
+func (s *transactionStore) checkExternalReference(ctx context.Context, t *tx, transaction models.Transaction) error {
+	if transaction.ExternalReference == "" {
+		return nil
+	}
+
+	key := fmt.Sprintf("%d/%s", transaction.SourceAccountID, transaction.ExternalReference)
+	if err := t.lock(ctx, rowKey("transactions.external_reference", key)); err != nil {
+		return err
+	}
+
+	if _, ok := s.findByExternalReference(t, transaction.SourceAccountID, transaction.ExternalReference); ok {
+		return repository.ErrExternalReferenceExists
+	}
+	return nil
+}
+
+func (s *transactionStore) GetByExternalReference(ctx context.Context, sourceAccountID int64, externalReference string) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := s.run(func(t *tx) error {
+		var ok bool
+		transaction, ok = s.findByExternalReference(t, sourceAccountID, externalReference)
+		if !ok {
+			return repository.ErrTransactionNotFound
+		}
+		return nil
+	})
+	return transaction, err
+}
+
+func (s *transactionStore) ListByExternalReference(ctx context.Context, externalReference string) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := s.run(func(t *tx) error {
+		transactions = viewOf(t, s.db.transactions).filter(func(transaction models.Transaction) bool {
+			return transaction.ExternalReference == externalReference
+		})
+		return nil
+	})
+	return transactions, err
+}
+
+// findByExternalReference only matches transfers that hold their reference,
+// like the partial unique index in Postgres.
+func (s *transactionStore) findByExternalReference(t *tx, sourceAccountID int64, externalReference string) (models.Transaction, bool) {
+	matches := viewOf(t, s.db.transactions).filter(func(transaction models.Transaction) bool {
+		return transaction.SourceAccountID == sourceAccountID && transaction.ExternalReference == externalReference &&
+			(transaction.Status == models.TransactionStatusPosted || transaction.Status == models.TransactionStatusPendingApproval)
+	})
+	if len(matches) == 0 {
+		return models.Transaction{}, false
+	}
+	return matches[0], true
+}
+
+func (s *transactionStore) ListReversals(ctx context.Context, id int64) ([]models.Transaction, error) {
+	var reversals []models.Transaction
+	err := s.run(func(t *tx) error {
+		reversals = viewOf(t, s.db.transactions).filter(func(transaction models.Transaction) bool {
+			return transaction.ReversalOf != nil && *transaction.ReversalOf == id
+		})
+		return nil
+	})
+	return reversals, err
+}
+
+func (s *transactionStore) ListByBatch(ctx context.Context, batchID int64) ([]models.Transaction, error) {
+	var legs []models.Transaction
+	err := s.run(func(t *tx) error {
+		legs = viewOf(t, s.db.transactions).filter(func(transaction models.Transaction) bool {
+			return transaction.BatchID != nil && *transaction.BatchID == batchID
+		})
+		return nil
+	})
+	return legs, err
+}
+
+func (s *transactionStore) UpdateReversal(ctx context.Context, id int64, reversedAmount decimal.Decimal, status string) error {
+	return s.update(ctx, id, func(transaction *models.Transaction) {
+		transaction.ReversedAmount = reversedAmount
+		transaction.ReversalStatus = status
+	})
+}
+
+func (s *transactionStore) UpdateStatus(ctx context.Context, transaction models.Transaction) error {
+	return s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("transactions", transaction.ID)); err != nil {
+			return err
+		}
+
+		transactions := viewOf(t, s.db.transactions)
+		current, ok := transactions.get(transaction.ID)
+		if !ok {
+			return repository.ErrTransactionNotFound
+		}
+
+		current.Status = transaction.Status
+		current.DestinationAmount = transaction.DestinationAmount
+		current.FXRate = transaction.FXRate
+		current.PostedAt = postedAt(t, current.Status)
+
+		transactions.put(current.ID, current)
+		return nil
+	})
+}
+
+func (s *transactionStore) ExpirePending(ctx context.Context) ([]int64, error) {
+	var expired []int64
+	err := s.run(func(t *tx) error {
+		transactions := viewOf(t, s.db.transactions)
+		due := transactions.filter(func(transaction models.Transaction) bool {
+			return transaction.Status == models.TransactionStatusPendingApproval && !transaction.ApprovalExpiresAt.After(t.now)
+		})
+
+		for _, transaction := range due {
+			// A transfer that is being approved or rejected right now is
+			// left for the next run.
+			if !t.tryLock(rowKey("transactions", transaction.ID)) {
+				continue
+			}
+
+			current, ok := transactions.get(transaction.ID)
+			if !ok || current.Status != models.TransactionStatusPendingApproval {
+				continue
+			}
+
+			current.Status = models.TransactionStatusExpired
+			transactions.put(transaction.ID, current)
+			expired = append(expired, transaction.ID)
+		}
+		return nil
+	})
+	return expired, err
+}
+
+func (s *transactionStore) SumOutbound(ctx context.Context, accountID int64, since time.Time) (repository.OutboundTotals, error) {
+	outbound, err := s.ListOutbound(ctx, accountID, since)
+	if err != nil {
+		return repository.OutboundTotals{}, err
+	}
+
+	totals := repository.OutboundTotals{Amount: decimal.Zero}
+	for _, transaction := range outbound {
+		totals.Amount = totals.Amount.Add(transaction.Amount)
+		totals.Count++
+	}
+	if len(outbound) > 0 {
+		earliest := outbound[0].CreatedAt
+		totals.Earliest = &earliest
+	}
+
+	return totals, nil
+}
+
+func (s *transactionStore) ListOutbound(ctx context.Context, accountID int64, since time.Time) ([]models.Transaction, error) {
+	var outbound []models.Transaction
+	err := s.run(func(t *tx) error {
+		outbound = viewOf(t, s.db.transactions).filter(func(transaction models.Transaction) bool {
+			return transaction.SourceAccountID == accountID && transaction.Kind == models.TransactionKindTransfer &&
+				(transaction.Status == models.TransactionStatusPosted || transaction.Status == models.TransactionStatusPendingApproval) &&
+				!transaction.CreatedAt.Before(since)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Oldest first, matching ORDER BY created_at, id.
+	slices.SortStableFunc(outbound, func(a, b models.Transaction) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return outbound, nil
+}
+
+// postedAt stands in for the posted_at Postgres sets when a transaction is
+// written as posted.
+func postedAt(t *tx, status string) *time.Time {
+	if status != models.TransactionStatusPosted {
+		return nil
+	}
+	now := t.now
+	return &now
+}
+
+// update locks the transaction row and applies fn to it, like an UPDATE
+// statement.
+func (s *transactionStore) update(ctx context.Context, id int64, fn func(transaction *models.Transaction)) error {
+	return s.run(func(t *tx) error {
+		if err := t.lock(ctx, rowKey("transactions", id)); err != nil {
+			return err
+		}
+
+		transactions := viewOf(t, s.db.transactions)
+		transaction, ok := transactions.get(id)
+		if !ok {
+			return repository.ErrTransactionNotFound
+		}
+
+		fn(&transaction)
+		transactions.put(id, transaction)
+		return nil
+	})
+}
+
+func (s *transactionStore) ListByAccount(ctx context.Context, accountID int64, limit, offset int) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := s.run(func(t *tx) error {
+		transactions = viewOf(t, s.db.transactions).filter(func(transaction models.Transaction) bool {
+			return transaction.SourceAccountID == accountID || transaction.DestinationAccountID == accountID
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first, matching ORDER BY created_at DESC, id DESC.
+	slices.Reverse(transactions)
+	slices.SortStableFunc(transactions, func(a, b models.Transaction) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return page(transactions, limit, offset), nil
+}
+
+func (s *transactionStore) EachByAccount(ctx context.Context, accountID int64, after, until time.Time, fn func(models.Transaction) error) error {
+	var transactions []models.Transaction
+	err := s.run(func(t *tx) error {
+		transactions = viewOf(t, s.db.transactions).filter(func(transaction models.Transaction) bool {
+			return (transaction.SourceAccountID == accountID || transaction.DestinationAccountID == accountID) &&
+				transaction.Status == models.TransactionStatusPosted &&
+				transaction.PostedAt.After(after) && !transaction.PostedAt.After(until)
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Posting order, matching ORDER BY posted_at, id.
+	slices.SortStableFunc(transactions, func(a, b models.Transaction) int {
+		return a.PostedAt.Compare(*b.PostedAt)
+	})
+
+	for _, transaction := range transactions {
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func page[T any](rows []T, limit, offset int) []T {
+	if offset >= len(rows) {
+		return []T{}
+	}
+
+	rows = rows[offset:]
+	if limit < len(rows) {
+		rows = rows[:limit]
+	}
+
+	return rows
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+const (
+	sqlStateUniqueViolation     = "23505"
+	sqlStateForeignKeyViolation = "23503"
+	sqlStateSerialization       = "40001"
+	sqlStateDeadlock            = "40P01"
+)
+
+// DBTX is the subset of *sql.DB and *sql.Tx the Postgres repositories use, so
+// that the same repository can run on its own or inside a transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+func NewPostgresStores(db DBTX) Stores {
+	return Stores{
+		Accounts:     NewAccountRepository(db),
+		Transactions: NewTransactionRepository(db),
+		Journal:      NewJournalRepository(db),
+		Idempotency:  NewIdempotencyRepository(db),
+		FXQuotes:     NewFXQuoteRepository(db),
+		Holds:        NewHoldRepository(db),
+		Batches:      NewBatchRepository(db),
+		Schedules:    NewScheduleRepository(db),
+		APIKeys:      NewAPIKeyRepository(db),
+		Approvals:    NewApprovalRepository(db),
+		Risk:         NewRiskRepository(db),
+		Bulk:         NewBulkRepository(db),
+	}
+}
+
+type PostgresUnitOfWork struct {
+	db *sql.DB
+}
+
+func NewPostgresUnitOfWork(db *sql.DB) *PostgresUnitOfWork {
+	return &PostgresUnitOfWork{
+		db: db,
+	}
+}
+
+func (u *PostgresUnitOfWork) Do(ctx context.Context, fn func(stores Stores) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Rollback is a no-op once the transaction has been committed.
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := fn(NewPostgresStores(tx)); err != nil {
+		return wrapTxConflict(err)
+	}
+
+	return wrapTxConflict(tx.Commit())
+}
+
+func wrapTxConflict(err error) error {
+	if hasSQLState(err, sqlStateDeadlock) || hasSQLState(err, sqlStateSerialization) {
+		return fmt.Errorf("%w: %w", ErrTxConflict, err)
+	}
+
+	return err
+}
+
+func hasSQLState(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
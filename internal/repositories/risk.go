@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+const riskDecisionColumns = `id, transaction_id, source_account_id, destination_account_id, amount, currency, action, score, hits, rules_version, created_at`
+
+// RiskDecisionQuery selects a page of risk decisions, newest first. Zero
+// fields do not filter.
+type RiskDecisionQuery struct {
+	SourceAccountID int64
+	TransactionID   int64
+	Action          string
+	Limit           int
+	Offset          int
+}
+
+type RiskRepository struct {
+	db DBTX
+}
+
+func NewRiskRepository(db DBTX) *RiskRepository {
+	return &RiskRepository{
+		db: db,
+	}
+}
+
+func (r *RiskRepository) CreateDecision(ctx context.Context, decision models.RiskDecision) (models.RiskDecision, error) {
+	query := `
+		INSERT INTO risk_decisions (transaction_id, source_account_id, destination_account_id, amount, currency, action, score, hits, rules_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	// Passed as text, since lib/pq would send a []byte in binary form.
+	hits, err := json.Marshal(decision.Hits)
+	if err != nil {
+		return models.RiskDecision{}, err
+	}
+
+	var transactionID sql.NullInt64
+	if decision.TransactionID != nil {
+		transactionID = sql.NullInt64{Int64: *decision.TransactionID, Valid: true}
+	}
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		transactionID,
+		decision.SourceAccountID,
+		decision.DestinationAccountID,
+		decision.Amount.String(),
+		decision.Currency,
+		decision.Action,
+		decision.Score,
+		string(hits),
+		decision.RulesVersion,
+	).Scan(&decision.ID, &decision.CreatedAt)
+	if err != nil {
+		return models.RiskDecision{}, err
+	}
+
+	return decision, nil
+}
+
+func (r *RiskRepository) ListDecisions(ctx context.Context, q RiskDecisionQuery) ([]models.RiskDecision, error) {
+	var conditions []string
+	var args []any
+
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.SourceAccountID != 0 {
+		conditions = append(conditions, "source_account_id = "+arg(q.SourceAccountID))
+	}
+	if q.TransactionID != 0 {
+		conditions = append(conditions, "transaction_id = "+arg(q.TransactionID))
+	}
+	if q.Action != "" {
+		conditions = append(conditions, "action = "+arg(q.Action))
+	}
+
+	query := `SELECT ` + riskDecisionColumns + ` FROM risk_decisions`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY id DESC LIMIT ` + arg(q.Limit) + ` OFFSET ` + arg(q.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decisions := []models.RiskDecision{}
+	for rows.Next() {
+		decision, err := scanRiskDecision(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		decisions = append(decisions, decision)
+	}
+
+	return decisions, rows.Err()
+}
+
+func scanRiskDecision(row rowScanner) (models.RiskDecision, error) {
+	var decision models.RiskDecision
+	var transactionID sql.NullInt64
+	var amountStr string
+	var hits []byte
+
+	err := row.Scan(
+		&decision.ID,
+		&transactionID,
+		&decision.SourceAccountID,
+		&decision.DestinationAccountID,
+		&amountStr,
+		&decision.Currency,
+		&decision.Action,
+		&decision.Score,
+		&hits,
+		&decision.RulesVersion,
+		&decision.CreatedAt,
+	)
+	if err != nil {
+		return models.RiskDecision{}, err
+	}
+
+	if transactionID.Valid {
+		decision.TransactionID = &transactionID.Int64
+	}
+
+	decision.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return models.RiskDecision{}, err
+	}
+
+	if err := json.Unmarshal(hits, &decision.Hits); err != nil {
+		return models.RiskDecision{}, err
+	}
+
+	return decision, nil
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrScheduledTransferNotFound = errors.New("Scheduled transfer not Found")
+)
+
+const scheduleColumns = `
+	id, source_account_id, destination_account_id, amount, fx_mode, execute_at,
+	frequency, end_at, max_occurrences, occurrences, status, next_run_at,
+	initiated_by, created_at, updated_at
+`
+
+type ScheduleRepository struct {
+	db DBTX
+}
+
+func NewScheduleRepository(db DBTX) *ScheduleRepository {
+	return &ScheduleRepository{
+		db: db,
+	}
+}
+
+func (r *ScheduleRepository) Create(ctx context.Context, schedule models.ScheduledTransfer) (models.ScheduledTransfer, error) {
+	query := `
+		INSERT INTO scheduled_transfers (
+			source_account_id, destination_account_id, amount, fx_mode, execute_at,
+			frequency, end_at, max_occurrences, status, next_run_at, initiated_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + scheduleColumns
+
+	return scanSchedule(r.db.QueryRowContext(
+		ctx,
+		query,
+		schedule.SourceAccountID,
+		schedule.DestinationAccountID,
+		schedule.Amount.String(),
+		schedule.FXMode,
+		schedule.ExecuteAt,
+		sql.NullString{String: schedule.Frequency, Valid: schedule.Frequency != ""},
+		schedule.EndAt,
+		sql.NullInt64{Int64: int64(schedule.MaxOccurrences), Valid: schedule.MaxOccurrences > 0},
+		schedule.Status,
+		schedule.NextRunAt,
+		sql.NullString{String: schedule.InitiatedBy, Valid: schedule.InitiatedBy != ""},
+	))
+}
+
+func (r *ScheduleRepository) GetByID(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM scheduled_transfers WHERE id = $1`
+	return scanSchedule(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *ScheduleRepository) GetByIDForUpdate(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM scheduled_transfers WHERE id = $1 FOR UPDATE`
+	return scanSchedule(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *ScheduleRepository) Update(ctx context.Context, schedule models.ScheduledTransfer) error {
+	query := `
+		UPDATE scheduled_transfers
+		SET status = $1, occurrences = $2, next_run_at = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`
+	result, err := r.db.ExecContext(ctx, query, schedule.Status, schedule.Occurrences, schedule.NextRunAt, schedule.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrScheduledTransferNotFound
+	}
+
+	return nil
+}
+
+// ClaimDue uses SKIP LOCKED so that several server replicas can poll at once
+// without executing the same schedule twice.
+func (r *ScheduleRepository) ClaimDue(ctx context.Context, limit int) ([]models.ScheduledTransfer, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM scheduled_transfers
+		WHERE status = 'active' AND next_run_at <= CURRENT_TIMESTAMP
+		ORDER BY next_run_at, id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []models.ScheduledTransfer{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (r *ScheduleRepository) CreateRun(ctx context.Context, run models.ScheduledTransferRun) (models.ScheduledTransferRun, error) {
+	query := `
+		INSERT INTO scheduled_transfer_runs (scheduled_transfer_id, occurrence, scheduled_for, status, transaction_id, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, executed_at
+	`
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		run.ScheduledTransferID,
+		run.Occurrence,
+		run.ScheduledFor,
+		run.Status,
+		run.TransactionID,
+		sql.NullString{String: run.Error, Valid: run.Error != ""},
+	).Scan(&run.ID, &run.ExecutedAt)
+	if err != nil {
+		return models.ScheduledTransferRun{}, err
+	}
+
+	return run, nil
+}
+
+// ListRuns returns the schedule's execution attempts, oldest first.
+func (r *ScheduleRepository) ListRuns(ctx context.Context, scheduleID int64) ([]models.ScheduledTransferRun, error) {
+	query := `
+		SELECT id, scheduled_transfer_id, occurrence, scheduled_for, status, transaction_id, error, executed_at
+		FROM scheduled_transfer_runs
+		WHERE scheduled_transfer_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []models.ScheduledTransferRun{}
+	for rows.Next() {
+		var run models.ScheduledTransferRun
+		var transactionID sql.NullInt64
+		var errorMessage sql.NullString
+
+		err := rows.Scan(
+			&run.ID,
+			&run.ScheduledTransferID,
+			&run.Occurrence,
+			&run.ScheduledFor,
+			&run.Status,
+			&transactionID,
+			&errorMessage,
+			&run.ExecutedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if transactionID.Valid {
+			run.TransactionID = &transactionID.Int64
+		}
+		run.Error = errorMessage.String
+
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+func scanSchedule(row rowScanner) (models.ScheduledTransfer, error) {
+	var schedule models.ScheduledTransfer
+	var amountStr string
+	var frequency, initiatedBy sql.NullString
+	var endAt, nextRunAt sql.NullTime
+	var maxOccurrences sql.NullInt64
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.SourceAccountID,
+		&schedule.DestinationAccountID,
+		&amountStr,
+		&schedule.FXMode,
+		&schedule.ExecuteAt,
+		&frequency,
+		&endAt,
+		&maxOccurrences,
+		&schedule.Occurrences,
+		&schedule.Status,
+		&nextRunAt,
+		&initiatedBy,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ScheduledTransfer{}, ErrScheduledTransferNotFound
+		}
+		return models.ScheduledTransfer{}, err
+	}
+
+	schedule.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return models.ScheduledTransfer{}, err
+	}
+
+	schedule.Frequency = frequency.String
+	schedule.MaxOccurrences = int(maxOccurrences.Int64)
+	schedule.InitiatedBy = initiatedBy.String
+
+	if endAt.Valid {
+		schedule.EndAt = &endAt.Time
+	}
+
+	if nextRunAt.Valid {
+		schedule.NextRunAt = &nextRunAt.Time
+	}
+
+	return schedule, nil
+}
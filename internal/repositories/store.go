@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrTxConflict reports that a unit of work was aborted because it
+	// conflicted with a concurrent one (a deadlock or serialization failure).
+	// Running the whole unit of work again may succeed.
+	ErrTxConflict = errors.New("Transaction conflict")
+)
+
+type AccountStore interface {
+	Create(ctx context.Context, account models.Account) error
+	GetByID(ctx context.Context, accountID int64) (models.Account, error)
+	// GetByIDForUpdate reads the account and locks it until the unit of work
+	// ends.
+	GetByIDForUpdate(ctx context.Context, accountID int64) (models.Account, error)
+	List(ctx context.Context, q AccountQuery) ([]models.Account, error)
+	UpdateBalance(ctx context.Context, accountID int64, newBalance decimal.Decimal) error
+	UpdateOverdraftLimit(ctx context.Context, accountID int64, limit decimal.Decimal) error
+	UpdateStatus(ctx context.Context, accountID int64, status string) error
+	UpdateTier(ctx context.Context, accountID int64, tier string) error
+	// GetLimits returns the account's own transfer limits, all nil if it has
+	// none.
+	GetLimits(ctx context.Context, accountID int64) (models.TransferLimits, error)
+	// SetLimits replaces the account's own transfer limits.
+	SetLimits(ctx context.Context, accountID int64, limits models.TransferLimits) error
+	CreateStatusChange(ctx context.Context, change models.AccountStatusChange) (models.AccountStatusChange, error)
+	ListStatusChanges(ctx context.Context, accountID int64) ([]models.AccountStatusChange, error)
+}
+
+type TransactionStore interface {
+	Create(ctx context.Context, transaction models.Transaction) (models.Transaction, error)
+	GetByID(ctx context.Context, id int64) (models.Transaction, error)
+	GetByIDForUpdate(ctx context.Context, id int64) (models.Transaction, error)
+	GetByExternalReference(ctx context.Context, sourceAccountID int64, externalReference string) (models.Transaction, error)
+	ListByExternalReference(ctx context.Context, externalReference string) ([]models.Transaction, error)
+	ListByAccount(ctx context.Context, accountID int64, limit, offset int) ([]models.Transaction, error)
+	// EachByAccount streams the account's posted transactions, posted after
+	// after and up to until, to fn in posting order, stopping at fn's first
+	// error.
+	EachByAccount(ctx context.Context, accountID int64, after, until time.Time, fn func(models.Transaction) error) error
+	ListReversals(ctx context.Context, id int64) ([]models.Transaction, error)
+	ListByBatch(ctx context.Context, batchID int64) ([]models.Transaction, error)
+	UpdateReversal(ctx context.Context, id int64, reversedAmount decimal.Decimal, status string) error
+	// UpdateStatus saves the status of a transfer awaiting approval, and the
+	// destination amount and rate it was approved at.
+	UpdateStatus(ctx context.Context, transaction models.Transaction) error
+	// ExpirePending marks transfers awaiting approval past their expiry as
+	// expired and returns their IDs.
+	ExpirePending(ctx context.Context) ([]int64, error)
+	// SumOutbound totals the transfers the account made since since that are
+	// posted or awaiting approval.
+	SumOutbound(ctx context.Context, accountID int64, since time.Time) (OutboundTotals, error)
+	// ListOutbound returns the transfers SumOutbound would total, oldest
+	// first.
+	ListOutbound(ctx context.Context, accountID int64, since time.Time) ([]models.Transaction, error)
+}
+
+// OutboundTotals sums an account's outbound transfers over a window.
+type OutboundTotals struct {
+	Amount decimal.Decimal
+	Count  int64
+	// Earliest is when the earliest of them was made, if there were any.
+	Earliest *time.Time
+}
+
+type ApprovalStore interface {
+	CreateEvent(ctx context.Context, event models.ApprovalEvent) (models.ApprovalEvent, error)
+	// ListEvents returns a transaction's approval events, oldest first.
+	ListEvents(ctx context.Context, transactionID int64) ([]models.ApprovalEvent, error)
+}
+
+type RiskStore interface {
+	CreateDecision(ctx context.Context, decision models.RiskDecision) (models.RiskDecision, error)
+	ListDecisions(ctx context.Context, q RiskDecisionQuery) ([]models.RiskDecision, error)
+}
+
+type BatchStore interface {
+	Create(ctx context.Context, batch models.TransactionBatch) (models.TransactionBatch, error)
+	GetByID(ctx context.Context, id int64) (models.TransactionBatch, error)
+}
+
+type JournalStore interface {
+	CreateEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error)
+	SumPostings(ctx context.Context, accountID int64) (decimal.Decimal, error)
+	// SumPostingsUntil returns the account balance as recorded by the journal
+	// at until, inclusive.
+	SumPostingsUntil(ctx context.Context, accountID int64, until time.Time) (decimal.Decimal, error)
+	// SumPostingsPostedUntil is SumPostingsUntil with postings dated by
+	// their transaction's posted_at, as statement lines are, rather than by
+	// when they were written. Postings without a transaction, such as
+	// opening balances, keep their own date.
+	SumPostingsPostedUntil(ctx context.Context, accountID int64, until time.Time) (decimal.Decimal, error)
+	// ListEntries returns the entries posted to the account after after and
+	// up to until, in posting order. Each entry carries only the account's
+	// own postings.
+	ListEntries(ctx context.Context, accountID int64, after, until time.Time) ([]models.JournalEntry, error)
+}
+
+type FXQuoteStore interface {
+	Create(ctx context.Context, quote models.FXQuote) (models.FXQuote, error)
+	GetByID(ctx context.Context, id string) (models.FXQuote, error)
+	GetByIDForUpdate(ctx context.Context, id string) (models.FXQuote, error)
+	MarkUsed(ctx context.Context, id string, transactionID int64) error
+}
+
+type HoldStore interface {
+	Create(ctx context.Context, hold models.Hold) (models.Hold, error)
+	GetByID(ctx context.Context, id int64) (models.Hold, error)
+	GetByIDForUpdate(ctx context.Context, id int64) (models.Hold, error)
+	// Update saves the hold's status, captured amount and transaction.
+	Update(ctx context.Context, hold models.Hold) error
+	// SumActive returns the total amount reserved on the account by holds that
+	// are active and have not yet expired.
+	SumActive(ctx context.Context, accountID int64) (decimal.Decimal, error)
+	// ExpireDue marks active holds past their expiry as expired and returns how
+	// many it changed.
+	ExpireDue(ctx context.Context) (int64, error)
+}
+
+type ScheduleStore interface {
+	Create(ctx context.Context, schedule models.ScheduledTransfer) (models.ScheduledTransfer, error)
+	GetByID(ctx context.Context, id int64) (models.ScheduledTransfer, error)
+	GetByIDForUpdate(ctx context.Context, id int64) (models.ScheduledTransfer, error)
+	// Update saves the schedule's status, occurrence count and next run.
+	Update(ctx context.Context, schedule models.ScheduledTransfer) error
+	// ClaimDue locks up to limit active schedules whose next run is due,
+	// oldest first, skipping any that another unit of work has locked.
+	ClaimDue(ctx context.Context, limit int) ([]models.ScheduledTransfer, error)
+	CreateRun(ctx context.Context, run models.ScheduledTransferRun) (models.ScheduledTransferRun, error)
+	ListRuns(ctx context.Context, scheduleID int64) ([]models.ScheduledTransferRun, error)
+}
+
+type APIKeyStore interface {
+	Create(ctx context.Context, key models.APIKey) (models.APIKey, error)
+	GetByID(ctx context.Context, id int64) (models.APIKey, error)
+	GetByIDForUpdate(ctx context.Context, id int64) (models.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (models.APIKey, error)
+	// GetByRotatedFrom returns the key minted when the given key was
+	// rotated.
+	GetByRotatedFrom(ctx context.Context, id int64) (models.APIKey, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	// Update saves the key's expiry and revocation time.
+	Update(ctx context.Context, key models.APIKey) error
+}
+
+type IdempotencyStore interface {
+	Get(ctx context.Context, key string) (models.IdempotencyKey, error)
+	Create(ctx context.Context, record models.IdempotencyKey, ttl time.Duration) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// BulkStore writes many rows at once for imports. Unlike the other stores it
+// keeps the CreatedAt it is given, so imported history keeps its dates. It
+// must be used inside a unit of work.
+type BulkStore interface {
+	// CopyAccounts writes the accounts, balance included, or fails with
+	// ErrAccountExists if any of them already exists.
+	CopyAccounts(ctx context.Context, accounts []models.Account) error
+	// CopyTransactions assigns the transactions their IDs and writes them.
+	CopyTransactions(ctx context.Context, transactions []models.Transaction) ([]models.Transaction, error)
+	// CopyJournalEntries assigns the entries and their postings their IDs
+	// and writes them. It does not touch the cached account balances.
+	CopyJournalEntries(ctx context.Context, entries []models.JournalEntry) ([]models.JournalEntry, error)
+}
+
+// Stores groups the stores of one backend. Outside a unit of work every call
+// runs on its own; inside one, all calls share a single atomic transaction.
+type Stores struct {
+	Accounts     AccountStore
+	Transactions TransactionStore
+	Journal      JournalStore
+	Idempotency  IdempotencyStore
+	FXQuotes     FXQuoteStore
+	Holds        HoldStore
+	Batches      BatchStore
+	Schedules    ScheduleStore
+	APIKeys      APIKeyStore
+	Approvals    ApprovalStore
+	Risk         RiskStore
+	Bulk         BulkStore
+}
+
+// UnitOfWork runs fn against stores bound to a single transaction. The
+// transaction commits if fn returns nil and rolls back otherwise. Errors that
+// a retry may resolve match ErrTxConflict.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(stores Stores) error) error
+}
@@ -3,32 +3,516 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
 )
 
+var (
+	ErrTransactionNotFound = errors.New("Transaction not Found")
+	// ErrExternalReferenceExists reports that the source account already has
+	// a transaction with the same external reference.
+	ErrExternalReferenceExists = errors.New("External reference already exists")
+)
+
+const transactionColumns = `
+	id, kind, source_account_id, destination_account_id, amount, currency,
+	destination_amount, destination_currency, fx_rate, fx_quote_id,
+	reversal_of, reason_code, reversed_amount, reversal_status, batch_id,
+	description, external_reference, metadata, initiated_by, status,
+	approval_expires_at, created_at, posted_at
+`
+
 type TransactionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewTransactionRepository(db *sql.DB) *TransactionRepository {
+func NewTransactionRepository(db DBTX) *TransactionRepository {
 	return &TransactionRepository{
 		db: db,
 	}
 }
 
-func (r *TransactionRepository) Create(ctx context.Context, tx *sql.Tx, transaction models.Transaction) error {
+func (r *TransactionRepository) Create(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
 	query := `
-		INSERT INTO transactions (source_account_id, destination_account_id, amount)
-		VALUES ($1, $2, $3)
+		INSERT INTO transactions (
+			kind, source_account_id, destination_account_id, amount, currency,
+			destination_amount, destination_currency, fx_rate, fx_quote_id,
+			reversal_of, reason_code, batch_id, description, external_reference,
+			metadata, initiated_by, status, approval_expires_at, posted_at
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+			CASE WHEN $19 THEN CURRENT_TIMESTAMP END
+		)
+		RETURNING id, created_at, posted_at
 	`
-	_, err := tx.ExecContext(
+
+	var fxRate sql.NullString
+	if transaction.FXRate != nil {
+		fxRate = sql.NullString{String: transaction.FXRate.String(), Valid: true}
+	}
+
+	metadata, err := encodeMetadata(transaction.Metadata)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	transaction.ReversedAmount = decimal.Zero
+	transaction.ReversalStatus = models.ReversalStatusNone
+	if transaction.Status == "" {
+		transaction.Status = models.TransactionStatusPosted
+	}
+
+	var postedAt sql.NullTime
+	err = r.db.QueryRowContext(
 		ctx,
 		query,
+		transaction.Kind,
 		transaction.SourceAccountID,
 		transaction.DestinationAccountID,
-		transaction.Amount,
+		transaction.Amount.String(),
+		transaction.Currency,
+		transaction.DestinationAmount.String(),
+		transaction.DestinationCurrency,
+		fxRate,
+		transaction.FXQuoteID,
+		transaction.ReversalOf,
+		sql.NullString{String: transaction.ReasonCode, Valid: transaction.ReasonCode != ""},
+		transaction.BatchID,
+		sql.NullString{String: transaction.Description, Valid: transaction.Description != ""},
+		sql.NullString{String: transaction.ExternalReference, Valid: transaction.ExternalReference != ""},
+		metadata,
+		sql.NullString{String: transaction.InitiatedBy, Valid: transaction.InitiatedBy != ""},
+		transaction.Status,
+		transaction.ApprovalExpiresAt,
+		transaction.Status == models.TransactionStatusPosted,
+	).Scan(&transaction.ID, &transaction.CreatedAt, &postedAt)
+	if err != nil {
+		if hasSQLState(err, sqlStateUniqueViolation) {
+			return models.Transaction{}, ErrExternalReferenceExists
+		}
+		return models.Transaction{}, err
+	}
+
+	if postedAt.Valid {
+		transaction.PostedAt = &postedAt.Time
+	}
+
+	return transaction, nil
+}
+
+func (r *TransactionRepository) GetByID(ctx context.Context, id int64) (models.Transaction, error) {
+	return r.get(ctx, `SELECT `+transactionColumns+` FROM transactions WHERE id = $1`, id)
+}
+
+func (r *TransactionRepository) GetByIDForUpdate(ctx context.Context, id int64) (models.Transaction, error) {
+	return r.get(ctx, `SELECT `+transactionColumns+` FROM transactions WHERE id = $1 FOR UPDATE`, id)
+}
+
+func (r *TransactionRepository) get(ctx context.Context, query string, id int64) (models.Transaction, error) {
+	transaction, err := scanTransaction(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Transaction{}, ErrTransactionNotFound
+		}
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// GetByExternalReference returns the transaction the source account made
+// with the given external reference. Rejected and expired transfers no longer
+// hold their reference and are not returned.
+func (r *TransactionRepository) GetByExternalReference(ctx context.Context, sourceAccountID int64, externalReference string) (models.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE external_reference = $1 AND source_account_id = $2
+			AND status IN ('posted', 'pending_approval')
+	`
+
+	transaction, err := scanTransaction(r.db.QueryRowContext(ctx, query, externalReference, sourceAccountID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Transaction{}, ErrTransactionNotFound
+		}
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// ListByExternalReference returns the transactions of any source account
+// made with the given external reference, oldest first.
+func (r *TransactionRepository) ListByExternalReference(ctx context.Context, externalReference string) ([]models.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE external_reference = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, externalReference)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTransactions(rows)
+}
+
+// ListReversals returns the reversals of a transaction, oldest first.
+func (r *TransactionRepository) ListReversals(ctx context.Context, id int64) ([]models.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE reversal_of = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTransactions(rows)
+}
+
+// UpdateReversal records how much of a transaction has been reversed.
+func (r *TransactionRepository) UpdateReversal(ctx context.Context, id int64, reversedAmount decimal.Decimal, status string) error {
+	query := `UPDATE transactions SET reversed_amount = $1, reversal_status = $2 WHERE id = $3`
+	result, err := r.db.ExecContext(ctx, query, reversedAmount.String(), status, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTransactionNotFound
+	}
+
+	return nil
+}
+
+// UpdateStatus saves the status of a transfer awaiting approval and the
+// conversion it was approved at. Moving to posted sets posted_at.
+func (r *TransactionRepository) UpdateStatus(ctx context.Context, transaction models.Transaction) error {
+	query := `
+		UPDATE transactions
+		SET status = $1, destination_amount = $2, fx_rate = $3,
+			posted_at = CASE WHEN $4 THEN CURRENT_TIMESTAMP END
+		WHERE id = $5
+	`
+
+	var fxRate sql.NullString
+	if transaction.FXRate != nil {
+		fxRate = sql.NullString{String: transaction.FXRate.String(), Valid: true}
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		transaction.Status,
+		transaction.DestinationAmount.String(),
+		fxRate,
+		transaction.Status == models.TransactionStatusPosted,
+		transaction.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTransactionNotFound
+	}
+
+	return nil
+}
+
+// ExpirePending marks transfers whose approval window has passed as expired
+// and returns their IDs. It skips transfers another unit of work has locked,
+// such as one being approved.
+func (r *TransactionRepository) ExpirePending(ctx context.Context) ([]int64, error) {
+	query := `
+		UPDATE transactions
+		SET status = 'expired'
+		WHERE id IN (
+			SELECT id FROM transactions
+			WHERE status = 'pending_approval' AND approval_expires_at <= CURRENT_TIMESTAMP
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *TransactionRepository) SumOutbound(ctx context.Context, accountID int64, since time.Time) (OutboundTotals, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0), COUNT(*), MIN(created_at)
+		FROM transactions
+		WHERE source_account_id = $1 AND kind = 'transfer'
+			AND status IN ('posted', 'pending_approval') AND created_at >= $2
+	`
+
+	var amountStr string
+	var earliest sql.NullTime
+	var totals OutboundTotals
+
+	err := r.db.QueryRowContext(ctx, query, accountID, since).Scan(&amountStr, &totals.Count, &earliest)
+	if err != nil {
+		return OutboundTotals{}, err
+	}
+
+	totals.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return OutboundTotals{}, err
+	}
+	if earliest.Valid {
+		totals.Earliest = &earliest.Time
+	}
+
+	return totals, nil
+}
+
+func (r *TransactionRepository) ListOutbound(ctx context.Context, accountID int64, since time.Time) ([]models.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE source_account_id = $1 AND kind = 'transfer'
+			AND status IN ('posted', 'pending_approval') AND created_at >= $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTransactions(rows)
+}
+
+// ListByBatch returns the legs of a batch in the order they were posted.
+func (r *TransactionRepository) ListByBatch(ctx context.Context, batchID int64) ([]models.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE batch_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTransactions(rows)
+}
+
+// ListByAccount returns the transactions in which the account was either the
+// source or the destination, newest first.
+func (r *TransactionRepository) ListByAccount(ctx context.Context, accountID int64, limit, offset int) ([]models.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE source_account_id = $1 OR destination_account_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTransactions(rows)
+}
+
+// EachByAccount calls fn for every transaction in which the account was the
+// source or the destination, posted after after and up to until, in posting
+// order. Rows are read from the database as fn consumes them, so memory use
+// does not grow with the number of transactions.
+func (r *TransactionRepository) EachByAccount(ctx context.Context, accountID int64, after, until time.Time, fn func(models.Transaction) error) error {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE (source_account_id = $1 OR destination_account_id = $1)
+			AND status = 'posted' AND posted_at > $2 AND posted_at <= $3
+		ORDER BY posted_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID, after, until)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func scanTransactions(rows *sql.Rows) ([]models.Transaction, error) {
+	defer rows.Close()
+
+	transactions := []models.Transaction{}
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTransaction(row rowScanner) (models.Transaction, error) {
+	var transaction models.Transaction
+	var amountStr, destinationAmountStr, reversedAmountStr string
+	var fxRate, fxQuoteID, reasonCode, description, externalReference, initiatedBy sql.NullString
+	var reversalOf, batchID sql.NullInt64
+	var approvalExpiresAt, postedAt sql.NullTime
+	var metadata []byte
+
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.Kind,
+		&transaction.SourceAccountID,
+		&transaction.DestinationAccountID,
+		&amountStr,
+		&transaction.Currency,
+		&destinationAmountStr,
+		&transaction.DestinationCurrency,
+		&fxRate,
+		&fxQuoteID,
+		&reversalOf,
+		&reasonCode,
+		&reversedAmountStr,
+		&transaction.ReversalStatus,
+		&batchID,
+		&description,
+		&externalReference,
+		&metadata,
+		&initiatedBy,
+		&transaction.Status,
+		&approvalExpiresAt,
+		&transaction.CreatedAt,
+		&postedAt,
 	)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	transaction.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	transaction.DestinationAmount, err = decimal.NewFromString(destinationAmountStr)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	if fxRate.Valid {
+		rate, err := decimal.NewFromString(fxRate.String)
+		if err != nil {
+			return models.Transaction{}, err
+		}
+		transaction.FXRate = &rate
+	}
+
+	if fxQuoteID.Valid {
+		transaction.FXQuoteID = &fxQuoteID.String
+	}
+
+	if reversalOf.Valid {
+		transaction.ReversalOf = &reversalOf.Int64
+	}
+
+	if batchID.Valid {
+		transaction.BatchID = &batchID.Int64
+	}
+
+	if approvalExpiresAt.Valid {
+		transaction.ApprovalExpiresAt = &approvalExpiresAt.Time
+	}
+
+	if postedAt.Valid {
+		transaction.PostedAt = &postedAt.Time
+	}
+
+	transaction.ReasonCode = reasonCode.String
+	transaction.Description = description.String
+	transaction.ExternalReference = externalReference.String
+	transaction.InitiatedBy = initiatedBy.String
+
+	if metadata != nil {
+		if err := json.Unmarshal(metadata, &transaction.Metadata); err != nil {
+			return models.Transaction{}, err
+		}
+	}
+
+	transaction.ReversedAmount, err = decimal.NewFromString(reversedAmountStr)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// encodeMetadata returns the JSON to store for a transaction's metadata. It is
+// passed as text, since lib/pq would send a []byte in binary form.
+func encodeMetadata(metadata map[string]any) (sql.NullString, error) {
+	if len(metadata) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	body, err := json.Marshal(metadata)
+	if err != nil {
+		return sql.NullString{}, err
+	}
 
-	return err
+	return sql.NullString{String: string(body), Valid: true}, nil
 }
@@ -0,0 +1,268 @@
+// Package risk screens transfers against rules read from a YAML or JSON file.
+//
+// Each rule that fires votes review or deny and adds its score. The decision
+// is the most severe vote, raised further when the total score reaches the
+// file's review_score or deny_score. The file is read again whenever it
+// changes, so rules can be tuned without a restart.
+package risk
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultReloadInterval = 10 * time.Second
+
+var ErrInvalidRules = errors.New("invalid risk rules")
+
+// Transfer is what rules see of a transfer about to be made.
+type Transfer struct {
+	Source               models.Account
+	DestinationAccountID int64
+	Amount               decimal.Decimal
+	At                   time.Time
+}
+
+// file is the layout of the rules file.
+type file struct {
+	// ReviewScore and DenyScore, when above zero, turn a transfer whose
+	// rules score at least that much to review or deny.
+	ReviewScore int        `yaml:"review_score"`
+	DenyScore   int        `yaml:"deny_score"`
+	Rules       []RuleSpec `yaml:"rules"`
+}
+
+// Rules is one loaded version of the rules file. It does not change once
+// loaded; a reload replaces it.
+type Rules struct {
+	version     string
+	loadedAt    time.Time
+	reviewScore int
+	denyScore   int
+	rules       []rule
+	lookback    time.Duration
+}
+
+type rule struct {
+	spec RuleSpec
+	Rule
+}
+
+// Parse reads a rules file. JSON is read as the YAML it is a subset of.
+func Parse(data []byte) (*Rules, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var f file
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+
+	if f.ReviewScore < 0 || f.DenyScore < 0 {
+		return nil, fmt.Errorf("%w: scores must not be negative", ErrInvalidRules)
+	}
+
+	sum := sha256.Sum256(data)
+	rules := &Rules{
+		version:     hex.EncodeToString(sum[:6]),
+		loadedAt:    time.Now().UTC(),
+		reviewScore: f.ReviewScore,
+		denyScore:   f.DenyScore,
+	}
+
+	names := make(map[string]bool, len(f.Rules))
+	for _, spec := range f.Rules {
+		if spec.Name == "" || names[spec.Name] {
+			return nil, fmt.Errorf("%w: rule names must be set and unique, got %q", ErrInvalidRules, spec.Name)
+		}
+		names[spec.Name] = true
+
+		r, err := spec.build()
+		if err != nil {
+			return nil, fmt.Errorf("%w: rule %s: %v", ErrInvalidRules, spec.Name, err)
+		}
+
+		rules.rules = append(rules.rules, rule{spec: spec, Rule: r})
+		rules.lookback = max(rules.lookback, r.Lookback())
+	}
+
+	return rules, nil
+}
+
+// Version identifies the content of the rules file the rules came from.
+func (r *Rules) Version() string {
+	return r.version
+}
+
+// Lookback is how far back the account history passed to Evaluate must go.
+func (r *Rules) Lookback() time.Duration {
+	return r.lookback
+}
+
+// Evaluate runs every rule against the transfer. history holds the source
+// account's transfers made within Lookback of t.At that are posted or
+// awaiting approval, oldest first.
+func (r *Rules) Evaluate(t Transfer, history []models.Transaction) models.RiskDecision {
+	decision := models.RiskDecision{
+		SourceAccountID:      t.Source.AccountID,
+		DestinationAccountID: t.DestinationAccountID,
+		Amount:               t.Amount,
+		Currency:             t.Source.Currency,
+		Action:               models.RiskActionAllow,
+		Hits:                 []models.RiskRuleHit{},
+		RulesVersion:         r.version,
+	}
+
+	for _, rule := range r.rules {
+		reason, hit := rule.Evaluate(t, history)
+		if !hit {
+			continue
+		}
+
+		decision.Hits = append(decision.Hits, models.RiskRuleHit{
+			Rule:   rule.spec.Name,
+			Action: rule.spec.Action,
+			Score:  rule.spec.Score,
+			Reason: reason,
+		})
+		decision.Score += rule.spec.Score
+		decision.Action = moreSevere(decision.Action, rule.spec.Action)
+	}
+
+	if r.reviewScore > 0 && decision.Score >= r.reviewScore {
+		decision.Action = moreSevere(decision.Action, models.RiskActionReview)
+	}
+	if r.denyScore > 0 && decision.Score >= r.denyScore {
+		decision.Action = models.RiskActionDeny
+	}
+
+	return decision
+}
+
+// Summary describes the rules, for operators checking what is loaded.
+func (r *Rules) Summary() models.RiskRules {
+	summary := models.RiskRules{
+		Version:     r.version,
+		LoadedAt:    r.loadedAt,
+		ReviewScore: r.reviewScore,
+		DenyScore:   r.denyScore,
+		Rules:       make([]models.RiskRuleSummary, 0, len(r.rules)),
+	}
+
+	for _, rule := range r.rules {
+		summary.Rules = append(summary.Rules, models.RiskRuleSummary{
+			Name:   rule.spec.Name,
+			Kind:   rule.spec.Kind,
+			Action: rule.spec.Action,
+			Score:  rule.spec.Score,
+		})
+	}
+
+	return summary
+}
+
+var severity = map[string]int{
+	models.RiskActionAllow:  0,
+	models.RiskActionReview: 1,
+	models.RiskActionDeny:   2,
+}
+
+func moreSevere(a, b string) string {
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+// Engine holds the rules loaded from a file and swaps in a new version when
+// the file changes. A file that fails to load leaves the rules in use as they
+// were.
+type Engine struct {
+	path  string
+	rules atomic.Pointer[Rules]
+
+	// modTime and size of the file last read, which only Watch touches.
+	modTime time.Time
+	size    int64
+}
+
+// NewFileEngine loads the rules in path.
+func NewFileEngine(path string) (*Engine, error) {
+	e := &Engine{path: path}
+	if err := e.load(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Rules returns the version of the rules in use. A transfer should be
+// screened against a single version from start to end.
+func (e *Engine) Rules() *Rules {
+	return e.rules.Load()
+}
+
+func (e *Engine) load() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return err
+	}
+
+	// The file is not read again until it changes, even if this version
+	// turns out to be invalid.
+	e.modTime, e.size = info.ModTime(), info.Size()
+
+	rules, err := Parse(data)
+	if err != nil {
+		return err
+	}
+
+	e.rules.Store(rules)
+	return nil
+}
+
+// Watch checks the file for changes every interval until ctx is done.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(e.path)
+		if err != nil {
+			log.Printf("Failed to check risk rules: %v", err)
+			continue
+		}
+		if info.ModTime().Equal(e.modTime) && info.Size() == e.size {
+			continue
+		}
+
+		if err := e.load(); err != nil {
+			log.Printf("Failed to reload risk rules, keeping version %s: %v", e.Rules().Version(), err)
+			continue
+		}
+
+		log.Printf("Reloaded risk rules, now version %s with %d rules", e.Rules().Version(), len(e.Rules().rules))
+	}
+}
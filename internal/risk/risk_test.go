@@ -0,0 +1,276 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{name: "unknown field", rules: "rules:\n  - name: a\n    kind: destination_blocklist\n    action: deny\n    accounts: [1]\n    acounts: [2]\n", wantErr: "acounts"},
+		{name: "negative score threshold", rules: "review_score: -1\n", wantErr: "scores must not be negative"},
+		{name: "missing name", rules: "rules:\n  - kind: destination_blocklist\n    action: deny\n    accounts: [1]\n", wantErr: "names must be set and unique"},
+		{name: "duplicate name", rules: "rules:\n  - {name: a, kind: destination_blocklist, action: deny, accounts: [1]}\n  - {name: a, kind: destination_blocklist, action: deny, accounts: [2]}\n", wantErr: "names must be set and unique"},
+		{name: "unknown kind", rules: "rules:\n  - {name: a, kind: velocity, action: deny}\n", wantErr: `unknown kind "velocity"`},
+		{name: "allow action", rules: "rules:\n  - {name: a, kind: destination_blocklist, action: allow, accounts: [1]}\n", wantErr: "action must be review or deny"},
+		{name: "negative score", rules: "rules:\n  - {name: a, kind: destination_blocklist, action: deny, score: -5, accounts: [1]}\n", wantErr: "score must not be negative"},
+		{name: "new account outflow without an age", rules: "rules:\n  - {name: a, kind: new_account_outflow, action: review, min_amount: 100}\n", wantErr: "max_account_age and min_amount"},
+		{name: "round amount burst without a window", rules: "rules:\n  - {name: a, kind: round_amount_burst, action: review, multiple: 100, count: 3}\n", wantErr: "multiple, count and window"},
+		{name: "distinct destinations without a count", rules: "rules:\n  - {name: a, kind: distinct_destinations, action: review, window: 1h}\n", wantErr: "count and window"},
+		{name: "empty blocklist", rules: "rules:\n  - {name: a, kind: destination_blocklist, action: deny}\n", wantErr: "accounts must not be empty"},
+		{name: "bad duration", rules: "rules:\n  - {name: a, kind: distinct_destinations, action: review, count: 2, window: soon}\n", wantErr: "soon"},
+		{name: "bad decimal", rules: "rules:\n  - {name: a, kind: new_account_outflow, action: review, max_account_age: 24h, min_amount: lots}\n", wantErr: "lots"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.rules))
+			if !errors.Is(err, ErrInvalidRules) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want ErrInvalidRules mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDecodesDurationsAndDecimals(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{name: "YAML", rules: `
+review_score: 10
+deny_score: 20
+rules:
+  - name: new
+    kind: new_account_outflow
+    action: review
+    score: 5
+    max_account_age: 36h
+    min_amount: "250.50"
+  - name: round
+    kind: round_amount_burst
+    action: review
+    multiple: 100
+    count: 3
+    window: 90m
+`},
+		{name: "JSON", rules: `{"review_score": 10, "deny_score": 20, "rules": [
+  {"name": "new", "kind": "new_account_outflow", "action": "review", "score": 5, "max_account_age": "36h", "min_amount": 250.50},
+  {"name": "round", "kind": "round_amount_burst", "action": "review", "multiple": "100", "count": 3, "window": "90m"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := Parse([]byte(tt.rules))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			outflow := rules.rules[0].Rule.(newAccountOutflow)
+			if outflow.maxAge != 36*time.Hour || !outflow.minAmount.Equal(decimal.RequireFromString("250.5")) {
+				t.Errorf("new_account_outflow = %+v, want 36h and 250.50", outflow)
+			}
+
+			burst := rules.rules[1].Rule.(roundAmountBurst)
+			if burst.window != 90*time.Minute || !burst.multiple.Equal(decimal.NewFromInt(100)) {
+				t.Errorf("round_amount_burst = %+v, want 100 within 90m", burst)
+			}
+
+			if rules.Lookback() != 90*time.Minute {
+				t.Errorf("Lookback = %s, want the longest window, 90m", rules.Lookback())
+			}
+			if rules.reviewScore != 10 || rules.denyScore != 20 {
+				t.Errorf("scores = %d/%d, want 10/20", rules.reviewScore, rules.denyScore)
+			}
+		})
+	}
+}
+
+func sent(destination int64, amount string, ago time.Duration, now time.Time) models.Transaction {
+	return models.Transaction{
+		DestinationAccountID: destination,
+		Amount:               decimal.RequireFromString(amount),
+		CreatedAt:            now.Add(-ago),
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now().UTC()
+	newAccount := models.Account{AccountID: 1, Currency: "USD", CreatedAt: now.Add(-time.Hour)}
+	oldAccount := models.Account{AccountID: 1, Currency: "USD", CreatedAt: now.Add(-30 * 24 * time.Hour)}
+
+	transfer := func(source models.Account, destination int64, amount string) Transfer {
+		return Transfer{Source: source, DestinationAccountID: destination, Amount: decimal.RequireFromString(amount), At: now}
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		t       Transfer
+		history []models.Transaction
+		want    bool
+	}{
+		{name: "new account sends enough", rule: "{kind: new_account_outflow, max_account_age: 24h, min_amount: 500}", t: transfer(newAccount, 2, "500"), want: true},
+		{name: "new account sends little", rule: "{kind: new_account_outflow, max_account_age: 24h, min_amount: 500}", t: transfer(newAccount, 2, "499.99")},
+		{name: "old account sends enough", rule: "{kind: new_account_outflow, max_account_age: 24h, min_amount: 500}", t: transfer(oldAccount, 2, "5000")},
+
+		{name: "third round amount in the window", rule: "{kind: round_amount_burst, multiple: 100, count: 3, window: 1h}", t: transfer(oldAccount, 2, "300"),
+			history: []models.Transaction{sent(2, "100", 10*time.Minute, now), sent(3, "200", 20*time.Minute, now)}, want: true},
+		{name: "round amounts outside the window", rule: "{kind: round_amount_burst, multiple: 100, count: 3, window: 1h}", t: transfer(oldAccount, 2, "300"),
+			history: []models.Transaction{sent(2, "100", 2*time.Hour, now), sent(3, "200", 20*time.Minute, now)}},
+		{name: "uneven amounts in the window", rule: "{kind: round_amount_burst, multiple: 100, count: 3, window: 1h}", t: transfer(oldAccount, 2, "300"),
+			history: []models.Transaction{sent(2, "150", 10*time.Minute, now), sent(3, "200", 20*time.Minute, now)}},
+		{name: "uneven transfer after a burst", rule: "{kind: round_amount_burst, multiple: 100, count: 3, window: 1h}", t: transfer(oldAccount, 2, "301"),
+			history: []models.Transaction{sent(2, "100", 10*time.Minute, now), sent(3, "200", 20*time.Minute, now)}},
+
+		{name: "new destination makes the count", rule: "{kind: distinct_destinations, count: 3, window: 1h}", t: transfer(oldAccount, 4, "10"),
+			history: []models.Transaction{sent(2, "10", 10*time.Minute, now), sent(3, "10", 20*time.Minute, now)}, want: true},
+		{name: "repeat destinations", rule: "{kind: distinct_destinations, count: 3, window: 1h}", t: transfer(oldAccount, 2, "10"),
+			history: []models.Transaction{sent(2, "10", 10*time.Minute, now), sent(3, "10", 20*time.Minute, now)}},
+		{name: "destinations outside the window", rule: "{kind: distinct_destinations, count: 3, window: 1h}", t: transfer(oldAccount, 4, "10"),
+			history: []models.Transaction{sent(2, "10", 2*time.Hour, now), sent(3, "10", 20*time.Minute, now)}},
+
+		{name: "blocklisted destination", rule: "{kind: destination_blocklist, accounts: [7, 9]}", t: transfer(oldAccount, 9, "1"), want: true},
+		{name: "other destination", rule: "{kind: destination_blocklist, accounts: [7, 9]}", t: transfer(oldAccount, 8, "1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := Parse([]byte("rules:\n  - " + strings.Replace(tt.rule, "{", "{name: r, action: deny, score: 1, ", 1) + "\n"))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			decision := rules.Evaluate(tt.t, tt.history)
+
+			want := models.RiskActionAllow
+			if tt.want {
+				want = models.RiskActionDeny
+			}
+			if decision.Action != want {
+				t.Errorf("action = %s, want %s (hits %+v)", decision.Action, want, decision.Hits)
+			}
+			if hit := len(decision.Hits) == 1; hit != tt.want {
+				t.Errorf("hits = %+v, want hit %v", decision.Hits, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateCombinesVotesAndScores(t *testing.T) {
+	rules, err := Parse([]byte(`
+review_score: 5
+deny_score: 8
+rules:
+  - {name: blocklist, kind: destination_blocklist, action: review, score: 3, accounts: [9]}
+  - {name: big, kind: new_account_outflow, action: review, score: 3, max_account_age: 24h, min_amount: 100}
+  - {name: huge, kind: new_account_outflow, action: review, score: 3, max_account_age: 24h, min_amount: 1000}
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	now := time.Now().UTC()
+	source := models.Account{AccountID: 1, Currency: "USD", CreatedAt: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name        string
+		destination int64
+		amount      string
+		wantAction  string
+		wantScore   int
+	}{
+		{name: "nothing fires", destination: 2, amount: "10", wantAction: models.RiskActionAllow},
+		{name: "one vote", destination: 9, amount: "10", wantAction: models.RiskActionReview, wantScore: 3},
+		{name: "review score reached", destination: 2, amount: "1000", wantAction: models.RiskActionReview, wantScore: 6},
+		{name: "deny score reached", destination: 9, amount: "1000", wantAction: models.RiskActionDeny, wantScore: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := rules.Evaluate(Transfer{Source: source, DestinationAccountID: tt.destination, Amount: decimal.RequireFromString(tt.amount), At: now}, nil)
+			if decision.Action != tt.wantAction || decision.Score != tt.wantScore {
+				t.Errorf("decision = %s scoring %d, want %s scoring %d", decision.Action, decision.Score, tt.wantAction, tt.wantScore)
+			}
+			if decision.RulesVersion != rules.Version() {
+				t.Errorf("rules version = %q, want %q", decision.RulesVersion, rules.Version())
+			}
+		})
+	}
+}
+
+func TestEngineKeepsRulesWhenReloadFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	write := func(rules string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("rules:\n  - {name: a, kind: destination_blocklist, action: deny, accounts: [1]}\n")
+	engine, err := NewFileEngine(path)
+	if err != nil {
+		t.Fatalf("NewFileEngine: %v", err)
+	}
+	first := engine.Rules()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Watch(ctx, 5*time.Millisecond)
+
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{name: "invalid YAML", rules: "rules: [\n"},
+		{name: "invalid rule", rules: "rules:\n  - {name: a, kind: destination_blocklist, action: block, accounts: [1]}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			write(tt.rules)
+			time.Sleep(50 * time.Millisecond)
+
+			if got := engine.Rules(); got != first {
+				t.Errorf("rules version %s in use, want %s", got.Version(), first.Version())
+			}
+		})
+	}
+
+	// A valid file is picked up again after the failed ones.
+	write("rules:\n  - {name: a, kind: destination_blocklist, action: deny, accounts: [1, 2]}\n")
+	deadline := time.Now().Add(2 * time.Second)
+	for engine.Rules() == first {
+		if time.Now().After(deadline) {
+			t.Fatal("valid rules were not reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := len(engine.Rules().rules); n != 1 {
+		t.Errorf("reloaded %d rules, want 1", n)
+	}
+}
+
+func TestNewFileEngineRejectsInvalidRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte("rules:\n  - {name: a, kind: nope, action: deny}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFileEngine(path); !errors.Is(err, ErrInvalidRules) {
+		t.Fatalf("err = %v, want ErrInvalidRules", err)
+	}
+}
@@ -0,0 +1,178 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// Rule kinds.
+const (
+	// KindNewAccountOutflow fires on a transfer of at least MinAmount from an
+	// account younger than MaxAccountAge.
+	KindNewAccountOutflow = "new_account_outflow"
+	// KindRoundAmountBurst fires on the Count-th transfer within Window whose
+	// amount is a whole multiple of Multiple.
+	KindRoundAmountBurst = "round_amount_burst"
+	// KindDistinctDestinations fires when a transfer makes Count or more
+	// distinct destinations within Window.
+	KindDistinctDestinations = "distinct_destinations"
+	// KindDestinationBlocklist fires on a transfer to any of Accounts.
+	KindDestinationBlocklist = "destination_blocklist"
+)
+
+// Rule is one check a transfer is screened against.
+type Rule interface {
+	// Evaluate reports whether the rule fires on the transfer, and why.
+	Evaluate(t Transfer, history []models.Transaction) (reason string, hit bool)
+	// Lookback is how much account history the rule looks at.
+	Lookback() time.Duration
+}
+
+// RuleSpec is a rule as written in the rules file. Which of the parameters
+// apply depends on Kind.
+type RuleSpec struct {
+	Name string `yaml:"name"`
+	Kind string `yaml:"kind"`
+	// Action is review or deny.
+	Action string `yaml:"action"`
+	Score  int    `yaml:"score"`
+
+	MaxAccountAge time.Duration   `yaml:"max_account_age"`
+	MinAmount     decimal.Decimal `yaml:"min_amount"`
+	Multiple      decimal.Decimal `yaml:"multiple"`
+	Count         int             `yaml:"count"`
+	Window        time.Duration   `yaml:"window"`
+	Accounts      []int64         `yaml:"accounts"`
+}
+
+func (s RuleSpec) build() (Rule, error) {
+	if s.Action != models.RiskActionReview && s.Action != models.RiskActionDeny {
+		return nil, errors.New("action must be review or deny")
+	}
+	if s.Score < 0 {
+		return nil, errors.New("score must not be negative")
+	}
+
+	switch s.Kind {
+	case KindNewAccountOutflow:
+		if s.MaxAccountAge <= 0 || !s.MinAmount.IsPositive() {
+			return nil, errors.New("max_account_age and min_amount must be positive")
+		}
+		return newAccountOutflow{maxAge: s.MaxAccountAge, minAmount: s.MinAmount}, nil
+
+	case KindRoundAmountBurst:
+		if !s.Multiple.IsPositive() || s.Count < 1 || s.Window <= 0 {
+			return nil, errors.New("multiple, count and window must be positive")
+		}
+		return roundAmountBurst{multiple: s.Multiple, count: s.Count, window: s.Window}, nil
+
+	case KindDistinctDestinations:
+		if s.Count < 1 || s.Window <= 0 {
+			return nil, errors.New("count and window must be positive")
+		}
+		return distinctDestinations{count: s.Count, window: s.Window}, nil
+
+	case KindDestinationBlocklist:
+		if len(s.Accounts) == 0 {
+			return nil, errors.New("accounts must not be empty")
+		}
+		return destinationBlocklist{accounts: slices.Clone(s.Accounts)}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown kind %q", s.Kind)
+	}
+}
+
+type newAccountOutflow struct {
+	maxAge    time.Duration
+	minAmount decimal.Decimal
+}
+
+func (r newAccountOutflow) Evaluate(t Transfer, _ []models.Transaction) (string, bool) {
+	age := t.At.Sub(t.Source.CreatedAt)
+	if age >= r.maxAge || t.Amount.LessThan(r.minAmount) {
+		return "", false
+	}
+	return fmt.Sprintf("account is %s old and sends %s", age.Round(time.Second), t.Amount), true
+}
+
+func (r newAccountOutflow) Lookback() time.Duration {
+	return 0
+}
+
+type roundAmountBurst struct {
+	multiple decimal.Decimal
+	count    int
+	window   time.Duration
+}
+
+func (r roundAmountBurst) round(amount decimal.Decimal) bool {
+	return amount.Mod(r.multiple).IsZero()
+}
+
+func (r roundAmountBurst) Evaluate(t Transfer, history []models.Transaction) (string, bool) {
+	if !r.round(t.Amount) {
+		return "", false
+	}
+
+	since := t.At.Add(-r.window)
+	count := 1
+	for _, transaction := range history {
+		if !transaction.CreatedAt.Before(since) && r.round(transaction.Amount) {
+			count++
+		}
+	}
+
+	if count < r.count {
+		return "", false
+	}
+	return fmt.Sprintf("%d transfers in multiples of %s within %s", count, r.multiple, r.window), true
+}
+
+func (r roundAmountBurst) Lookback() time.Duration {
+	return r.window
+}
+
+type distinctDestinations struct {
+	count  int
+	window time.Duration
+}
+
+func (r distinctDestinations) Evaluate(t Transfer, history []models.Transaction) (string, bool) {
+	since := t.At.Add(-r.window)
+	destinations := map[int64]bool{t.DestinationAccountID: true}
+	for _, transaction := range history {
+		if !transaction.CreatedAt.Before(since) {
+			destinations[transaction.DestinationAccountID] = true
+		}
+	}
+
+	if len(destinations) < r.count {
+		return "", false
+	}
+	return fmt.Sprintf("%d distinct destinations within %s", len(destinations), r.window), true
+}
+
+func (r distinctDestinations) Lookback() time.Duration {
+	return r.window
+}
+
+type destinationBlocklist struct {
+	accounts []int64
+}
+
+func (r destinationBlocklist) Evaluate(t Transfer, _ []models.Transaction) (string, bool) {
+	if !slices.Contains(r.accounts, t.DestinationAccountID) {
+		return "", false
+	}
+	return fmt.Sprintf("destination account %d is blocklisted", t.DestinationAccountID), true
+}
+
+func (r destinationBlocklist) Lookback() time.Duration {
+	return 0
+}
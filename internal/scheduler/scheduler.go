@@ -0,0 +1,170 @@
+// Package scheduler runs due scheduled transfers in the background.
+//
+// Every replica of the server may run a scheduler. Due schedules are claimed
+// with row locks that other replicas skip, and each run goes through
+// TransactionService.RunScheduledTransfer under an idempotency key derived
+// from the schedule and occurrence, so a run that is retried after a crash
+// cannot move money twice.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/auth"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	service "github.com/KaranPal130/transfers-system/internal/services"
+)
+
+const (
+	DefaultInterval = 10 * time.Second
+
+	// claimSize is how many schedules one unit of work claims. They stay
+	// locked until all of them have run.
+	claimSize = 10
+
+	// retryDelay is how long a run that failed for a reason other than the
+	// transfer being rejected waits before it is tried again.
+	retryDelay = time.Minute
+)
+
+type Scheduler struct {
+	uow                repository.UnitOfWork
+	transactionService *service.TransactionService
+	apiKeyService      *service.APIKeyService
+	interval           time.Duration
+}
+
+func New(uow repository.UnitOfWork, transactionService *service.TransactionService, apiKeyService *service.APIKeyService, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		uow:                uow,
+		transactionService: transactionService,
+		apiKeyService:      apiKeyService,
+		interval:           interval,
+	}
+}
+
+// Run polls for due schedules every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		executed, err := s.RunDue(ctx)
+		if err != nil {
+			log.Printf("Failed to run scheduled transfers: %v", err)
+		}
+		if executed > 0 {
+			log.Printf("Ran %d scheduled transfers", executed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs every schedule that is due and returns how many runs it
+// attempted.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		var claimed int
+
+		err := s.uow.Do(ctx, func(stores repository.Stores) error {
+			due, err := stores.Schedules.ClaimDue(ctx, claimSize)
+			if err != nil {
+				return err
+			}
+
+			claimed = len(due)
+			for _, schedule := range due {
+				if err := s.runOne(ctx, stores, schedule); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += claimed
+		if claimed < claimSize {
+			return total, nil
+		}
+	}
+}
+
+// runOne executes the schedule's current occurrence and records the attempt.
+// A rejected transfer, such as one with insufficient funds, moves the
+// schedule on to its next occurrence, and one whose creator's API key was
+// revoked pauses the schedule; any other failure retries the same occurrence
+// after retryDelay.
+func (s *Scheduler) runOne(ctx context.Context, stores repository.Stores, schedule models.ScheduledTransfer) error {
+	req := models.TransactionRequest{
+		SourceAccountID:      schedule.SourceAccountID,
+		DestinationAccountID: schedule.DestinationAccountID,
+		Amount:               schedule.Amount.String(),
+		FXMode:               schedule.FXMode,
+	}
+
+	run := models.ScheduledTransferRun{
+		ScheduledTransferID: schedule.ID,
+		Occurrence:          schedule.Occurrences,
+		ScheduledFor:        service.ScheduledFor(schedule),
+	}
+
+	// The run is made on behalf of the schedule's creator, whose right to
+	// debit the source is checked again each time.
+	var transaction models.Transaction
+	principal, err := s.apiKeyService.CurrentPrincipal(ctx, schedule.InitiatedBy)
+	if err == nil {
+		runCtx := ctx
+		if schedule.InitiatedBy != "" {
+			runCtx = auth.NewContext(ctx, principal)
+		}
+		transaction, err = s.transactionService.RunScheduledTransfer(runCtx, req, schedule.ID, schedule.Occurrences)
+	}
+
+	switch {
+	case errors.Is(err, service.ErrInitiatorInactive):
+		// Nobody may debit the source on the schedule's behalf any more. A
+		// resumed schedule is paused again on its next run.
+		log.Printf("Pausing scheduled transfer %d: %v", schedule.ID, err)
+		run.Status = models.ScheduleRunFailed
+		run.Error = err.Error()
+		schedule.Status = models.ScheduleStatusPaused
+	case err == nil:
+		run.Status = models.ScheduleRunSucceeded
+		run.TransactionID = &transaction.ID
+		schedule = service.AdvanceSchedule(schedule)
+	// A key already used with another request would fail the same way on
+	// every retry.
+	case service.IsTransferRejection(err), errors.Is(err, service.ErrIdempotencyKeyMismatch):
+		run.Status = models.ScheduleRunFailed
+		run.Error = err.Error()
+		schedule = service.AdvanceSchedule(schedule)
+	default:
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		log.Printf("Scheduled transfer %d failed, retrying in %s: %v", schedule.ID, retryDelay, err)
+		run.Status = models.ScheduleRunFailed
+		run.Error = err.Error()
+		retryAt := time.Now().Add(retryDelay)
+		schedule.NextRunAt = &retryAt
+	}
+
+	if _, err := stores.Schedules.CreateRun(ctx, run); err != nil {
+		return err
+	}
+
+	return stores.Schedules.Update(ctx, schedule)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/KaranPal130/transfers-system/internal/currency"
 	"github.com/KaranPal130/transfers-system/internal/models"
 	repository "github.com/KaranPal130/transfers-system/internal/repositories"
 	"github.com/shopspring/decimal"
@@ -12,43 +13,272 @@ import (
 var (
 	ErrInvalidInitialBalance = errors.New("invalid initial balance")
 	ErrAccountAlreadyExists  = errors.New("account already exists")
+	ErrInvalidAccountID      = errors.New("invalid account id")
+	ErrUnsupportedCurrency   = errors.New("unsupported currency")
+	ErrAmountPrecision       = errors.New("amount has more decimal places than the currency allows")
+	ErrInvalidOverdraftLimit = errors.New("invalid overdraft limit")
 )
 
+const DefaultCurrency = "USD"
+
 type AccountService struct {
-	accountRepo *repository.AccountRepository
+	uow                repository.UnitOfWork
+	accountStore       repository.AccountStore
+	holdStore          repository.HoldStore
+	transactionService *TransactionService
 }
 
-func NewAccountService(accountRepo *repository.AccountRepository) *AccountService {
+// NewAccountService returns a service that sweeps the balance of accounts
+// being closed through transactionService.
+func NewAccountService(uow repository.UnitOfWork, accountStore repository.AccountStore, holdStore repository.HoldStore, transactionService *TransactionService) *AccountService {
 	return &AccountService{
-		accountRepo: accountRepo,
+		uow:                uow,
+		accountStore:       accountStore,
+		holdStore:          holdStore,
+		transactionService: transactionService,
 	}
 }
 
 func (s *AccountService) CreateAccount(ctx context.Context, req models.AccountCreateRequest) error {
-	initialBalance, err := decimal.NewFromString(req.InitialBalance)
+	account, initialBalance, err := newAccount(req, s.transactionService.limits)
 	if err != nil {
-		return ErrInvalidInitialBalance
-	}
-
-	if initialBalance.LessThan(decimal.Zero) {
-		return ErrInvalidInitialBalance
+		return err
 	}
 
-	_, err = s.accountRepo.GetByID(ctx, req.AccountID)
+	_, err = s.accountStore.GetByID(ctx, req.AccountID)
 	if err == nil {
 		return ErrAccountAlreadyExists
 	} else if !errors.Is(err, repository.ErrAccountNotFound) {
 		return err
 	}
 
+	err = runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		if err := stores.Accounts.Create(ctx, account); err != nil {
+			return err
+		}
+
+		if initialBalance.IsZero() {
+			return nil
+		}
+
+		// The opening balance is funded from the system account so that the
+		// journal stays balanced and the cached balance is derived from it.
+		accounts := map[int64]models.Account{account.AccountID: account}
+		_, err := postJournalEntry(ctx, stores, accounts, models.JournalEntry{
+			Kind:     models.JournalEntryOpeningBalance,
+			Postings: openingPostings(account.AccountID, initialBalance, account.Currency),
+		})
+		return err
+	})
+	if errors.Is(err, repository.ErrAccountExists) {
+		return ErrAccountAlreadyExists
+	}
+
+	return err
+}
+
+// newAccount validates req and returns the account to create, with a zero
+// balance, and the initial balance to fund it with. The tier must be one of
+// limits and have limits in the account's currency.
+func newAccount(req models.AccountCreateRequest, limits LimitPolicy) (models.Account, decimal.Decimal, error) {
+	var errs []error
+
+	if req.AccountID == models.SystemAccountID {
+		errs = append(errs, ErrInvalidAccountID)
+	}
+
+	tier := req.Tier
+	if tier == "" {
+		tier = models.DefaultAccountTier
+	}
+	if !limits.hasTier(tier) {
+		errs = append(errs, ErrInvalidTier)
+	}
+
+	initialBalance, err := decimal.NewFromString(req.InitialBalance)
+	if err != nil || initialBalance.LessThan(decimal.Zero) {
+		errs = append(errs, ErrInvalidInitialBalance)
+		initialBalance = decimal.Zero
+	}
+
+	currencyCode := req.Currency
+	if currencyCode == "" {
+		currencyCode = DefaultCurrency
+	}
+
+	// The amounts can only be checked against a known currency.
+	cur, ok := currency.Lookup(currencyCode)
+	if !ok {
+		errs = append(errs, ErrUnsupportedCurrency)
+		return models.Account{}, decimal.Zero, errors.Join(errs...)
+	}
+
+	if limits.hasTier(tier) && !limits.coversCurrency(tier, cur.Code) {
+		errs = append(errs, ErrTierCurrency)
+	}
+
+	if !cur.Fits(initialBalance) {
+		errs = append(errs, &FieldError{Field: "initial_balance", Err: ErrAmountPrecision})
+	}
+
+	overdraftLimit := decimal.Zero
+	if req.OverdraftLimit != "" {
+		overdraftLimit, err = parseOverdraftLimit(req.OverdraftLimit, cur)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return models.Account{}, decimal.Zero, err
+	}
+
 	account := models.Account{
-		AccountID: req.AccountID,
-		Balance:   initialBalance,
+		AccountID:      req.AccountID,
+		Status:         models.AccountStatusActive,
+		Balance:        decimal.Zero,
+		Currency:       cur.Code,
+		OverdraftLimit: overdraftLimit,
+		Tier:           tier,
 	}
 
-	return s.accountRepo.Create(ctx, account)
+	return account, initialBalance, nil
+}
+
+// openingPostings fund an initial balance from the system account.
+func openingPostings(accountID int64, initialBalance decimal.Decimal, currencyCode string) []models.Posting {
+	return []models.Posting{
+		{AccountID: models.SystemAccountID, Amount: initialBalance.Neg(), Currency: currencyCode},
+		{AccountID: accountID, Amount: initialBalance, Currency: currencyCode},
+	}
 }
 
 func (s *AccountService) GetAccount(ctx context.Context, accountID int64) (models.Account, error) {
-	return s.accountRepo.GetByID(ctx, accountID)
+	account, err := s.accountStore.GetByID(ctx, accountID)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return withAvailableBalance(ctx, s.holdStore, account)
+}
+
+// UpdateOverdraftLimit sets how far below zero transfers may take the
+// account's balance. Lowering the limit below what the account already owes
+// is allowed; it only blocks further debits.
+func (s *AccountService) UpdateOverdraftLimit(ctx context.Context, accountID int64, req models.OverdraftLimitRequest) (models.Account, error) {
+	var account models.Account
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		var err error
+		account, err = stores.Accounts.GetByIDForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		cur, ok := currency.Lookup(account.Currency)
+		if !ok {
+			return ErrUnsupportedCurrency
+		}
+
+		limit, err := parseOverdraftLimit(req.OverdraftLimit, cur)
+		if err != nil {
+			return err
+		}
+
+		if err := stores.Accounts.UpdateOverdraftLimit(ctx, accountID, limit); err != nil {
+			return err
+		}
+
+		account.OverdraftLimit = limit
+		account, err = withAvailableBalance(ctx, stores.Holds, account)
+		return err
+	})
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return account, nil
+}
+
+// UpdateTier moves the account to another tier, whose limits apply from the
+// next transfer on. The account's own limits still take precedence.
+func (s *AccountService) UpdateTier(ctx context.Context, accountID int64, req models.AccountTierRequest) (models.Account, error) {
+	if !s.transactionService.limits.hasTier(req.Tier) {
+		return models.Account{}, ErrInvalidTier
+	}
+
+	var account models.Account
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		var err error
+		account, err = stores.Accounts.GetByIDForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if !s.transactionService.limits.coversCurrency(req.Tier, account.Currency) {
+			return ErrTierCurrency
+		}
+
+		if err := stores.Accounts.UpdateTier(ctx, accountID, req.Tier); err != nil {
+			return err
+		}
+
+		account.Tier = req.Tier
+		account, err = withAvailableBalance(ctx, stores.Holds, account)
+		return err
+	})
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return account, nil
+}
+
+func parseOverdraftLimit(value string, cur currency.Currency) (decimal.Decimal, error) {
+	limit, err := decimal.NewFromString(value)
+	if err != nil || limit.IsNegative() {
+		return decimal.Zero, ErrInvalidOverdraftLimit
+	}
+
+	if !cur.Fits(limit) {
+		return decimal.Zero, &FieldError{Field: "overdraft_limit", Err: ErrAmountPrecision}
+	}
+
+	return limit, nil
+}
+
+// ReconcileBalance recomputes the account balance from its postings and
+// reports any drift between that and the cached accounts.balance value. The
+// account row is locked while both are read so that a concurrent transfer
+// cannot show up as drift.
+func (s *AccountService) ReconcileBalance(ctx context.Context, accountID int64) (models.BalanceReconciliation, error) {
+	var reconciliation models.BalanceReconciliation
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		account, err := stores.Accounts.GetByIDForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		ledgerBalance, err := stores.Journal.SumPostings(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		drift := account.Balance.Sub(ledgerBalance)
+		reconciliation = models.BalanceReconciliation{
+			AccountID:     accountID,
+			CachedBalance: account.Balance,
+			LedgerBalance: ledgerBalance,
+			Drift:         drift,
+			InSync:        drift.IsZero(),
+		}
+		return nil
+	})
+	if err != nil {
+		return models.BalanceReconciliation{}, err
+	}
+
+	return reconciliation, nil
 }
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/currency"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidAccountFilter = errors.New("invalid account filter")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+)
+
+// accountCursor is the decoded form of AccountPage.NextCursor: the sort it
+// was issued for and the sort keys of the last account on the page.
+type accountCursor struct {
+	Sort      string          `json:"s"`
+	AccountID int64           `json:"id"`
+	Balance   decimal.Decimal `json:"b"`
+	CreatedAt time.Time       `json:"t"`
+}
+
+// ListAccounts returns a page of accounts. Pages are keyed on the last
+// account seen rather than an offset, so accounts created while paging do not
+// repeat or skip rows; they only show up if they sort after the cursor.
+func (s *AccountService) ListAccounts(ctx context.Context, req models.AccountListRequest) (models.AccountPage, error) {
+	q, err := accountQuery(req)
+	if err != nil {
+		return models.AccountPage{}, err
+	}
+
+	limit := q.Limit
+	q.Limit++
+
+	accounts, err := s.accountStore.List(ctx, q)
+	if err != nil {
+		return models.AccountPage{}, err
+	}
+
+	page := models.AccountPage{Accounts: accounts}
+	if len(accounts) > limit {
+		page.Accounts = accounts[:limit]
+		page.NextCursor, err = encodeAccountCursor(sortParam(q), page.Accounts[limit-1])
+		if err != nil {
+			return models.AccountPage{}, err
+		}
+	}
+
+	for i, account := range page.Accounts {
+		page.Accounts[i], err = withAvailableBalance(ctx, s.holdStore, account)
+		if err != nil {
+			return models.AccountPage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+func accountQuery(req models.AccountListRequest) (repository.AccountQuery, error) {
+	q := repository.AccountQuery{Limit: req.Limit}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit < 0 || q.Limit > MaxPageSize {
+		return q, &FieldError{Field: "limit", Err: ErrInvalidPagination}
+	}
+
+	switch req.Status {
+	case "", models.AccountStatusActive, models.AccountStatusFrozenDebit, models.AccountStatusFrozenAll, models.AccountStatusClosed:
+		q.Status = req.Status
+	default:
+		return q, &FieldError{Field: "status", Err: ErrInvalidAccountFilter}
+	}
+
+	if req.Currency != "" {
+		cur, ok := currency.Lookup(req.Currency)
+		if !ok {
+			return q, ErrUnsupportedCurrency
+		}
+		q.Currency = cur.Code
+	}
+
+	var err error
+	if q.MinBalance, err = optionalDecimal("min_balance", req.MinBalance); err != nil {
+		return q, err
+	}
+	if q.MaxBalance, err = optionalDecimal("max_balance", req.MaxBalance); err != nil {
+		return q, err
+	}
+	if q.CreatedFrom, err = optionalTime("created_from", req.CreatedFrom); err != nil {
+		return q, err
+	}
+	if q.CreatedTo, err = optionalTime("created_to", req.CreatedTo); err != nil {
+		return q, err
+	}
+
+	sortBy, descending := strings.CutPrefix(req.Sort, "-")
+	switch sortBy {
+	case "":
+		sortBy = repository.AccountSortID
+	case repository.AccountSortID, repository.AccountSortBalance, repository.AccountSortCreatedAt:
+	default:
+		return q, &FieldError{Field: "sort", Err: ErrInvalidAccountFilter}
+	}
+	q.SortBy, q.Descending = sortBy, descending
+
+	if req.Cursor != "" {
+		after, err := decodeAccountCursor(req.Cursor, sortParam(q))
+		if err != nil {
+			return q, err
+		}
+		q.After = &after
+	}
+
+	return q, nil
+}
+
+// sortParam is the canonical form of the sort parameter behind q.
+func sortParam(q repository.AccountQuery) string {
+	if q.Descending {
+		return "-" + q.SortBy
+	}
+	return q.SortBy
+}
+
+func encodeAccountCursor(sort string, last models.Account) (string, error) {
+	data, err := json.Marshal(accountCursor{
+		Sort:      sort,
+		AccountID: last.AccountID,
+		Balance:   last.Balance,
+		CreatedAt: last.CreatedAt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeAccountCursor rejects cursors issued for a different sort, whose keys
+// would not line up with the requested order.
+func decodeAccountCursor(value, sort string) (models.Account, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return models.Account{}, ErrInvalidCursor
+	}
+
+	var cursor accountCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort {
+		return models.Account{}, ErrInvalidCursor
+	}
+
+	return models.Account{
+		AccountID: cursor.AccountID,
+		Balance:   cursor.Balance,
+		CreatedAt: cursor.CreatedAt,
+	}, nil
+}
+
+func optionalDecimal(field, value string) (*decimal.Decimal, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return nil, &FieldError{Field: field, Err: ErrInvalidAccountFilter}
+	}
+
+	return &d, nil
+}
+
+func optionalTime(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, &FieldError{Field: field, Err: ErrInvalidAccountFilter}
+	}
+
+	return &t, nil
+}
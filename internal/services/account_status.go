@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+var (
+	ErrAccountFrozen           = errors.New("account is frozen")
+	ErrAccountClosed           = errors.New("account is closed")
+	ErrInvalidFreezeMode       = errors.New("invalid freeze mode")
+	ErrStatusReasonRequired    = errors.New("a reason of at most 500 characters is required")
+	ErrAccountStatusTransition = errors.New("account cannot make that status change")
+	ErrAccountBalanceNotZero   = errors.New("account balance must be zero to close it")
+	ErrAccountHasHolds         = errors.New("account has active holds")
+)
+
+const MaxStatusReasonLength = 500
+
+// FreezeAccount stops debits from the account, or with FreezeAll debits and
+// credits, until it is unfrozen. A frozen account can be refrozen in the
+// other mode.
+func (s *AccountService) FreezeAccount(ctx context.Context, accountID int64, req models.FreezeAccountRequest) (models.Account, error) {
+	status := models.AccountStatusFrozenAll
+	switch req.Mode {
+	case "", models.FreezeAll:
+	case models.FreezeDebit:
+		status = models.AccountStatusFrozenDebit
+	default:
+		return models.Account{}, ErrInvalidFreezeMode
+	}
+
+	return s.changeStatus(ctx, accountID, req.Reason, func(stores repository.Stores, account models.Account) (models.AccountStatusChange, error) {
+		if account.Status == status || account.Status == models.AccountStatusClosed {
+			return models.AccountStatusChange{}, ErrAccountStatusTransition
+		}
+
+		return models.AccountStatusChange{ToStatus: status}, nil
+	})
+}
+
+func (s *AccountService) UnfreezeAccount(ctx context.Context, accountID int64, req models.UnfreezeAccountRequest) (models.Account, error) {
+	return s.changeStatus(ctx, accountID, req.Reason, func(stores repository.Stores, account models.Account) (models.AccountStatusChange, error) {
+		if account.Status != models.AccountStatusFrozenDebit && account.Status != models.AccountStatusFrozenAll {
+			return models.AccountStatusChange{}, ErrAccountStatusTransition
+		}
+
+		return models.AccountStatusChange{ToStatus: models.AccountStatusActive}, nil
+	})
+}
+
+// CloseAccount closes the account for good. Its balance must be zero, or
+// positive with SweepToAccountID set, in which case the balance is first
+// transferred there. Sweeping is a debit, so it fails while the account is
+// frozen for debits.
+func (s *AccountService) CloseAccount(ctx context.Context, accountID int64, req models.CloseAccountRequest) (models.Account, error) {
+	if req.SweepToAccountID != nil && *req.SweepToAccountID == accountID {
+		return models.Account{}, ErrSameSourceAndDest
+	}
+
+	return s.changeStatus(ctx, accountID, req.Reason, func(stores repository.Stores, account models.Account) (models.AccountStatusChange, error) {
+		change := models.AccountStatusChange{ToStatus: models.AccountStatusClosed}
+
+		if account.Status == models.AccountStatusClosed {
+			return change, ErrAccountStatusTransition
+		}
+
+		if account.AvailableBalance.LessThan(account.Balance) {
+			return change, ErrAccountHasHolds
+		}
+
+		if account.Balance.IsZero() {
+			return change, nil
+		}
+
+		if req.SweepToAccountID == nil || account.Balance.IsNegative() {
+			return change, ErrAccountBalanceNotZero
+		}
+
+		sweep, err := s.transactionService.executeTransfer(ctx, stores, models.TransactionRequest{
+			SourceAccountID:      accountID,
+			DestinationAccountID: *req.SweepToAccountID,
+			Amount:               account.Balance.String(),
+			FXMode:               models.FXModeConvert,
+		}, account.Balance, nil)
+		if err != nil {
+			return change, err
+		}
+
+		change.SweepTransactionID = &sweep.ID
+		return change, nil
+	})
+}
+
+func (s *AccountService) ListStatusChanges(ctx context.Context, accountID int64) ([]models.AccountStatusChange, error) {
+	if _, err := s.accountStore.GetByID(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	return s.accountStore.ListStatusChanges(ctx, accountID)
+}
+
+// changeStatus locks the account, lets decide pick the new status, and
+// applies and audits the change. decide runs in the unit of work and may move
+// money through stores.
+func (s *AccountService) changeStatus(
+	ctx context.Context,
+	accountID int64,
+	reason string,
+	decide func(stores repository.Stores, account models.Account) (models.AccountStatusChange, error),
+) (models.Account, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > MaxStatusReasonLength {
+		return models.Account{}, ErrStatusReasonRequired
+	}
+
+	var account models.Account
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		// A sweep locks the destination too; lockAccounts is a no-op for
+		// this account then.
+		accounts, err := lockAccounts(ctx, stores, accountID)
+		if err != nil {
+			return err
+		}
+		account = accounts[accountID]
+
+		change, err := decide(stores, account)
+		if err != nil {
+			return err
+		}
+
+		change.AccountID = accountID
+		change.FromStatus = account.Status
+		change.Reason = reason
+
+		if err := stores.Accounts.UpdateStatus(ctx, accountID, change.ToStatus); err != nil {
+			return err
+		}
+
+		if _, err := stores.Accounts.CreateStatusChange(ctx, change); err != nil {
+			return err
+		}
+
+		account, err = stores.Accounts.GetByID(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		account, err = withAvailableBalance(ctx, stores.Holds, account)
+		return err
+	})
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return account, nil
+}
+
+// checkCanDebit reports whether the account's status lets money leave it.
+func checkCanDebit(account models.Account) error {
+	switch account.Status {
+	case models.AccountStatusFrozenDebit, models.AccountStatusFrozenAll:
+		return ErrAccountFrozen
+	case models.AccountStatusClosed:
+		return ErrAccountClosed
+	default:
+		return nil
+	}
+}
+
+// checkCanCredit reports whether the account's status lets money arrive.
+func checkCanCredit(account models.Account) error {
+	switch account.Status {
+	case models.AccountStatusFrozenAll:
+		return ErrAccountFrozen
+	case models.AccountStatusClosed:
+		return ErrAccountClosed
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/auth"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+var (
+	ErrUnauthenticated     = errors.New("missing, invalid or revoked API key")
+	ErrInsufficientScope   = errors.New("caller lacks the scope the request needs")
+	ErrAccountNotPermitted = errors.New("caller may not debit the account")
+
+	ErrInvalidAPIKeyName   = errors.New("invalid API key name")
+	ErrInvalidScope        = errors.New("invalid scope")
+	ErrInvalidGracePeriod  = errors.New("invalid grace period")
+	ErrInvalidBootstrapKey = errors.New("bootstrap API key is too short")
+	ErrAPIKeyInactive      = errors.New("API key is revoked or was already rotated")
+	ErrInitiatorInactive   = errors.New("API key that initiated the work is revoked or has expired")
+)
+
+const (
+	MaxAPIKeyNameLength = 100
+	MaxAPIKeyAccounts   = 100
+	MaxRotationGrace    = 7 * 24 * time.Hour
+
+	// apiKeyPrefix starts every minted key, so that leaked keys are easy to
+	// spot. The stored prefix adds the first few random characters.
+	apiKeyPrefix       = "tsk_"
+	apiKeyPrefixLength = len(apiKeyPrefix) + 8
+	// MinBootstrapKeyLength keeps a key supplied by the operator from being
+	// much weaker than a minted one.
+	MinBootstrapKeyLength = 32
+)
+
+type APIKeyService struct {
+	uow         repository.UnitOfWork
+	apiKeyStore repository.APIKeyStore
+}
+
+func NewAPIKeyService(uow repository.UnitOfWork, apiKeyStore repository.APIKeyStore) *APIKeyService {
+	return &APIKeyService{
+		uow:         uow,
+		apiKeyStore: apiKeyStore,
+	}
+}
+
+// CreateKey mints a key. The returned secret is not stored and cannot be
+// retrieved again.
+func (s *APIKeyService) CreateKey(ctx context.Context, req models.APIKeyRequest) (models.IssuedAPIKey, error) {
+	key, err := newAPIKey(req)
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	return s.issue(ctx, s.apiKeyStore, key, secret)
+}
+
+// newAPIKey validates req and returns the key to create, without its secret.
+func newAPIKey(req models.APIKeyRequest) (models.APIKey, error) {
+	var errs []error
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || !validText(name, MaxAPIKeyNameLength) {
+		errs = append(errs, ErrInvalidAPIKeyName)
+	}
+
+	if len(req.Scopes) == 0 || slices.ContainsFunc(req.Scopes, func(scope string) bool { return !auth.ValidScope(scope) }) {
+		errs = append(errs, ErrInvalidScope)
+	}
+
+	if len(req.AccountIDs) > MaxAPIKeyAccounts || slices.Contains(req.AccountIDs, models.SystemAccountID) {
+		errs = append(errs, &FieldError{Field: "account_ids", Err: ErrInvalidAccountID})
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return models.APIKey{}, err
+	}
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+
+	accountIDs := slices.Clone(req.AccountIDs)
+	slices.Sort(accountIDs)
+
+	return models.APIKey{
+		Name:       name,
+		Scopes:     slices.Compact(scopes),
+		AccountIDs: slices.Compact(accountIDs),
+	}, nil
+}
+
+func newAPIKeySecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// hashAPIKey returns the hash keys are stored and looked up by. Keys are long
+// random strings, so a plain SHA-256 is enough; a slow password hash would
+// only slow down every request.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *APIKeyService) issue(ctx context.Context, store repository.APIKeyStore, key models.APIKey, secret string) (models.IssuedAPIKey, error) {
+	if key.Prefix == "" {
+		key.Prefix = secret[:apiKeyPrefixLength]
+	}
+	key.KeyHash = hashAPIKey(secret)
+
+	key, err := store.Create(ctx, key)
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	return models.IssuedAPIKey{APIKey: key, Secret: secret}, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.apiKeyStore.List(ctx)
+}
+
+// RotateKey mints a successor with the same name, scopes and accounts. The
+// old key keeps working for the grace period, if any, and is revoked at once
+// otherwise.
+func (s *APIKeyService) RotateKey(ctx context.Context, id int64, req models.APIKeyRotateRequest) (models.IssuedAPIKey, error) {
+	grace := time.Duration(req.GracePeriodSeconds) * time.Second
+	if req.GracePeriodSeconds < 0 || grace > MaxRotationGrace {
+		return models.IssuedAPIKey{}, ErrInvalidGracePeriod
+	}
+
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	var issued models.IssuedAPIKey
+
+	err = runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		old, err := stores.APIKeys.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if !old.Active(now) || old.ExpiresAt != nil {
+			return ErrAPIKeyInactive
+		}
+
+		if grace == 0 {
+			old.RevokedAt = &now
+		} else {
+			expiresAt := now.Add(grace)
+			old.ExpiresAt = &expiresAt
+		}
+
+		if err := stores.APIKeys.Update(ctx, old); err != nil {
+			return err
+		}
+
+		issued, err = s.issue(ctx, stores.APIKeys, models.APIKey{
+			Name:        old.Name,
+			Scopes:      old.Scopes,
+			AccountIDs:  old.AccountIDs,
+			RotatedFrom: &old.ID,
+		}, secret)
+		return err
+	})
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	return issued, nil
+}
+
+// RevokeKey stops the key from authenticating. Revoking a revoked key
+// changes nothing.
+func (s *APIKeyService) RevokeKey(ctx context.Context, id int64) (models.APIKey, error) {
+	var key models.APIKey
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		var err error
+		key, err = stores.APIKeys.GetByIDForUpdate(ctx, id)
+		if err != nil || key.RevokedAt != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		key.RevokedAt = &now
+		return stores.APIKeys.Update(ctx, key)
+	})
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// Authenticate returns the principal of the key whose secret was presented.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (auth.Principal, error) {
+	if secret == "" {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+
+	key, err := s.apiKeyStore.GetByHash(ctx, hashAPIKey(secret))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	if !key.Active(time.Now()) {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+
+	return auth.Principal{
+		ID:         fmt.Sprintf("api_key:%d", key.ID),
+		Scopes:     key.Scopes,
+		AccountIDs: key.AccountIDs,
+	}, nil
+}
+
+// CurrentPrincipal returns the caller initiatedBy names as it stands now, for
+// work done later on its behalf such as scheduled runs. A rotated API key is
+// followed to its successor, whose scopes and accounts then apply; a key
+// revoked or expired without one is ErrInitiatorInactive. Other callers, such
+// as JWT subjects, have nothing stored to check and are returned by ID alone.
+func (s *APIKeyService) CurrentPrincipal(ctx context.Context, initiatedBy string) (auth.Principal, error) {
+	idStr, ok := strings.CutPrefix(initiatedBy, "api_key:")
+	if !ok {
+		return auth.Principal{ID: initiatedBy}, nil
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return auth.Principal{}, fmt.Errorf("parse initiator %q: %w", initiatedBy, err)
+	}
+
+	key, err := s.apiKeyStore.GetByID(ctx, id)
+	now := time.Now()
+	for err == nil && !key.Active(now) {
+		key, err = s.apiKeyStore.GetByRotatedFrom(ctx, key.ID)
+	}
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return auth.Principal{}, ErrInitiatorInactive
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	// Audit fields keep naming the key the work was set up with.
+	return auth.Principal{
+		ID:         initiatedBy,
+		Scopes:     key.Scopes,
+		AccountIDs: key.AccountIDs,
+	}, nil
+}
+
+// EnsureBootstrapKey stores secret as an admin key named bootstrap unless a
+// key with that secret already exists, so that a fresh deployment can mint
+// its first keys. A bootstrap key that was revoked stays revoked.
+func (s *APIKeyService) EnsureBootstrapKey(ctx context.Context, secret string) error {
+	if len(secret) < MinBootstrapKeyLength {
+		return ErrInvalidBootstrapKey
+	}
+
+	_, err := s.apiKeyStore.GetByHash(ctx, hashAPIKey(secret))
+	if !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return err
+	}
+
+	// The operator chose the secret, so none of it is kept as its prefix.
+	_, err = s.issue(ctx, s.apiKeyStore, models.APIKey{
+		Name:   "bootstrap",
+		Prefix: "bootstrap",
+		Scopes: []string{auth.ScopeAdmin},
+	}, secret)
+	if errors.Is(err, repository.ErrAPIKeyExists) {
+		// Another instance stored it first.
+		return nil
+	}
+
+	return err
+}
+
+// checkDebitPermitted reports whether the caller, if any, may debit the
+// account.
+func checkDebitPermitted(ctx context.Context, accountID int64) error {
+	if p, ok := auth.FromContext(ctx); ok && !p.CanDebit(accountID) {
+		return ErrAccountNotPermitted
+	}
+	return nil
+}
+
+// initiatedBy names the caller, if any, for a record's initiated_by.
+func initiatedBy(ctx context.Context) string {
+	p, _ := auth.FromContext(ctx)
+	return p.ID
+}
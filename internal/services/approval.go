@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrApprovalRequired      = errors.New("transfers above the approval threshold must be made on their own")
+	ErrNotPendingApproval    = errors.New("transaction is not awaiting approval")
+	ErrApprovalExpired       = errors.New("approval window has passed")
+	ErrSelfApproval          = errors.New("a transfer must be approved or rejected by a caller other than the one that made it")
+	ErrInvalidApprovalReason = errors.New("invalid approval reason")
+)
+
+const (
+	DefaultApprovalTTL      = 24 * time.Hour
+	MaxApprovalReasonLength = 500
+)
+
+// ApprovalPolicy holds transfers whose amount, in the source account's
+// currency, is above Threshold for approval by a second caller. They expire
+// if nobody decides within TTL. A zero Threshold turns approvals off.
+type ApprovalPolicy struct {
+	Threshold decimal.Decimal
+	TTL       time.Duration
+}
+
+func (s *TransactionService) needsApproval(amount decimal.Decimal) bool {
+	return s.approvals.Threshold.IsPositive() && amount.GreaterThan(s.approvals.Threshold)
+}
+
+// requestApproval runs every check of executeTransfer and records the
+// transfer as pending_approval, without moving any money or using its FX
+// quote.
+func (s *TransactionService) requestApproval(ctx context.Context, stores repository.Stores, req models.TransactionRequest, amount decimal.Decimal) (models.Transaction, error) {
+	accounts, err := lockAccounts(ctx, stores, req.SourceAccountID, req.DestinationAccountID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	transaction, err := s.planTransfer(ctx, stores, accounts, req, amount)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	expiresAt := time.Now().Add(s.approvals.TTL)
+	transaction.Status = models.TransactionStatusPendingApproval
+	transaction.ApprovalExpiresAt = &expiresAt
+
+	transaction, err = stores.Transactions.Create(ctx, transaction)
+	if errors.Is(err, repository.ErrExternalReferenceExists) {
+		return models.Transaction{}, ErrDuplicateExternalReference
+	}
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	_, err = stores.Approvals.CreateEvent(ctx, models.ApprovalEvent{
+		TransactionID: transaction.ID,
+		Action:        models.ApprovalActionRequested,
+		Actor:         transaction.InitiatedBy,
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// ApproveTransaction posts a transfer awaiting approval. The transfer is
+// checked again as if it were made now, by the approving caller: balances,
+// account status, debit permission and, without a quote, the current FX
+// rate. A failed check leaves it pending.
+func (s *TransactionService) ApproveTransaction(ctx context.Context, id int64, req models.ApprovalDecisionRequest) (models.Transaction, error) {
+	if !validText(req.Reason, MaxApprovalReasonLength) {
+		return models.Transaction{}, ErrInvalidApprovalReason
+	}
+
+	var transaction models.Transaction
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		pending, err := lockPendingApproval(ctx, stores, id)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(ctx, stores, pending.SourceAccountID, pending.DestinationAccountID)
+		if err != nil {
+			return err
+		}
+
+		// The pending transfer already holds its external reference, so it
+		// is left out of the checks.
+		transfer := models.TransactionRequest{
+			SourceAccountID:      pending.SourceAccountID,
+			DestinationAccountID: pending.DestinationAccountID,
+			Amount:               pending.Amount.String(),
+			Description:          pending.Description,
+			Metadata:             pending.Metadata,
+		}
+		if pending.Currency != pending.DestinationCurrency {
+			transfer.FXMode = models.FXModeConvert
+		}
+		if pending.FXQuoteID != nil {
+			transfer.FXQuoteID = *pending.FXQuoteID
+		}
+
+		planned, err := s.planTransfer(ctx, stores, accounts, transfer, pending.Amount)
+		if err != nil {
+			return err
+		}
+
+		pending.Status = models.TransactionStatusPosted
+		pending.DestinationAmount = planned.DestinationAmount
+		pending.FXRate = planned.FXRate
+
+		if err := stores.Transactions.UpdateStatus(ctx, pending); err != nil {
+			return err
+		}
+
+		if pending.FXQuoteID != nil {
+			if err := stores.FXQuotes.MarkUsed(ctx, *pending.FXQuoteID, pending.ID); err != nil {
+				return err
+			}
+		}
+
+		_, err = postJournalEntry(ctx, stores, accounts, models.JournalEntry{
+			Kind:          models.JournalEntryTransfer,
+			TransactionID: &pending.ID,
+			Postings:      transferPostings(pending),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = stores.Approvals.CreateEvent(ctx, models.ApprovalEvent{
+			TransactionID: pending.ID,
+			Action:        models.ApprovalActionApproved,
+			Actor:         initiatedBy(ctx),
+			Reason:        req.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		transaction, err = stores.Transactions.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// RejectTransaction turns down a transfer awaiting approval. It never moves
+// money, and its external reference may be used again.
+func (s *TransactionService) RejectTransaction(ctx context.Context, id int64, req models.ApprovalDecisionRequest) (models.Transaction, error) {
+	if !validText(req.Reason, MaxApprovalReasonLength) {
+		return models.Transaction{}, ErrInvalidApprovalReason
+	}
+
+	var transaction models.Transaction
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		pending, err := lockPendingApproval(ctx, stores, id)
+		if err != nil {
+			return err
+		}
+
+		pending.Status = models.TransactionStatusRejected
+		if err := stores.Transactions.UpdateStatus(ctx, pending); err != nil {
+			return err
+		}
+
+		_, err = stores.Approvals.CreateEvent(ctx, models.ApprovalEvent{
+			TransactionID: pending.ID,
+			Action:        models.ApprovalActionRejected,
+			Actor:         initiatedBy(ctx),
+			Reason:        req.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		transaction, err = stores.Transactions.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// lockPendingApproval locks a transfer awaiting approval on behalf of the
+// caller deciding it, who must not be the caller that made it. Without
+// authentication there is no caller to tell apart, so nothing can be decided.
+func lockPendingApproval(ctx context.Context, stores repository.Stores, id int64) (models.Transaction, error) {
+	transaction, err := stores.Transactions.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	if transaction.Status != models.TransactionStatusPendingApproval {
+		return models.Transaction{}, ErrNotPendingApproval
+	}
+
+	if checker := initiatedBy(ctx); checker == "" || checker == transaction.InitiatedBy {
+		return models.Transaction{}, ErrSelfApproval
+	}
+
+	if !transaction.ApprovalExpiresAt.After(time.Now()) {
+		return models.Transaction{}, ErrApprovalExpired
+	}
+
+	return transaction, nil
+}
+
+// ListApprovalEvents returns the approval chain of a transaction, oldest
+// first. It is empty for transfers that did not need approval.
+func (s *TransactionService) ListApprovalEvents(ctx context.Context, id int64) ([]models.ApprovalEvent, error) {
+	if _, err := s.transactionStore.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.approvalStore.ListEvents(ctx, id)
+}
+
+// ExpirePendingApprovals expires transfers nobody decided on in time and
+// returns how many it expired.
+func (s *TransactionService) ExpirePendingApprovals(ctx context.Context) (int, error) {
+	var expired []int64
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		var err error
+		expired, err = stores.Transactions.ExpirePending(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range expired {
+			_, err := stores.Approvals.CreateEvent(ctx, models.ApprovalEvent{
+				TransactionID: id,
+				Action:        models.ApprovalActionExpired,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(expired), nil
+}
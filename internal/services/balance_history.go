@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+var (
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	ErrInvalidTimeRange = errors.New("invalid time range")
+	ErrInvalidInterval  = errors.New("invalid interval")
+)
+
+const (
+	// DefaultTimeRange is how far back a history or statement reaches when
+	// the request leaves from unset.
+	DefaultTimeRange = 30 * 24 * time.Hour
+	// MaxBalanceHistoryPeriods caps how many periods an interval may split
+	// the range into.
+	MaxBalanceHistoryPeriods = 1000
+)
+
+var balanceHistoryIntervals = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+// GetBalanceAsOf returns the account's balance at asOf, an RFC 3339 timestamp
+// that defaults to now, by summing the postings made up to then.
+func (s *AccountService) GetBalanceAsOf(ctx context.Context, accountID int64, asOf string) (models.AccountBalance, error) {
+	at := time.Now().UTC()
+	if asOf != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, asOf)
+		if err != nil {
+			return models.AccountBalance{}, &FieldError{Field: "as_of", Err: ErrInvalidTimestamp}
+		}
+		at = parsed
+	}
+
+	var balance models.AccountBalance
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		account, err := stores.Accounts.GetByID(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		sum, err := stores.Journal.SumPostingsUntil(ctx, accountID, at)
+		if err != nil {
+			return err
+		}
+
+		balance = models.AccountBalance{
+			AccountID: accountID,
+			Currency:  account.Currency,
+			AsOf:      at,
+			Balance:   sum,
+		}
+		return nil
+	})
+	if err != nil {
+		return models.AccountBalance{}, err
+	}
+
+	return balance, nil
+}
+
+// GetBalanceHistory replays the account's postings between req.From and
+// req.To on top of the balance at req.From. Without an interval it lists the
+// running balance after every posting; with one it lists the balance at the
+// end of each period. Periods are aligned to UTC hours, days or weeks starting
+// on Monday, so the first and last may be cut short by req.From and req.To.
+func (s *AccountService) GetBalanceHistory(ctx context.Context, accountID int64, req models.BalanceHistoryRequest) (models.BalanceHistory, error) {
+	from, to, err := parseTimeRange(req.From, req.To)
+	if err != nil {
+		return models.BalanceHistory{}, err
+	}
+
+	var interval time.Duration
+	// start is where the first period would begin were it not cut short.
+	var start time.Time
+	if req.Interval != "" {
+		var ok bool
+		interval, ok = balanceHistoryIntervals[req.Interval]
+		if !ok {
+			return models.BalanceHistory{}, ErrInvalidInterval
+		}
+
+		// Truncate counts from the zero time, a Monday at UTC midnight.
+		start = from.UTC().Truncate(interval)
+		if to.Sub(start) > interval*MaxBalanceHistoryPeriods {
+			return models.BalanceHistory{}, ErrInvalidTimeRange
+		}
+	}
+
+	history := models.BalanceHistory{
+		AccountID: accountID,
+		From:      from,
+		To:        to,
+		Interval:  req.Interval,
+	}
+	var entries []models.JournalEntry
+
+	err = runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		account, err := stores.Accounts.GetByID(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		history.Currency = account.Currency
+
+		history.OpeningBalance, err = stores.Journal.SumPostingsUntil(ctx, accountID, from)
+		if err != nil {
+			return err
+		}
+
+		entries, err = stores.Journal.ListEntries(ctx, accountID, from, to)
+		return err
+	})
+	if err != nil {
+		return models.BalanceHistory{}, err
+	}
+
+	balance := history.OpeningBalance
+	history.Entries = []models.BalanceHistoryEntry{}
+
+	if interval == 0 {
+		for _, entry := range entries {
+			for _, posting := range entry.Postings {
+				balance = balance.Add(posting.Amount)
+				history.Entries = append(history.Entries, models.BalanceHistoryEntry{
+					At:             posting.CreatedAt,
+					JournalEntryID: &entry.ID,
+					Kind:           entry.Kind,
+					TransactionID:  entry.TransactionID,
+					Change:         posting.Amount,
+					Balance:        balance,
+				})
+			}
+		}
+	} else {
+		for end := start.Add(interval); ; end = end.Add(interval) {
+			if !end.Before(to) {
+				history.Entries = append(history.Entries, models.BalanceHistoryEntry{At: to})
+				break
+			}
+			history.Entries = append(history.Entries, models.BalanceHistoryEntry{At: end})
+		}
+
+		// Postings are stamped with their unit of work's start time, which
+		// need not follow posting order, so each is placed by its time.
+		for _, entry := range entries {
+			period := int((entry.CreatedAt.Sub(start) - 1) / interval)
+			for _, posting := range entry.Postings {
+				history.Entries[period].Change = history.Entries[period].Change.Add(posting.Amount)
+			}
+		}
+
+		for i := range history.Entries {
+			balance = balance.Add(history.Entries[i].Change)
+			history.Entries[i].Balance = balance
+		}
+	}
+
+	history.ClosingBalance = balance
+	return history, nil
+}
+
+// parseTimeRange parses optional RFC 3339 bounds. to defaults to now and from
+// to DefaultTimeRange before to.
+func parseTimeRange(fromParam, toParam string) (time.Time, time.Time, error) {
+	to := time.Now().UTC()
+	if toParam != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, &FieldError{Field: "to", Err: ErrInvalidTimestamp}
+		}
+		to = parsed
+	}
+
+	from := to.Add(-DefaultTimeRange)
+	if fromParam != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, &FieldError{Field: "from", Err: ErrInvalidTimestamp}
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, ErrInvalidTimeRange
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestBalanceHistoryPeriodsAlignToUTC(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 1, "100")
+	s.createAccount(t, 2, "0")
+
+	if _, err := s.transactions.CreateTransaction(context.Background(), transfer(1, 2, "30"), ""); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	// A range starting mid-day, in a zone other than UTC.
+	zone := time.FixedZone("UTC+5", 5*60*60)
+	now := time.Now()
+	from := now.Add(-50 * time.Hour).In(zone)
+	to := now.Add(time.Minute).In(zone)
+
+	tests := []struct {
+		interval string
+		boundary func(time.Time) bool
+	}{
+		{"hour", func(at time.Time) bool { return at.Equal(at.Truncate(time.Hour)) }},
+		{"day", func(at time.Time) bool { return at.Equal(at.Truncate(time.Hour)) && at.Hour() == 0 }},
+		{"week", func(at time.Time) bool {
+			return at.Equal(at.Truncate(time.Hour)) && at.Hour() == 0 && at.Weekday() == time.Monday
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			history, err := s.accounts.GetBalanceHistory(context.Background(), 1, models.BalanceHistoryRequest{
+				From:     from.Format(time.RFC3339Nano),
+				To:       to.Format(time.RFC3339Nano),
+				Interval: tt.interval,
+			})
+			if err != nil {
+				t.Fatalf("GetBalanceHistory: %v", err)
+			}
+
+			entries := history.Entries
+			if len(entries) == 0 {
+				t.Fatal("no periods")
+			}
+
+			// Every period but the last ends on a UTC boundary; the last ends at to.
+			previous := from
+			for i, entry := range entries {
+				if !entry.At.After(previous) {
+					t.Errorf("period %d ends at %s, not after %s", i, entry.At, previous)
+				}
+				if i < len(entries)-1 && !tt.boundary(entry.At.UTC()) {
+					t.Errorf("period %d ends at %s, off a UTC %s boundary", i, entry.At.UTC(), tt.interval)
+				}
+				previous = entry.At
+			}
+			if last := entries[len(entries)-1].At; !last.Equal(to) {
+				t.Errorf("last period ends at %s, want %s", last, to)
+			}
+
+			// The first period is the partial one up to the first boundary.
+			if first := entries[0].At; first.Sub(from) > balanceHistoryIntervals[tt.interval] {
+				t.Errorf("first period ends at %s, more than an interval after %s", first, from)
+			}
+
+			change := decimal.Zero
+			for _, entry := range entries {
+				change = change.Add(entry.Change)
+			}
+			if !change.Equal(history.ClosingBalance.Sub(history.OpeningBalance)) {
+				t.Errorf("changes add up to %s, balance moved from %s to %s", change, history.OpeningBalance, history.ClosingBalance)
+			}
+			if !history.ClosingBalance.Equal(decimal.NewFromInt(70)) {
+				t.Errorf("closing balance = %s, want 70", history.ClosingBalance)
+			}
+		})
+	}
+}
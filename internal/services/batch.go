@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidBatch = errors.New("batch must have between 1 and 1000 legs")
+)
+
+const MaxBatchLegs = 1000
+
+// BatchError rejects a batch and lists every leg that failed. None of the
+// batch's legs were posted.
+type BatchError struct {
+	Legs []BatchLegError
+}
+
+type BatchLegError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of the batch's legs failed", len(e.Legs))
+}
+
+// transferRejections are the errors with which a transfer is turned down on
+// its merits, as opposed to failing for a reason a retry could fix.
+var transferRejections = []error{
+	ErrSameSourceAndDest,
+	ErrInvalidAmount,
+	ErrInvalidFXMode,
+	ErrAmountPrecision,
+	ErrInsufficientBalance,
+	ErrAccountFrozen,
+	ErrAccountClosed,
+	ErrCurrencyMismatch,
+	ErrFXUnavailable,
+	ErrFXRateUnavailable,
+	ErrFXQuoteMismatch,
+	ErrFXQuoteExpired,
+	ErrFXQuoteUsed,
+	ErrInvalidDescription,
+	ErrInvalidExternalReference,
+	ErrInvalidMetadata,
+	ErrDuplicateExternalReference,
+	ErrAccountNotPermitted,
+	ErrApprovalRequired,
+	ErrLimitExceeded,
+	ErrRiskDenied,
+	ErrRiskReviewRequired,
+	repository.ErrAccountNotFound,
+	repository.ErrFXQuoteNotFound,
+}
+
+// IsTransferRejection reports whether err means the transfer itself was
+// rejected, for example for insufficient funds.
+func IsTransferRejection(err error) bool {
+	return slices.ContainsFunc(transferRejections, func(target error) bool {
+		return errors.Is(err, target)
+	})
+}
+
+// CreateBatch posts every leg of the batch in a single unit of work, in order,
+// with all involved accounts locked up front in canonical order. If any leg
+// fails, nothing is posted and the returned *BatchError reports every failing
+// leg. idempotencyKey works as for CreateTransaction.
+func (s *TransactionService) CreateBatch(ctx context.Context, req models.BatchTransactionRequest, idempotencyKey string) (models.TransactionBatch, error) {
+	if err := checkIdempotencyKey(idempotencyKey); err != nil {
+		return models.TransactionBatch{}, err
+	}
+
+	if len(req.Legs) == 0 || len(req.Legs) > MaxBatchLegs {
+		return models.TransactionBatch{}, ErrInvalidBatch
+	}
+
+	amounts := make([]decimal.Decimal, len(req.Legs))
+	var failed []BatchLegError
+	for i, leg := range req.Legs {
+		amount, err := validateTransferRequest(leg)
+		if err != nil {
+			failed = append(failed, BatchLegError{Index: i, Err: err})
+			continue
+		}
+		// A batch posts or fails as a whole, so no leg can wait for
+		// approval.
+		if s.needsApproval(amount) {
+			failed = append(failed, BatchLegError{Index: i, Err: ErrApprovalRequired})
+			continue
+		}
+		amounts[i] = amount
+	}
+
+	if len(failed) > 0 {
+		return models.TransactionBatch{}, &BatchError{Legs: failed}
+	}
+
+	var requestHash string
+	if idempotencyKey != "" {
+		var err error
+		requestHash, err = hashRequest(req)
+		if err != nil {
+			return models.TransactionBatch{}, err
+		}
+
+		batch, err := s.replayBatch(ctx, idempotencyKey, requestHash)
+		if !errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+			return batch, err
+		}
+	}
+
+	batch, err := s.postBatch(ctx, req, amounts, idempotencyKey, requestHash)
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		batch, err = s.replayBatch(ctx, idempotencyKey, requestHash)
+		return batch, concurrentReplayError(err)
+	}
+
+	return batch, err
+}
+
+func (s *TransactionService) postBatch(ctx context.Context, req models.BatchTransactionRequest, amounts []decimal.Decimal, idempotencyKey, requestHash string) (models.TransactionBatch, error) {
+	var batch models.TransactionBatch
+	// rejected holds the risk decisions of legs the rules turned down, which
+	// are recorded even though the batch is not.
+	var rejected []models.RiskDecision
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		accountIDs := make([]int64, 0, 2*len(req.Legs))
+		for _, leg := range req.Legs {
+			accountIDs = append(accountIDs, leg.SourceAccountID, leg.DestinationAccountID)
+		}
+		slices.Sort(accountIDs)
+		accountIDs = slices.Compact(accountIDs)
+
+		// Each leg locks its own pair again, which is a no-op by then. A
+		// missing account is reported by the legs that use it.
+		for _, id := range accountIDs {
+			_, err := stores.Accounts.GetByIDForUpdate(ctx, id)
+			if err != nil && !errors.Is(err, repository.ErrAccountNotFound) {
+				return err
+			}
+		}
+
+		var err error
+		batch, err = stores.Batches.Create(ctx, models.TransactionBatch{LegCount: len(req.Legs)})
+		if err != nil {
+			return err
+		}
+
+		var failed []BatchLegError
+		var decisions []models.RiskDecision
+		rejected = nil
+		batch.Legs = make([]models.BatchLegResult, len(req.Legs))
+		for i, leg := range req.Legs {
+			transaction, decision, err := s.postBatchLeg(ctx, stores, leg, amounts[i], &batch.ID)
+			if err != nil {
+				// Only a rejected leg lets the other legs be checked;
+				// anything else aborts the batch.
+				if !IsTransferRejection(err) {
+					return err
+				}
+				failed = append(failed, BatchLegError{Index: i, Err: err})
+				if decision != nil && decision.Action != models.RiskActionAllow {
+					rejected = append(rejected, *decision)
+				}
+				continue
+			}
+
+			batch.Legs[i] = models.BatchLegResult{Index: i, Transaction: &transaction}
+			if decision != nil {
+				decision.TransactionID = &transaction.ID
+				decisions = append(decisions, *decision)
+			}
+		}
+
+		if len(failed) > 0 {
+			return &BatchError{Legs: failed}
+		}
+
+		for _, decision := range decisions {
+			if _, err := stores.Risk.CreateDecision(ctx, decision); err != nil {
+				return err
+			}
+		}
+
+		if idempotencyKey == "" {
+			return nil
+		}
+
+		// The key points at the first leg, which leads back to the batch.
+		record := models.IdempotencyKey{
+			Key:           idempotencyKey,
+			RequestHash:   requestHash,
+			TransactionID: batch.Legs[0].Transaction.ID,
+		}
+
+		return stores.Idempotency.Create(ctx, record, s.idempotencyTTL)
+	})
+	if err != nil {
+		var batchErr *BatchError
+		if errors.As(err, &batchErr) && len(rejected) > 0 {
+			if err := s.recordRejectedDecisions(ctx, rejected); err != nil {
+				return models.TransactionBatch{}, err
+			}
+		}
+		return models.TransactionBatch{}, err
+	}
+
+	return batch, nil
+}
+
+// postBatchLeg checks a leg against its source account's limits and the risk
+// rules, to which earlier legs count, and posts it. A leg the rules deny or
+// hold for review is rejected, along with its decision, since a batch cannot
+// wait for approval.
+func (s *TransactionService) postBatchLeg(ctx context.Context, stores repository.Stores, leg models.TransactionRequest, amount decimal.Decimal, batchID *int64) (models.Transaction, *models.RiskDecision, error) {
+	decision, err := s.admitTransfer(ctx, stores, leg, amount)
+	if err != nil {
+		return models.Transaction{}, nil, err
+	}
+
+	if err := mustPostNow(decision); err != nil {
+		return models.Transaction{}, decision, err
+	}
+
+	transaction, err := s.executeTransfer(ctx, stores, leg, amount, batchID)
+	return transaction, decision, err
+}
+
+func (s *TransactionService) replayBatch(ctx context.Context, key, requestHash string) (models.TransactionBatch, error) {
+	transaction, err := s.replay(ctx, key, requestHash)
+	if err != nil {
+		return models.TransactionBatch{}, err
+	}
+
+	if transaction.BatchID == nil {
+		return models.TransactionBatch{}, ErrIdempotencyKeyMismatch
+	}
+
+	return s.GetBatch(ctx, *transaction.BatchID)
+}
+
+func (s *TransactionService) GetBatch(ctx context.Context, id int64) (models.TransactionBatch, error) {
+	batch, err := s.batchStore.GetByID(ctx, id)
+	if err != nil {
+		return models.TransactionBatch{}, err
+	}
+
+	legs, err := s.transactionStore.ListByBatch(ctx, id)
+	if err != nil {
+		return models.TransactionBatch{}, err
+	}
+
+	batch.Legs = make([]models.BatchLegResult, len(legs))
+	for i := range legs {
+		batch.Legs[i] = models.BatchLegResult{Index: i, Transaction: &legs[i]}
+	}
+
+	return batch, nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/currency"
+	"github.com/KaranPal130/transfers-system/internal/fx"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrSameCurrency      = errors.New("source and destination currencies must be different")
+	ErrFXRateUnavailable = errors.New("exchange rate unavailable")
+	ErrFXQuoteExpired    = errors.New("fx quote has expired")
+	ErrFXQuoteUsed       = errors.New("fx quote has already been used")
+	ErrFXQuoteMismatch   = errors.New("fx quote does not match the account currencies")
+)
+
+const DefaultFXQuoteTTL = 30 * time.Second
+
+type FXService struct {
+	rates      fx.RateProvider
+	quoteStore repository.FXQuoteStore
+	quoteTTL   time.Duration
+}
+
+// NewFXService returns a service that quotes rates from rates. A nil rates
+// provider disables currency conversion.
+func NewFXService(rates fx.RateProvider, quoteStore repository.FXQuoteStore, quoteTTL time.Duration) *FXService {
+	return &FXService{
+		rates:      rates,
+		quoteStore: quoteStore,
+		quoteTTL:   quoteTTL,
+	}
+}
+
+// CreateQuote locks the current rate for the currency pair for the quote TTL.
+func (s *FXService) CreateQuote(ctx context.Context, req models.FXQuoteRequest) (models.FXQuote, error) {
+	source, ok := currency.Lookup(req.SourceCurrency)
+	if !ok {
+		return models.FXQuote{}, ErrUnsupportedCurrency
+	}
+
+	dest, ok := currency.Lookup(req.DestinationCurrency)
+	if !ok {
+		return models.FXQuote{}, ErrUnsupportedCurrency
+	}
+
+	if source.Code == dest.Code {
+		return models.FXQuote{}, ErrSameCurrency
+	}
+
+	rate, err := currentRate(ctx, s.rates, source.Code, dest.Code)
+	if err != nil {
+		return models.FXQuote{}, err
+	}
+
+	id, err := newQuoteID()
+	if err != nil {
+		return models.FXQuote{}, err
+	}
+
+	return s.quoteStore.Create(ctx, models.FXQuote{
+		ID:                  id,
+		SourceCurrency:      source.Code,
+		DestinationCurrency: dest.Code,
+		Rate:                rate,
+		ExpiresAt:           time.Now().Add(s.quoteTTL),
+	})
+}
+
+func (s *FXService) GetQuote(ctx context.Context, id string) (models.FXQuote, error) {
+	return s.quoteStore.GetByID(ctx, id)
+}
+
+func currentRate(ctx context.Context, rates fx.RateProvider, from, to string) (decimal.Decimal, error) {
+	if rates == nil {
+		return decimal.Zero, ErrFXUnavailable
+	}
+
+	rate, err := rates.Rate(ctx, from, to)
+	if errors.Is(err, fx.ErrRateUnavailable) {
+		return decimal.Zero, fmt.Errorf("%w: %w", ErrFXRateUnavailable, err)
+	}
+
+	return rate, err
+}
+
+// conversion is the rate applied to a cross-currency transfer and the quote it
+// came from, if any.
+type conversion struct {
+	rate    decimal.Decimal
+	quoteID *string
+}
+
+// resolveConversion picks the rate for a transfer from one currency to
+// another: the quoted rate when quoteID is set, the current rate otherwise. A
+// quote is locked so that only one transfer can use it.
+func resolveConversion(ctx context.Context, stores repository.Stores, rates fx.RateProvider, quoteID, from, to string) (conversion, error) {
+	if quoteID == "" {
+		rate, err := currentRate(ctx, rates, from, to)
+		if err != nil {
+			return conversion{}, err
+		}
+		return conversion{rate: rate}, nil
+	}
+
+	quote, err := stores.FXQuotes.GetByIDForUpdate(ctx, quoteID)
+	if err != nil {
+		return conversion{}, err
+	}
+
+	if quote.SourceCurrency != from || quote.DestinationCurrency != to {
+		return conversion{}, ErrFXQuoteMismatch
+	}
+
+	if quote.TransactionID != nil {
+		return conversion{}, ErrFXQuoteUsed
+	}
+
+	if !time.Now().Before(quote.ExpiresAt) {
+		return conversion{}, ErrFXQuoteExpired
+	}
+
+	return conversion{rate: quote.Rate, quoteID: &quote.ID}, nil
+}
+
+func newQuoteID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "q_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidHoldExpiry  = errors.New("invalid hold expiry")
+	ErrHoldNotActive      = errors.New("hold is no longer active")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the held amount")
+)
+
+const (
+	DefaultHoldTTL = 7 * 24 * time.Hour
+	MaxHoldTTL     = 30 * 24 * time.Hour
+)
+
+type HoldService struct {
+	uow                repository.UnitOfWork
+	holdStore          repository.HoldStore
+	transactionService *TransactionService
+	defaultTTL         time.Duration
+}
+
+// NewHoldService returns a service whose captures are executed as transfers by
+// transactionService. Holds created without an explicit expiry last for
+// defaultTTL.
+func NewHoldService(uow repository.UnitOfWork, holdStore repository.HoldStore, transactionService *TransactionService, defaultTTL time.Duration) *HoldService {
+	return &HoldService{
+		uow:                uow,
+		holdStore:          holdStore,
+		transactionService: transactionService,
+		defaultTTL:         defaultTTL,
+	}
+}
+
+// CreateHold reserves an amount on an account. The account's available
+// balance must cover it; its ledger balance is left untouched.
+func (s *HoldService) CreateHold(ctx context.Context, req models.HoldRequest) (models.Hold, error) {
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil || !amount.IsPositive() {
+		return models.Hold{}, ErrInvalidAmount
+	}
+
+	ttl := s.defaultTTL
+	if req.ExpiresInSeconds != 0 {
+		ttl = time.Duration(req.ExpiresInSeconds) * time.Second
+	}
+
+	if req.ExpiresInSeconds < 0 || ttl > MaxHoldTTL {
+		return models.Hold{}, ErrInvalidHoldExpiry
+	}
+
+	// A hold cannot wait for approval, so one that would need it is turned
+	// down, as are batch legs.
+	if s.transactionService.needsApproval(amount) {
+		return models.Hold{}, ErrApprovalRequired
+	}
+
+	var hold models.Hold
+
+	err = runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		// Holding the account lock serializes this check with transfers and
+		// other holds on the same account.
+		accounts, err := lockAccounts(ctx, stores, req.AccountID)
+		if err != nil {
+			return err
+		}
+
+		account := accounts[req.AccountID]
+
+		if err := checkDebitPermitted(ctx, account.AccountID); err != nil {
+			return err
+		}
+
+		if err := checkCanDebit(account); err != nil {
+			return err
+		}
+
+		if err := checkPrecision(amount, account.Currency); err != nil {
+			return err
+		}
+
+		if account.Headroom.LessThan(amount) {
+			return ErrInsufficientBalance
+		}
+
+		// The hold only counts towards the limits once captured, when they
+		// are checked again, but one over them already is turned down now.
+		if err := s.transactionService.checkLimits(ctx, stores, account, amount); err != nil {
+			return err
+		}
+
+		hold, err = stores.Holds.Create(ctx, models.Hold{
+			AccountID: account.AccountID,
+			Amount:    amount,
+			Currency:  account.Currency,
+			Status:    models.HoldStatusActive,
+			ExpiresAt: time.Now().Add(ttl),
+		})
+		return err
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+func (s *HoldService) GetHold(ctx context.Context, id int64) (models.Hold, error) {
+	hold, err := s.holdStore.GetByID(ctx, id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	// The sweeper may not have run yet.
+	if hold.Status == models.HoldStatusActive && !hold.ExpiresAt.After(time.Now()) {
+		hold.Status = models.HoldStatusExpired
+	}
+
+	return hold, nil
+}
+
+// CaptureHold settles a hold as a transfer to the destination account. An
+// empty amount captures the full hold; a smaller amount releases the rest. A
+// hold can be captured once.
+func (s *HoldService) CaptureHold(ctx context.Context, id int64, req models.HoldCaptureRequest) (models.Transaction, error) {
+	var amount decimal.Decimal
+	if req.Amount != "" {
+		var err error
+		amount, err = decimal.NewFromString(req.Amount)
+		if err != nil || !amount.IsPositive() {
+			return models.Transaction{}, ErrInvalidAmount
+		}
+	}
+
+	var transaction models.Transaction
+	// rejected is the decision on a capture the risk rules turned down, which
+	// is recorded even though the capture is not.
+	var rejected *models.RiskDecision
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		rejected = nil
+
+		hold, err := stores.Holds.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkHoldActive(hold); err != nil {
+			return err
+		}
+
+		if hold.AccountID == req.DestinationAccountID {
+			return ErrSameSourceAndDest
+		}
+
+		captureAmount := hold.Amount
+		if req.Amount != "" {
+			if amount.GreaterThan(hold.Amount) {
+				return ErrCaptureExceedsHold
+			}
+			captureAmount = amount
+		}
+
+		// Holds created before the threshold was lowered may still be
+		// above it.
+		if s.transactionService.needsApproval(captureAmount) {
+			return ErrApprovalRequired
+		}
+
+		// Release the reservation first so that the transfer's funds check
+		// does not count it against itself.
+		hold.Status = models.HoldStatusCaptured
+		hold.CapturedAmount = captureAmount
+		if err := stores.Holds.Update(ctx, hold); err != nil {
+			return err
+		}
+
+		transfer := models.TransactionRequest{
+			SourceAccountID:      hold.AccountID,
+			DestinationAccountID: req.DestinationAccountID,
+			Amount:               captureAmount.String(),
+		}
+		decision, err := s.transactionService.admitTransfer(ctx, stores, transfer, captureAmount)
+		if err != nil {
+			return err
+		}
+
+		// A capture settles at once, so it cannot wait for review.
+		if err := mustPostNow(decision); err != nil {
+			rejected = decision
+			return err
+		}
+
+		transaction, err = s.transactionService.executeTransfer(ctx, stores, transfer, captureAmount, nil)
+		if err != nil {
+			return err
+		}
+
+		if decision != nil {
+			decision.TransactionID = &transaction.ID
+			if _, err := stores.Risk.CreateDecision(ctx, *decision); err != nil {
+				return err
+			}
+		}
+
+		hold.TransactionID = &transaction.ID
+		return stores.Holds.Update(ctx, hold)
+	})
+	if rejected != nil {
+		if err := s.transactionService.recordRejectedDecisions(ctx, []models.RiskDecision{*rejected}); err != nil {
+			return models.Transaction{}, err
+		}
+	}
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// VoidHold releases a hold without moving any money. The caller must be
+// allowed to debit the held account.
+func (s *HoldService) VoidHold(ctx context.Context, id int64) (models.Hold, error) {
+	var hold models.Hold
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		var err error
+		hold, err = stores.Holds.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Voiding frees the held funds, so it takes the same permission as
+		// spending them.
+		if err := checkDebitPermitted(ctx, hold.AccountID); err != nil {
+			return err
+		}
+
+		if err := checkHoldActive(hold); err != nil {
+			return err
+		}
+
+		hold.Status = models.HoldStatusVoided
+		if err := stores.Holds.Update(ctx, hold); err != nil {
+			return err
+		}
+
+		hold, err = stores.Holds.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// ExpireHolds marks holds past their expiry as expired and returns how many
+// were changed. Expired holds stop counting against the available balance as
+// soon as they expire; this only brings their stored status up to date.
+func (s *HoldService) ExpireHolds(ctx context.Context) (int64, error) {
+	return s.holdStore.ExpireDue(ctx)
+}
+
+func checkHoldActive(hold models.Hold) error {
+	if hold.Status == models.HoldStatusExpired ||
+		(hold.Status == models.HoldStatusActive && !hold.ExpiresAt.After(time.Now())) {
+		return ErrHoldExpired
+	}
+
+	if hold.Status != models.HoldStatusActive {
+		return ErrHoldNotActive
+	}
+
+	return nil
+}
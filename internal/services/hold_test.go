@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/KaranPal130/transfers-system/internal/auth"
+	"github.com/KaranPal130/transfers-system/internal/models"
+)
+
+func TestVoidHoldNeedsDebitPermission(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 1, "100")
+	s.createAccount(t, 2, "100")
+
+	holds := NewHoldService(s.db, s.db.Stores().Holds, s.transactions, DefaultHoldTTL)
+
+	hold, err := holds.CreateHold(context.Background(), models.HoldRequest{AccountID: 1, Amount: "40"})
+	if err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+
+	other := auth.NewContext(context.Background(), auth.Principal{ID: "api_key:7", AccountIDs: []int64{2}})
+	if _, err := holds.VoidHold(other, hold.ID); !errors.Is(err, ErrAccountNotPermitted) {
+		t.Fatalf("void by a key restricted to account 2: err = %v, want ErrAccountNotPermitted", err)
+	}
+
+	got, err := holds.GetHold(context.Background(), hold.ID)
+	if err != nil {
+		t.Fatalf("get hold: %v", err)
+	}
+	if got.Status != models.HoldStatusActive {
+		t.Errorf("status = %s after the rejected void, want %s", got.Status, models.HoldStatusActive)
+	}
+
+	owner := auth.NewContext(context.Background(), auth.Principal{ID: "api_key:8", AccountIDs: []int64{1}})
+	voided, err := holds.VoidHold(owner, hold.ID)
+	if err != nil {
+		t.Fatalf("void by a key restricted to account 1: %v", err)
+	}
+	if voided.Status != models.HoldStatusVoided {
+		t.Errorf("status = %s, want %s", voided.Status, models.HoldStatusVoided)
+	}
+}
@@ -0,0 +1,498 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/importer"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidImportKind = errors.New("invalid import kind")
+	ErrInvalidChunkSize  = errors.New("invalid chunk size")
+
+	// errDryRun rolls back a dry run's unit of work.
+	errDryRun = errors.New("dry run")
+)
+
+const (
+	DefaultImportChunkSize = 1000
+	MaxImportChunkSize     = 50000
+)
+
+type ImportOptions struct {
+	Kind      string
+	ChunkSize int
+	// DryRun validates and loads every row in a single unit of work, then
+	// rolls it back.
+	DryRun bool
+	// MaxErrors caps how many row errors the result lists.
+	MaxErrors int
+	// Report, if set, is called with every row error in file order.
+	Report func(models.ImportRowError) error
+}
+
+// ImportService bulk loads accounts and transfers. Every row passes the
+// checks CreateAccount or CreateTransaction would apply; rows that fail are
+// reported and skipped, and the rest are written in chunks through
+// repository.BulkStore.
+type ImportService struct {
+	uow                repository.UnitOfWork
+	transactionService *TransactionService
+}
+
+func NewImportService(uow repository.UnitOfWork, transactionService *TransactionService) *ImportService {
+	return &ImportService{
+		uow:                uow,
+		transactionService: transactionService,
+	}
+}
+
+// importRow is a parsed row ready for the checks that need the database.
+type importRow struct {
+	line      int
+	createdAt time.Time
+
+	account        models.Account
+	initialBalance decimal.Decimal
+
+	transfer models.TransactionRequest
+	amount   decimal.Decimal
+}
+
+// importLoader checks and writes one chunk within a unit of work. It returns
+// how many rows it loaded and the errors of the rest.
+type importLoader func(ctx context.Context, stores repository.Stores, rows []importRow) (int, []models.ImportRowError, error)
+
+func (s *ImportService) Import(ctx context.Context, records importer.Reader, opts ImportOptions) (models.ImportResult, error) {
+	result := models.ImportResult{
+		Kind:   opts.Kind,
+		DryRun: opts.DryRun,
+		Errors: []models.ImportRowError{},
+	}
+
+	if opts.ChunkSize == 0 {
+		opts.ChunkSize = DefaultImportChunkSize
+	}
+	if opts.ChunkSize < 0 || opts.ChunkSize > MaxImportChunkSize {
+		return result, ErrInvalidChunkSize
+	}
+
+	now := time.Now().UTC()
+
+	var parse func(record importer.Record) (importRow, error)
+	var load importLoader
+
+	switch opts.Kind {
+	case models.ImportKindAccounts:
+		seen := make(map[int64]int)
+		parse = func(record importer.Record) (importRow, error) {
+			return parseAccountRow(record, seen, s.transactionService.limits, now)
+		}
+		load = s.loadAccounts
+	case models.ImportKindTransfers:
+		parse = func(record importer.Record) (importRow, error) {
+			return parseTransferRow(record, now)
+		}
+		load = s.loadTransfers
+	default:
+		return result, ErrInvalidImportKind
+	}
+
+	report := func(rowErrors []models.ImportRowError) error {
+		slices.SortFunc(rowErrors, func(a, b models.ImportRowError) int {
+			return a.Line - b.Line
+		})
+
+		for _, rowError := range rowErrors {
+			result.Failed++
+			if len(result.Errors) < opts.MaxErrors {
+				result.Errors = append(result.Errors, rowError)
+			} else {
+				result.ErrorsTruncated = true
+			}
+
+			if opts.Report != nil {
+				if err := opts.Report(rowError); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	// loadChunks reads the whole file, handing each chunk of parsed rows to
+	// run.
+	loadChunks := func(run func(rows []importRow) (int, []models.ImportRowError, error)) error {
+		for {
+			rows, rowErrors, err := readChunk(records, parse, opts.ChunkSize)
+			result.Rows += len(rows) + len(rowErrors)
+			if err != nil && err != io.EOF {
+				return err
+			}
+
+			if len(rows) > 0 {
+				imported, loadErrors, loadErr := run(rows)
+				if loadErr != nil {
+					return loadErr
+				}
+				result.Imported += imported
+				rowErrors = append(rowErrors, loadErrors...)
+			}
+
+			if reportErr := report(rowErrors); reportErr != nil {
+				return reportErr
+			}
+
+			if err == io.EOF {
+				return nil
+			}
+		}
+	}
+
+	if opts.DryRun {
+		err := s.uow.Do(ctx, func(stores repository.Stores) error {
+			err := loadChunks(func(rows []importRow) (int, []models.ImportRowError, error) {
+				return load(ctx, stores, rows)
+			})
+			if err != nil {
+				return err
+			}
+			return errDryRun
+		})
+		if !errors.Is(err, errDryRun) {
+			return result, err
+		}
+		return result, nil
+	}
+
+	err := loadChunks(func(rows []importRow) (int, []models.ImportRowError, error) {
+		var imported int
+		var rowErrors []models.ImportRowError
+
+		err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+			var err error
+			imported, rowErrors, err = load(ctx, stores, rows)
+			return err
+		})
+		return imported, rowErrors, err
+	})
+
+	return result, err
+}
+
+// readChunk parses up to size rows. Rows that fail to parse come back as
+// errors and do not count towards size. The error is io.EOF once the file is
+// exhausted.
+func readChunk(records importer.Reader, parse func(importer.Record) (importRow, error), size int) ([]importRow, []models.ImportRowError, error) {
+	var rows []importRow
+	var rowErrors []models.ImportRowError
+
+	for len(rows) < size {
+		record, err := records.Next()
+		if err != nil {
+			return rows, rowErrors, err
+		}
+
+		if record.Err != nil {
+			rowErrors = append(rowErrors, rowError(record.Line, record.Err))
+			continue
+		}
+
+		row, err := parse(record)
+		if err != nil {
+			rowErrors = append(rowErrors, rowError(record.Line, err))
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+// parseAccountRow applies CreateAccount's checks. seen maps the account IDs
+// met so far to their line, to catch duplicates within the file.
+func parseAccountRow(record importer.Record, seen map[int64]int, limits LimitPolicy, now time.Time) (importRow, error) {
+	row := importRow{line: record.Line}
+
+	accountID, err := int64Field(record, "account_id")
+	if err != nil {
+		return row, err
+	}
+
+	req := models.AccountCreateRequest{
+		AccountID:      accountID,
+		InitialBalance: record.Fields["initial_balance"],
+		Currency:       record.Fields["currency"],
+		OverdraftLimit: record.Fields["overdraft_limit"],
+		Tier:           record.Fields["tier"],
+	}
+
+	row.account, row.initialBalance, err = newAccount(req, limits)
+	if err != nil {
+		return row, err
+	}
+
+	row.createdAt, err = createdAtField(record, now)
+	if err != nil {
+		return row, err
+	}
+
+	if line, ok := seen[accountID]; ok {
+		return row, fmt.Errorf("account_id %d already appears on line %d", accountID, line)
+	}
+	seen[accountID] = record.Line
+
+	row.account.Balance = row.initialBalance
+	row.account.CreatedAt = row.createdAt
+	return row, nil
+}
+
+// parseTransferRow applies the checks CreateTransaction makes before reading
+// any account. FX quotes are short-lived and cannot be imported.
+func parseTransferRow(record importer.Record, now time.Time) (importRow, error) {
+	row := importRow{line: record.Line}
+
+	sourceID, err := int64Field(record, "source_account_id")
+	if err != nil {
+		return row, err
+	}
+
+	destinationID, err := int64Field(record, "destination_account_id")
+	if err != nil {
+		return row, err
+	}
+
+	if record.Fields["fx_quote_id"] != "" {
+		return row, errors.New("fx_quote_id cannot be imported")
+	}
+
+	row.transfer = models.TransactionRequest{
+		SourceAccountID:      sourceID,
+		DestinationAccountID: destinationID,
+		Amount:               record.Fields["amount"],
+		FXMode:               record.Fields["fx_mode"],
+		Description:          record.Fields["description"],
+		ExternalReference:    record.Fields["external_reference"],
+	}
+
+	// Rows are flat, so metadata comes as a JSON object encoded in a string.
+	if metadata := record.Fields["metadata"]; metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &row.transfer.Metadata); err != nil {
+			return row, ErrInvalidMetadata
+		}
+	}
+
+	row.amount, err = validateTransferRequest(row.transfer)
+	if err != nil {
+		return row, err
+	}
+
+	row.createdAt, err = createdAtField(record, now)
+	return row, err
+}
+
+// rowError reports err against a line. Several joined errors are listed on
+// one line.
+func rowError(line int, err error) models.ImportRowError {
+	return models.ImportRowError{Line: line, Error: strings.ReplaceAll(err.Error(), "\n", "; ")}
+}
+
+func int64Field(record importer.Record, name string) (int64, error) {
+	value, ok := record.Fields[name]
+	if !ok || value == "" {
+		return 0, fmt.Errorf("missing %s", name)
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+
+	return n, nil
+}
+
+// createdAtField parses the optional created_at field, which defaults to now
+// and may not lie in the future.
+func createdAtField(record importer.Record, now time.Time) (time.Time, error) {
+	value := record.Fields["created_at"]
+	if value == "" {
+		return now, nil
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, errors.New("invalid created_at")
+	}
+
+	if createdAt.After(now) {
+		return time.Time{}, errors.New("created_at is in the future")
+	}
+
+	return createdAt.UTC(), nil
+}
+
+func (s *ImportService) loadAccounts(ctx context.Context, stores repository.Stores, rows []importRow) (int, []models.ImportRowError, error) {
+	var rowErrors []models.ImportRowError
+	var accounts []models.Account
+	var entries []models.JournalEntry
+
+	for _, row := range rows {
+		_, err := stores.Accounts.GetByID(ctx, row.account.AccountID)
+		if err == nil {
+			rowErrors = append(rowErrors, rowError(row.line, ErrAccountAlreadyExists))
+			continue
+		}
+		if !errors.Is(err, repository.ErrAccountNotFound) {
+			return 0, nil, err
+		}
+
+		accounts = append(accounts, row.account)
+
+		if !row.initialBalance.IsZero() {
+			entries = append(entries, models.JournalEntry{
+				Kind:      models.JournalEntryOpeningBalance,
+				CreatedAt: row.createdAt,
+				Postings:  openingPostings(row.account.AccountID, row.initialBalance, row.account.Currency),
+			})
+		}
+	}
+
+	if err := stores.Bulk.CopyAccounts(ctx, accounts); err != nil {
+		return 0, nil, err
+	}
+
+	if _, err := stores.Bulk.CopyJournalEntries(ctx, entries); err != nil {
+		return 0, nil, err
+	}
+
+	return len(accounts), rowErrors, nil
+}
+
+// loadTransfers locks every account the chunk touches and applies the rows in
+// file order against balances that include the rows before them.
+func (s *ImportService) loadTransfers(ctx context.Context, stores repository.Stores, rows []importRow) (int, []models.ImportRowError, error) {
+	var ids []int64
+	for _, row := range rows {
+		ids = append(ids, row.transfer.SourceAccountID, row.transfer.DestinationAccountID)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	// Locked one at a time, in the same order lockAccounts uses, so that a
+	// missing account fails only the rows that name it.
+	accounts := make(map[int64]models.Account, len(ids))
+	for _, id := range ids {
+		account, err := stores.Accounts.GetByIDForUpdate(ctx, id)
+		if errors.Is(err, repository.ErrAccountNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+
+		account, err = withAvailableBalance(ctx, stores.Holds, account)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		accounts[id] = account
+	}
+
+	var rowErrors []models.ImportRowError
+	var transactions []models.Transaction
+	touched := make(map[int64]bool)
+	// References used by earlier rows of the chunk, which planTransfer
+	// cannot see until the chunk is written.
+	references := make(map[string]int)
+
+	for _, row := range rows {
+		reference := fmt.Sprintf("%d/%s", row.transfer.SourceAccountID, row.transfer.ExternalReference)
+		if line, ok := references[reference]; ok && row.transfer.ExternalReference != "" {
+			rowErrors = append(rowErrors, models.ImportRowError{Line: row.line, Error: fmt.Sprintf("external_reference already appears on line %d", line)})
+			continue
+		}
+
+		transaction, err := s.planImportedTransfer(ctx, stores, accounts, row)
+		if err != nil {
+			rowErrors = append(rowErrors, rowError(row.line, err))
+			continue
+		}
+		references[reference] = row.line
+
+		source := accounts[transaction.SourceAccountID]
+		source.Balance = source.Balance.Sub(transaction.Amount)
+		source.AvailableBalance = source.AvailableBalance.Sub(transaction.Amount)
+		source.Headroom = source.Headroom.Sub(transaction.Amount)
+		accounts[source.AccountID] = source
+
+		destination := accounts[transaction.DestinationAccountID]
+		destination.Balance = destination.Balance.Add(transaction.DestinationAmount)
+		destination.AvailableBalance = destination.AvailableBalance.Add(transaction.DestinationAmount)
+		destination.Headroom = destination.Headroom.Add(transaction.DestinationAmount)
+		accounts[destination.AccountID] = destination
+
+		touched[source.AccountID] = true
+		touched[destination.AccountID] = true
+		transactions = append(transactions, transaction)
+	}
+
+	transactions, err := stores.Bulk.CopyTransactions(ctx, transactions)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	entries := make([]models.JournalEntry, len(transactions))
+	for i := range transactions {
+		entries[i] = models.JournalEntry{
+			Kind:          models.JournalEntryTransfer,
+			TransactionID: &transactions[i].ID,
+			CreatedAt:     transactions[i].CreatedAt,
+			Postings:      transferPostings(transactions[i]),
+		}
+	}
+
+	if _, err := stores.Bulk.CopyJournalEntries(ctx, entries); err != nil {
+		return 0, nil, err
+	}
+
+	for id := range touched {
+		if err := stores.Accounts.UpdateBalance(ctx, id, accounts[id].Balance); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return len(transactions), rowErrors, nil
+}
+
+func (s *ImportService) planImportedTransfer(ctx context.Context, stores repository.Stores, accounts map[int64]models.Account, row importRow) (models.Transaction, error) {
+	for _, id := range []int64{row.transfer.SourceAccountID, row.transfer.DestinationAccountID} {
+		account, ok := accounts[id]
+		if !ok {
+			return models.Transaction{}, fmt.Errorf("account %d not found", id)
+		}
+
+		if row.createdAt.Before(account.CreatedAt) {
+			return models.Transaction{}, fmt.Errorf("created_at is before account %d was created", id)
+		}
+	}
+
+	transaction, err := s.transactionService.planTransfer(ctx, stores, accounts, row.transfer, row.amount)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	transaction.CreatedAt = row.createdAt
+	return transaction, nil
+}
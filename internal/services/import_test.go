@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KaranPal130/transfers-system/internal/importer"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+func (s testServices) importFile(t *testing.T, format, data string, opts ImportOptions) models.ImportResult {
+	t.Helper()
+
+	records, err := importer.NewReader(strings.NewReader(data), format)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+
+	result, err := NewImportService(s.db, s.transactions).Import(context.Background(), records, opts)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	return result
+}
+
+// errorText is err as an import row reports it.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return rowError(0, err).Error
+}
+
+func TestImportedAccountsAreCheckedLikeCreateAccount(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.AccountCreateRequest
+		ok   bool
+	}{
+		{"valid", models.AccountCreateRequest{AccountID: 10, InitialBalance: "100", Currency: "EUR", OverdraftLimit: "50"}, true},
+		{"default currency", models.AccountCreateRequest{AccountID: 10, InitialBalance: "0"}, true},
+		{"system account", models.AccountCreateRequest{AccountID: models.SystemAccountID, InitialBalance: "1"}, false},
+		{"negative balance", models.AccountCreateRequest{AccountID: 10, InitialBalance: "-5"}, false},
+		{"unparsable balance", models.AccountCreateRequest{AccountID: 10, InitialBalance: "ten"}, false},
+		{"unsupported currency", models.AccountCreateRequest{AccountID: 10, InitialBalance: "5", Currency: "XYZ"}, false},
+		{"too precise", models.AccountCreateRequest{AccountID: 10, InitialBalance: "1.5", Currency: "JPY"}, false},
+		{"bad overdraft", models.AccountCreateRequest{AccountID: 10, InitialBalance: "5", OverdraftLimit: "-1"}, false},
+		{"unknown tier", models.AccountCreateRequest{AccountID: 10, InitialBalance: "5", Tier: "gold"}, false},
+		{"several errors", models.AccountCreateRequest{AccountID: 10, InitialBalance: "-5", Tier: "gold"}, false},
+		{"existing account", models.AccountCreateRequest{AccountID: 1, InitialBalance: "5"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := newTestServices(t)
+			created.createAccount(t, 1, "0")
+			want := errorText(created.accounts.CreateAccount(context.Background(), tt.req))
+			if (want == "") != tt.ok {
+				t.Fatalf("CreateAccount error = %q, want success %t", want, tt.ok)
+			}
+
+			imported := newTestServices(t)
+			imported.createAccount(t, 1, "0")
+			data := fmt.Sprintf("account_id,initial_balance,currency,overdraft_limit,tier\n%d,%s,%s,%s,%s\n",
+				tt.req.AccountID, tt.req.InitialBalance, tt.req.Currency, tt.req.OverdraftLimit, tt.req.Tier)
+			result := imported.importFile(t, importer.FormatCSV, data, ImportOptions{Kind: models.ImportKindAccounts, MaxErrors: 10})
+
+			var got string
+			if len(result.Errors) > 0 {
+				got = result.Errors[0].Error
+			}
+			if got != want {
+				t.Errorf("import error = %q, CreateAccount error = %q", got, want)
+			}
+
+			if want != "" {
+				return
+			}
+			if result.Imported != 1 {
+				t.Fatalf("imported %d rows, want 1", result.Imported)
+			}
+
+			// The imported account matches the created one.
+			wantAccount, err := created.db.Stores().Accounts.GetByID(context.Background(), tt.req.AccountID)
+			if err != nil {
+				t.Fatalf("get created account: %v", err)
+			}
+			gotAccount, err := imported.db.Stores().Accounts.GetByID(context.Background(), tt.req.AccountID)
+			if err != nil {
+				t.Fatalf("get imported account: %v", err)
+			}
+			if gotAccount.Currency != wantAccount.Currency || !gotAccount.OverdraftLimit.Equal(wantAccount.OverdraftLimit) || gotAccount.Tier != wantAccount.Tier {
+				t.Errorf("imported account = %+v, want %+v", gotAccount, wantAccount)
+			}
+			if got, want := imported.balance(t, tt.req.AccountID), created.balance(t, tt.req.AccountID); !got.Equal(want) {
+				t.Errorf("imported balance = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestImportedTransfersAreCheckedLikeCreateTransaction(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.TransactionRequest
+		ok   bool
+		// importErr is the row error when it words the failure differently
+		// from CreateTransaction.
+		importErr string
+	}{
+		{name: "valid", req: models.TransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "40", Description: "Rent", ExternalReference: "inv-2"}, ok: true},
+		{name: "whole balance", req: transfer(1, 2, "90"), ok: true},
+		{name: "insufficient balance", req: transfer(1, 2, "90.01")},
+		{name: "same account", req: transfer(1, 1, "5")},
+		{name: "zero amount", req: transfer(1, 2, "0")},
+		{name: "unparsable amount", req: transfer(1, 2, "five")},
+		{name: "too precise", req: transfer(1, 2, "0.001")},
+		{name: "currency mismatch", req: transfer(1, 3, "5")},
+		{name: "bad fx mode", req: models.TransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "5", FXMode: "swap"}},
+		{name: "duplicate reference", req: models.TransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "5", ExternalReference: "inv-1"}},
+		{name: "several errors", req: transfer(1, 1, "-5")},
+		{name: "unknown account", req: transfer(1, 9, "5"), importErr: "account 9 not found"},
+	}
+
+	setup := func(t *testing.T) testServices {
+		s := newTestServices(t)
+		s.createAccount(t, 1, "100")
+		s.createAccount(t, 2, "0")
+		if err := s.accounts.CreateAccount(context.Background(), models.AccountCreateRequest{AccountID: 3, InitialBalance: "0", Currency: "EUR"}); err != nil {
+			t.Fatalf("create account 3: %v", err)
+		}
+
+		req := transfer(1, 2, "10")
+		req.ExternalReference = "inv-1"
+		if _, err := s.transactions.CreateTransaction(context.Background(), req, ""); err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+		return s
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := setup(t)
+			_, err := created.transactions.CreateTransaction(context.Background(), tt.req, "")
+			want := errorText(err)
+			if (err == nil) != tt.ok {
+				t.Fatalf("CreateTransaction error = %v, want success %t", err, tt.ok)
+			}
+			if tt.importErr != "" {
+				want = tt.importErr
+			}
+
+			imported := setup(t)
+			data := fmt.Sprintf("source_account_id,destination_account_id,amount,fx_mode,description,external_reference\n%d,%d,%s,%s,%s,%s\n",
+				tt.req.SourceAccountID, tt.req.DestinationAccountID, tt.req.Amount, tt.req.FXMode, tt.req.Description, tt.req.ExternalReference)
+			result := imported.importFile(t, importer.FormatCSV, data, ImportOptions{Kind: models.ImportKindTransfers, MaxErrors: 10})
+
+			var got string
+			if len(result.Errors) > 0 {
+				got = result.Errors[0].Error
+			}
+			if got != want {
+				t.Errorf("import error = %q, CreateTransaction error = %q", got, want)
+			}
+
+			for _, id := range []int64{1, 2, 3} {
+				if got, want := imported.balance(t, id), created.balance(t, id); !got.Equal(want) {
+					t.Errorf("account %d: imported balance = %s, want %s", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestImportReportsRowErrors(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 9, "0")
+
+	// Line 5 holds a quoted field that runs onto line 6.
+	data := strings.Join([]string{
+		"account_id,initial_balance",
+		"1,10",
+		"x,10",
+		"2,5\"0",
+		"3,\"10",
+		"\"",
+		"1,20",
+		"4,-1",
+		"5,1",
+		"9,1",
+	}, "\n")
+
+	var reported []models.ImportRowError
+	result := s.importFile(t, importer.FormatCSV, data, ImportOptions{
+		Kind:      models.ImportKindAccounts,
+		ChunkSize: 2,
+		MaxErrors: 2,
+		Report: func(rowError models.ImportRowError) error {
+			reported = append(reported, rowError)
+			return nil
+		},
+	})
+
+	want := []models.ImportRowError{
+		{Line: 3, Error: "invalid account_id"},
+		{Line: 4, Error: `bare " in non-quoted-field`},
+		{Line: 7, Error: "account_id 1 already appears on line 2"},
+		{Line: 8, Error: ErrInvalidInitialBalance.Error()},
+		{Line: 10, Error: ErrAccountAlreadyExists.Error()},
+	}
+
+	if len(reported) != len(want) {
+		t.Fatalf("reported %+v, want %+v", reported, want)
+	}
+	for i := range want {
+		if reported[i] != want[i] {
+			t.Errorf("reported error %d = %+v, want %+v", i, reported[i], want[i])
+		}
+	}
+
+	if result.Rows != 8 || result.Imported != 3 || result.Failed != 5 {
+		t.Errorf("rows, imported, failed = %d, %d, %d, want 8, 3, 5", result.Rows, result.Imported, result.Failed)
+	}
+	if len(result.Errors) != 2 || result.Errors[0] != want[0] || result.Errors[1] != want[1] || !result.ErrorsTruncated {
+		t.Errorf("errors = %+v (truncated %t), want the first two, truncated", result.Errors, result.ErrorsTruncated)
+	}
+
+	for _, id := range []int64{1, 3, 5} {
+		if got := s.balance(t, id); got.IsZero() {
+			t.Errorf("account %d was not imported", id)
+		}
+	}
+}
+
+func TestImportDryRunWritesNothing(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 1, "100")
+	s.createAccount(t, 2, "0")
+	ctx := context.Background()
+
+	accounts := `{"account_id": 10, "initial_balance": "5"}
+{"account_id": 11, "initial_balance": "7"}
+{"account_id": 12, "initial_balance": "-7"}
+`
+	result := s.importFile(t, importer.FormatJSONL, accounts, ImportOptions{Kind: models.ImportKindAccounts, DryRun: true, MaxErrors: 10})
+	if !result.DryRun || result.Imported != 2 || result.Failed != 1 {
+		t.Errorf("accounts result = %+v, want 2 imported and 1 failed in a dry run", result)
+	}
+	for _, id := range []int64{10, 11} {
+		if _, err := s.db.Stores().Accounts.GetByID(ctx, id); !errors.Is(err, repository.ErrAccountNotFound) {
+			t.Errorf("account %d after a dry run: err = %v, want ErrAccountNotFound", id, err)
+		}
+	}
+
+	// The second transfer fails against the balance the first would leave.
+	transfers := `{"source_account_id": 1, "destination_account_id": 2, "amount": "60"}
+{"source_account_id": 1, "destination_account_id": 2, "amount": "60"}
+`
+	result = s.importFile(t, importer.FormatJSONL, transfers, ImportOptions{Kind: models.ImportKindTransfers, DryRun: true, MaxErrors: 10})
+	if result.Imported != 1 || result.Failed != 1 || result.Errors[0].Line != 2 {
+		t.Errorf("transfers result = %+v, want line 2 to fail", result)
+	}
+	if got := s.balance(t, 1); got.String() != "100" {
+		t.Errorf("source balance after a dry run = %s, want 100", got)
+	}
+	if got := s.balance(t, 2); !got.IsZero() {
+		t.Errorf("destination balance after a dry run = %s, want 0", got)
+	}
+
+	transactions, err := s.db.Stores().Transactions.ListByAccount(ctx, 1, 10, 0)
+	if err != nil {
+		t.Fatalf("list transactions: %v", err)
+	}
+	if len(transactions) != 0 {
+		t.Errorf("transactions after a dry run = %+v, want none", transactions)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/KaranPal130/transfers-system/internal/currency"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrUnbalancedEntry = errors.New("journal entry postings do not sum to zero in every currency")
+)
+
+// lockAccounts takes row locks on the given accounts in ascending account ID
+// order and fills in their available balance. Every code path that locks more
+// than one account must go through here so that concurrent transfers in
+// opposite directions cannot deadlock.
+func lockAccounts(ctx context.Context, stores repository.Stores, accountIDs ...int64) (map[int64]models.Account, error) {
+	ids := slices.Clone(accountIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	accounts := make(map[int64]models.Account, len(ids))
+	for _, id := range ids {
+		account, err := stores.Accounts.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		// Read after the lock so that holds committed while waiting count.
+		account, err = withAvailableBalance(ctx, stores.Holds, account)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
+
+// withAvailableBalance sets the account's available balance, its ledger
+// balance less what active holds reserve, and the headroom that the overdraft
+// limit adds on top. Debits are checked against the headroom.
+func withAvailableBalance(ctx context.Context, holdStore repository.HoldStore, account models.Account) (models.Account, error) {
+	held, err := holdStore.SumActive(ctx, account.AccountID)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	account.AvailableBalance = account.Balance.Sub(held)
+	account.Headroom = account.AvailableBalance.Add(account.OverdraftLimit)
+	return account, nil
+}
+
+// postJournalEntry records a balanced journal entry and applies each of its
+// postings to the cached balance of the posted account. stores must be bound
+// to the caller's unit of work. accounts must contain every non-system account
+// the entry touches, already locked by the caller, and is updated in place
+// with the new balances.
+func postJournalEntry(
+	ctx context.Context,
+	stores repository.Stores,
+	accounts map[int64]models.Account,
+	entry models.JournalEntry,
+) (models.JournalEntry, error) {
+	sums := make(map[string]decimal.Decimal)
+	for _, posting := range entry.Postings {
+		sums[posting.Currency] = sums[posting.Currency].Add(posting.Amount)
+	}
+
+	for _, sum := range sums {
+		if !sum.IsZero() {
+			return models.JournalEntry{}, ErrUnbalancedEntry
+		}
+	}
+
+	entry, err := stores.Journal.CreateEntry(ctx, entry)
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	for _, posting := range entry.Postings {
+		if posting.AccountID == models.SystemAccountID {
+			continue
+		}
+
+		account, ok := accounts[posting.AccountID]
+		if !ok {
+			return models.JournalEntry{}, fmt.Errorf("posting to account %d that was not locked", posting.AccountID)
+		}
+
+		if posting.Currency != account.Currency {
+			return models.JournalEntry{}, fmt.Errorf("posting in %s to %s account %d", posting.Currency, account.Currency, account.AccountID)
+		}
+
+		account.Balance = account.Balance.Add(posting.Amount)
+		if err := stores.Accounts.UpdateBalance(ctx, account.AccountID, account.Balance); err != nil {
+			return models.JournalEntry{}, err
+		}
+		accounts[account.AccountID] = account
+	}
+
+	return entry, nil
+}
+
+// transferPostings returns the postings that move a transaction's money. A
+// cross-currency transfer passes through the system account in both
+// currencies, so that each currency balances on its own.
+func transferPostings(transaction models.Transaction) []models.Posting {
+	if transaction.Currency == transaction.DestinationCurrency {
+		return []models.Posting{
+			{AccountID: transaction.SourceAccountID, Amount: transaction.Amount.Neg(), Currency: transaction.Currency},
+			{AccountID: transaction.DestinationAccountID, Amount: transaction.Amount, Currency: transaction.Currency},
+		}
+	}
+
+	return []models.Posting{
+		{AccountID: transaction.SourceAccountID, Amount: transaction.Amount.Neg(), Currency: transaction.Currency},
+		{AccountID: models.SystemAccountID, Amount: transaction.Amount, Currency: transaction.Currency},
+		{AccountID: models.SystemAccountID, Amount: transaction.DestinationAmount.Neg(), Currency: transaction.DestinationCurrency},
+		{AccountID: transaction.DestinationAccountID, Amount: transaction.DestinationAmount, Currency: transaction.DestinationCurrency},
+	}
+}
+
+// checkPrecision rejects amounts with more decimal places than the currency's
+// minor units.
+func checkPrecision(amount decimal.Decimal, currencyCode string) error {
+	cur, ok := currency.Lookup(currencyCode)
+	if !ok {
+		return ErrUnsupportedCurrency
+	}
+
+	if !cur.Fits(amount) {
+		return ErrAmountPrecision
+	}
+
+	return nil
+}
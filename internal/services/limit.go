@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/currency"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrLimitExceeded         = errors.New("transfer limit exceeded")
+	ErrInvalidTransferLimits = errors.New("invalid transfer limits")
+	ErrInvalidTier           = errors.New("unknown account tier")
+	ErrTierCurrency          = errors.New("account tier has no limits in the account's currency")
+
+	// ErrAmountLimitExceeded is the limit exceeded by a transfer too large to
+	// ever be made, as opposed to one that can be retried once the limit has
+	// room again.
+	ErrAmountLimitExceeded = fmt.Errorf("%w: amount above the maximum", ErrLimitExceeded)
+)
+
+const MaxTierLength = 32
+
+// Names of the limits, as in models.TransferLimits.
+const (
+	LimitMaxAmount       = "max_amount"
+	LimitDailyOutbound   = "daily_outbound"
+	LimitMonthlyOutbound = "monthly_outbound"
+	LimitMaxCount        = "max_count"
+)
+
+// LimitError turns down a transfer that would take its source account past
+// one of its limits.
+type LimitError struct {
+	Limit string
+	// RetryAt is when the limit has room again, if waiting can help.
+	RetryAt *time.Time
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("transfer would exceed the account's %s limit", e.Limit)
+}
+
+func (e *LimitError) Unwrap() error {
+	if e.Limit == LimitMaxAmount {
+		return ErrAmountLimitExceeded
+	}
+	return ErrLimitExceeded
+}
+
+// LimitPolicy holds the transfer limits of each account tier, by currency. The
+// standard tier always exists, without limits unless configured.
+type LimitPolicy struct {
+	Tiers map[string]map[string]models.TransferLimits `json:"tiers"`
+}
+
+// LoadLimitPolicy reads a JSON tier table such as
+//
+//	{"tiers": {"standard": {
+//	  "USD": {"max_amount": "10000", "daily_outbound": "25000",
+//	    "max_count": 20, "count_window_seconds": 3600},
+//	  "EUR": {"max_amount": "9000", "daily_outbound": "22000",
+//	    "max_count": 20, "count_window_seconds": 3600}}}}
+//
+// Amounts are not converted between currencies, so a tier lists its limits
+// for each currency it takes accounts in, and only accounts in those
+// currencies can be put in it. Accounts already in a tier when it stops
+// listing their currency are left with only their own limits.
+func LoadLimitPolicy(path string) (LimitPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LimitPolicy{}, err
+	}
+
+	var policy LimitPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return LimitPolicy{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	for tier, byCurrency := range policy.Tiers {
+		if !validTier(tier) {
+			return LimitPolicy{}, fmt.Errorf("%w: %q", ErrInvalidTier, tier)
+		}
+		if len(byCurrency) == 0 {
+			return LimitPolicy{}, fmt.Errorf("tier %s: no currencies", tier)
+		}
+
+		// Accounts store currency codes in upper case.
+		normalized := make(map[string]models.TransferLimits, len(byCurrency))
+		for code, limits := range byCurrency {
+			cur, ok := currency.Lookup(code)
+			if !ok {
+				return LimitPolicy{}, fmt.Errorf("tier %s: %w: %q", tier, ErrUnsupportedCurrency, code)
+			}
+			if _, ok := normalized[cur.Code]; ok {
+				return LimitPolicy{}, fmt.Errorf("tier %s: %s listed twice", tier, cur.Code)
+			}
+			if err := validateLimits(limits, cur); err != nil {
+				return LimitPolicy{}, fmt.Errorf("tier %s %s: %w", tier, cur.Code, err)
+			}
+			normalized[cur.Code] = limits
+		}
+		policy.Tiers[tier] = normalized
+	}
+
+	return policy, nil
+}
+
+func validTier(tier string) bool {
+	return tier != "" && validText(tier, MaxTierLength)
+}
+
+func (p LimitPolicy) hasTier(tier string) bool {
+	_, ok := p.Tiers[tier]
+	return ok || tier == models.DefaultAccountTier
+}
+
+// coversCurrency reports whether the tier has limits in the currency, so
+// that accounts in it can be put in the tier. An unconfigured standard tier
+// covers every currency, without limits.
+func (p LimitPolicy) coversCurrency(tier, currency string) bool {
+	byCurrency, ok := p.Tiers[tier]
+	if !ok {
+		return tier == models.DefaultAccountTier
+	}
+
+	_, ok = byCurrency[currency]
+	return ok
+}
+
+// limitsOf returns the limits in force on the account: its own where it has
+// them, its tier's in its currency otherwise. The count limit and its window
+// go together.
+func (p LimitPolicy) limitsOf(tier, currency string, overrides models.TransferLimits) models.TransferLimits {
+	limits := p.Tiers[tier][currency]
+
+	if overrides.MaxAmount != nil {
+		limits.MaxAmount = overrides.MaxAmount
+	}
+	if overrides.DailyOutbound != nil {
+		limits.DailyOutbound = overrides.DailyOutbound
+	}
+	if overrides.MonthlyOutbound != nil {
+		limits.MonthlyOutbound = overrides.MonthlyOutbound
+	}
+	if overrides.MaxCount != nil {
+		limits.MaxCount = overrides.MaxCount
+		limits.CountWindowSeconds = overrides.CountWindowSeconds
+	}
+
+	return limits
+}
+
+func validateLimits(limits models.TransferLimits, cur currency.Currency) error {
+	var errs []error
+
+	amounts := []struct {
+		field string
+		limit *decimal.Decimal
+	}{
+		{LimitMaxAmount, limits.MaxAmount},
+		{LimitDailyOutbound, limits.DailyOutbound},
+		{LimitMonthlyOutbound, limits.MonthlyOutbound},
+	}
+	for _, amount := range amounts {
+		switch {
+		case amount.limit == nil:
+		case amount.limit.IsNegative():
+			errs = append(errs, &FieldError{Field: amount.field, Err: ErrInvalidTransferLimits})
+		case !cur.Fits(*amount.limit):
+			errs = append(errs, &FieldError{Field: amount.field, Err: ErrAmountPrecision})
+		}
+	}
+
+	if err := validateCountLimit(limits); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateCountLimit checks that a count limit comes with a window to count
+// over.
+func validateCountLimit(limits models.TransferLimits) error {
+	if limits.MaxCount == nil && limits.CountWindowSeconds == nil {
+		return nil
+	}
+
+	if limits.MaxCount == nil || *limits.MaxCount < 0 {
+		return &FieldError{Field: LimitMaxCount, Err: ErrInvalidTransferLimits}
+	}
+	if limits.CountWindowSeconds == nil || *limits.CountWindowSeconds <= 0 {
+		return &FieldError{Field: "count_window_seconds", Err: ErrInvalidTransferLimits}
+	}
+
+	return nil
+}
+
+// admitTransfer locks the transfer's accounts and runs the checks that every
+// transfer a caller makes must pass, however it is made, before it is posted
+// or held for approval: the source account's limits, then the risk rules.
+// The decision is nil when no rules are loaded; the caller acts on it and
+// records it. Only transfers the system makes itself, such as account-closing
+// sweeps, go around it.
+func (s *TransactionService) admitTransfer(ctx context.Context, stores repository.Stores, req models.TransactionRequest, amount decimal.Decimal) (*models.RiskDecision, error) {
+	accounts, err := lockAccounts(ctx, stores, req.SourceAccountID, req.DestinationAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	source := accounts[req.SourceAccountID]
+	if err := s.checkLimits(ctx, stores, source, amount); err != nil {
+		return nil, err
+	}
+
+	return s.screen(ctx, stores, source, req, amount)
+}
+
+// checkLimits turns a transfer down if it would take the source account past
+// one of its limits. The account must be locked: transfers from it then wait
+// on the lock, so two of them cannot both take the last of a limit.
+func (s *TransactionService) checkLimits(ctx context.Context, stores repository.Stores, source models.Account, amount decimal.Decimal) error {
+	usage, err := s.accountLimits(ctx, stores, source)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case usage.MaxAmount != nil && amount.GreaterThan(*usage.MaxAmount):
+		return &LimitError{Limit: LimitMaxAmount}
+	case usage.Daily != nil && amount.GreaterThan(usage.Daily.Remaining):
+		return &LimitError{Limit: LimitDailyOutbound, RetryAt: &usage.Daily.ResetsAt}
+	case usage.Monthly != nil && amount.GreaterThan(usage.Monthly.Remaining):
+		return &LimitError{Limit: LimitMonthlyOutbound, RetryAt: &usage.Monthly.ResetsAt}
+	case usage.Count != nil && usage.Count.Remaining < 1:
+		return &LimitError{Limit: LimitMaxCount, RetryAt: usage.Count.ResetsAt}
+	}
+
+	return nil
+}
+
+// accountLimits returns the limits in force on the account and what is left
+// of them. Daily and monthly totals run over UTC calendar periods, the count
+// over a rolling window.
+func (s *TransactionService) accountLimits(ctx context.Context, stores repository.Stores, account models.Account) (models.AccountLimits, error) {
+	overrides, err := stores.Accounts.GetLimits(ctx, account.AccountID)
+	if err != nil {
+		return models.AccountLimits{}, err
+	}
+
+	limits := s.limits.limitsOf(account.Tier, account.Currency, overrides)
+
+	usage := models.AccountLimits{
+		AccountID: account.AccountID,
+		Tier:      account.Tier,
+		Currency:  account.Currency,
+		Overrides: overrides,
+		MaxAmount: limits.MaxAmount,
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	if limits.DailyOutbound != nil {
+		usage.Daily, err = outboundUsage(ctx, stores.Transactions, account.AccountID, *limits.DailyOutbound, today, today.AddDate(0, 0, 1))
+		if err != nil {
+			return models.AccountLimits{}, err
+		}
+	}
+
+	if limits.MonthlyOutbound != nil {
+		month := today.AddDate(0, 0, 1-today.Day())
+		usage.Monthly, err = outboundUsage(ctx, stores.Transactions, account.AccountID, *limits.MonthlyOutbound, month, month.AddDate(0, 1, 0))
+		if err != nil {
+			return models.AccountLimits{}, err
+		}
+	}
+
+	if limits.MaxCount != nil {
+		window := time.Duration(*limits.CountWindowSeconds) * time.Second
+
+		totals, err := stores.Transactions.SumOutbound(ctx, account.AccountID, now.Add(-window))
+		if err != nil {
+			return models.AccountLimits{}, err
+		}
+
+		usage.Count = &models.CountLimitUsage{
+			Limit:         *limits.MaxCount,
+			Used:          totals.Count,
+			Remaining:     max(*limits.MaxCount-totals.Count, 0),
+			WindowSeconds: *limits.CountWindowSeconds,
+		}
+		if totals.Earliest != nil {
+			resetsAt := totals.Earliest.Add(window)
+			usage.Count.ResetsAt = &resetsAt
+		}
+	}
+
+	return usage, nil
+}
+
+func outboundUsage(ctx context.Context, transactions repository.TransactionStore, accountID int64, limit decimal.Decimal, since, resetsAt time.Time) (*models.OutboundLimitUsage, error) {
+	totals, err := transactions.SumOutbound(ctx, accountID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OutboundLimitUsage{
+		Limit:     limit,
+		Used:      totals.Amount,
+		Remaining: decimal.Max(limit.Sub(totals.Amount), decimal.Zero),
+		ResetsAt:  resetsAt,
+	}, nil
+}
+
+// GetAccountLimits returns the limits in force on the account and the
+// headroom left under each.
+func (s *TransactionService) GetAccountLimits(ctx context.Context, accountID int64) (models.AccountLimits, error) {
+	var usage models.AccountLimits
+
+	err := s.uow.Do(ctx, func(stores repository.Stores) error {
+		account, err := stores.Accounts.GetByID(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		usage, err = s.accountLimits(ctx, stores, account)
+		return err
+	})
+	if err != nil {
+		return models.AccountLimits{}, err
+	}
+
+	return usage, nil
+}
+
+// UpdateAccountLimits replaces the account's own limits. Lowering a limit
+// below what the account has already sent only blocks further transfers.
+func (s *TransactionService) UpdateAccountLimits(ctx context.Context, accountID int64, req models.AccountLimitsRequest) (models.AccountLimits, error) {
+	var usage models.AccountLimits
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		account, err := stores.Accounts.GetByIDForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		cur, ok := currency.Lookup(account.Currency)
+		if !ok {
+			return ErrUnsupportedCurrency
+		}
+
+		limits, err := parseLimits(req, cur)
+		if err != nil {
+			return err
+		}
+
+		if err := stores.Accounts.SetLimits(ctx, accountID, limits); err != nil {
+			return err
+		}
+
+		usage, err = s.accountLimits(ctx, stores, account)
+		return err
+	})
+	if err != nil {
+		return models.AccountLimits{}, err
+	}
+
+	return usage, nil
+}
+
+func parseLimits(req models.AccountLimitsRequest, cur currency.Currency) (models.TransferLimits, error) {
+	var errs []error
+
+	parse := func(field, value string) *decimal.Decimal {
+		if value == "" {
+			return nil
+		}
+
+		limit, err := decimal.NewFromString(value)
+		if err != nil || limit.IsNegative() {
+			errs = append(errs, &FieldError{Field: field, Err: ErrInvalidTransferLimits})
+			return nil
+		}
+		if !cur.Fits(limit) {
+			errs = append(errs, &FieldError{Field: field, Err: ErrAmountPrecision})
+			return nil
+		}
+
+		return &limit
+	}
+
+	limits := models.TransferLimits{
+		MaxAmount:          parse(LimitMaxAmount, req.MaxAmount),
+		DailyOutbound:      parse(LimitDailyOutbound, req.DailyOutbound),
+		MonthlyOutbound:    parse(LimitMonthlyOutbound, req.MonthlyOutbound),
+		MaxCount:           req.MaxCount,
+		CountWindowSeconds: req.CountWindowSeconds,
+	}
+
+	if err := validateCountLimit(limits); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return models.TransferLimits{}, err
+	}
+
+	return limits, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+)
+
+func writeLimits(t *testing.T, body string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "limits.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLimitPolicyRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{name: "amounts without a currency", body: `{"tiers": {"standard": {"max_amount": "100"}}}`},
+		{name: "unknown currency", body: `{"tiers": {"standard": {"XXX": {"max_amount": "100"}}}}`, wantErr: ErrUnsupportedCurrency},
+		{name: "currency listed twice", body: `{"tiers": {"standard": {"USD": {}, "usd": {}}}}`},
+		{name: "no currencies", body: `{"tiers": {"premium": {}}}`},
+		{name: "too precise for the currency", body: `{"tiers": {"standard": {"JPY": {"max_amount": "100.5"}}}}`, wantErr: ErrAmountPrecision},
+		{name: "negative", body: `{"tiers": {"standard": {"USD": {"daily_outbound": "-1"}}}}`, wantErr: ErrInvalidTransferLimits},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadLimitPolicy(writeLimits(t, tt.body))
+			if err == nil {
+				t.Fatal("loaded an invalid policy")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTierLimitsApplyInTheirCurrency(t *testing.T) {
+	policy, err := LoadLimitPolicy(writeLimits(t, `{"tiers": {
+		"standard": {"USD": {"max_amount": "50"}, "jpy": {"max_amount": "5000"}},
+		"premium": {"USD": {}}}}`))
+	if err != nil {
+		t.Fatalf("LoadLimitPolicy: %v", err)
+	}
+
+	s := newTestServices(t)
+	s.transactions.limits = policy
+	ctx := context.Background()
+
+	create := func(id int64, currency, tier string) error {
+		return s.accounts.CreateAccount(ctx, models.AccountCreateRequest{AccountID: id, InitialBalance: "100000", Currency: currency, Tier: tier})
+	}
+
+	for id, currency := range map[int64]string{1: "USD", 2: "USD", 3: "JPY", 4: "JPY"} {
+		if err := create(id, currency, ""); err != nil {
+			t.Fatalf("create %s account %d: %v", currency, id, err)
+		}
+	}
+
+	// 60 is over the USD limit, but well within the JPY one.
+	if _, err := s.transactions.CreateTransaction(ctx, transfer(1, 2, "60"), ""); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("USD transfer of 60: err = %v, want ErrLimitExceeded", err)
+	}
+	if _, err := s.transactions.CreateTransaction(ctx, transfer(3, 4, "60"), ""); err != nil {
+		t.Errorf("JPY transfer of 60: %v", err)
+	}
+
+	// Tiers only take accounts in the currencies they have limits in.
+	if err := create(5, "EUR", ""); !errors.Is(err, ErrTierCurrency) {
+		t.Errorf("EUR account in standard: err = %v, want ErrTierCurrency", err)
+	}
+	if _, err := s.accounts.UpdateTier(ctx, 3, models.AccountTierRequest{Tier: "premium"}); !errors.Is(err, ErrTierCurrency) {
+		t.Errorf("JPY account to premium: err = %v, want ErrTierCurrency", err)
+	}
+	if _, err := s.accounts.UpdateTier(ctx, 1, models.AccountTierRequest{Tier: "premium"}); err != nil {
+		t.Errorf("USD account to premium: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+const (
+	maxTxAttempts = 5
+	baseTxBackoff = 10 * time.Millisecond
+	maxTxBackoff  = 250 * time.Millisecond
+)
+
+// runInTx runs fn as a single unit of work. When the backend aborts it because
+// of a deadlock or a serialization failure, the whole unit of work is retried
+// with exponential backoff and jitter, up to maxTxAttempts times. fn must
+// therefore be safe to run more than once.
+func runInTx(ctx context.Context, uow repository.UnitOfWork, fn func(stores repository.Stores) error) error {
+	backoff := baseTxBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := uow.Do(ctx, fn)
+		if err == nil || !errors.Is(err, repository.ErrTxConflict) || attempt == maxTxAttempts {
+			return err
+		}
+
+		sleep := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sleep):
+		}
+
+		backoff = min(backoff*2, maxTxBackoff)
+	}
+}
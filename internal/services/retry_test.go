@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+// conflictingUoW aborts its first conflicts units of work with ErrTxConflict.
+type conflictingUoW struct {
+	conflicts int
+	attempts  int
+}
+
+func (u *conflictingUoW) Do(ctx context.Context, fn func(stores repository.Stores) error) error {
+	u.attempts++
+	if err := fn(repository.Stores{}); err != nil {
+		return err
+	}
+	if u.attempts <= u.conflicts {
+		return repository.ErrTxConflict
+	}
+	return nil
+}
+
+func TestRunInTx(t *testing.T) {
+	otherErr := errors.New("other")
+
+	tests := []struct {
+		name         string
+		conflicts    int
+		fnErr        error
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "no conflict", wantAttempts: 1},
+		{name: "retried until it succeeds", conflicts: 2, wantAttempts: 3},
+		{name: "gives up after maxTxAttempts", conflicts: maxTxAttempts, wantErr: repository.ErrTxConflict, wantAttempts: maxTxAttempts},
+		{name: "other errors are not retried", conflicts: 2, fnErr: otherErr, wantErr: otherErr, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uow := &conflictingUoW{conflicts: tt.conflicts}
+			calls := 0
+
+			err := runInTx(context.Background(), uow, func(repository.Stores) error {
+				calls++
+				return tt.fnErr
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantAttempts {
+				t.Errorf("fn ran %d times, want %d", calls, tt.wantAttempts)
+			}
+		})
+	}
+}
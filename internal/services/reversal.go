@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/KaranPal130/transfers-system/internal/currency"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidReasonCode       = errors.New("invalid reason code")
+	ErrReversalNotAllowed      = errors.New("reversals cannot be reversed")
+	ErrReversalExceedsOriginal = errors.New("reversals would exceed the original amount")
+	ErrTransactionNotPosted    = errors.New("only posted transactions can be reversed")
+)
+
+var reasonCodes = map[string]bool{
+	models.ReasonCustomerRequest: true,
+	models.ReasonDuplicate:       true,
+	models.ReasonFraud:           true,
+	models.ReasonProcessingError: true,
+	models.ReasonOther:           true,
+}
+
+// ReverseTransaction returns all or part of a transfer to its source by
+// creating a linked compensating transaction. Cumulative reversals can never
+// exceed the original amount, and the original destination must still hold
+// the funds being taken back. A cross-currency transfer is reversed at its
+// original rate.
+func (s *TransactionService) ReverseTransaction(ctx context.Context, transactionID int64, req models.ReversalRequest, idempotencyKey string) (models.Transaction, error) {
+	if err := checkIdempotencyKey(idempotencyKey); err != nil {
+		return models.Transaction{}, err
+	}
+
+	if !reasonCodes[req.ReasonCode] {
+		return models.Transaction{}, ErrInvalidReasonCode
+	}
+
+	var amount decimal.Decimal
+	if req.Amount != "" {
+		var err error
+		amount, err = decimal.NewFromString(req.Amount)
+		if err != nil || !amount.IsPositive() {
+			return models.Transaction{}, ErrInvalidAmount
+		}
+	}
+
+	var requestHash string
+	if idempotencyKey != "" {
+		var err error
+		requestHash, err = hashRequest(struct {
+			TransactionID int64 `json:"transaction_id"`
+			models.ReversalRequest
+		}{transactionID, req})
+		if err != nil {
+			return models.Transaction{}, err
+		}
+
+		reversal, err := s.replay(ctx, idempotencyKey, requestHash)
+		if !errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+			return reversal, err
+		}
+	}
+
+	var reversal models.Transaction
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		// Locking the original serialises concurrent reversals of it.
+		original, err := stores.Transactions.GetByIDForUpdate(ctx, transactionID)
+		if err != nil {
+			return err
+		}
+
+		if original.Kind == models.TransactionKindReversal {
+			return ErrReversalNotAllowed
+		}
+
+		if original.Status != models.TransactionStatusPosted {
+			return ErrTransactionNotPosted
+		}
+
+		remaining := original.Amount.Sub(original.ReversedAmount)
+
+		refund := amount
+		if req.Amount == "" {
+			refund = remaining
+		}
+
+		if !refund.IsPositive() || refund.GreaterThan(remaining) {
+			return ErrReversalExceedsOriginal
+		}
+
+		if err := checkPrecision(refund, original.Currency); err != nil {
+			return err
+		}
+
+		clawback, err := reversalClawback(ctx, stores, original, refund, remaining)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(ctx, stores, original.SourceAccountID, original.DestinationAccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkDebitPermitted(ctx, original.DestinationAccountID); err != nil {
+			return err
+		}
+
+		if err := checkCanDebit(accounts[original.DestinationAccountID]); err != nil {
+			return err
+		}
+
+		if err := checkCanCredit(accounts[original.SourceAccountID]); err != nil {
+			return err
+		}
+
+		if accounts[original.DestinationAccountID].AvailableBalance.LessThan(clawback) {
+			return ErrInsufficientBalance
+		}
+
+		reversal, err = stores.Transactions.Create(ctx, models.Transaction{
+			Kind:                 models.TransactionKindReversal,
+			SourceAccountID:      original.DestinationAccountID,
+			DestinationAccountID: original.SourceAccountID,
+			Amount:               clawback,
+			Currency:             original.DestinationCurrency,
+			DestinationAmount:    refund,
+			DestinationCurrency:  original.Currency,
+			ReversalOf:           &original.ID,
+			ReasonCode:           req.ReasonCode,
+			InitiatedBy:          initiatedBy(ctx),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = postJournalEntry(ctx, stores, accounts, models.JournalEntry{
+			Kind:          models.JournalEntryReversal,
+			TransactionID: &reversal.ID,
+			Postings:      transferPostings(reversal),
+		})
+		if err != nil {
+			return err
+		}
+
+		reversedAmount := original.ReversedAmount.Add(refund)
+		status := models.ReversalStatusPartial
+		if reversedAmount.Equal(original.Amount) {
+			status = models.ReversalStatusFull
+		}
+
+		if err := stores.Transactions.UpdateReversal(ctx, original.ID, reversedAmount, status); err != nil {
+			return err
+		}
+
+		if idempotencyKey == "" {
+			return nil
+		}
+
+		return stores.Idempotency.Create(ctx, models.IdempotencyKey{
+			Key:           idempotencyKey,
+			RequestHash:   requestHash,
+			TransactionID: reversal.ID,
+		}, s.idempotencyTTL)
+	})
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		reversal, err = s.replay(ctx, idempotencyKey, requestHash)
+		return reversal, concurrentReplayError(err)
+	}
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return reversal, nil
+}
+
+// reversalClawback returns how much to take back from the original destination
+// for refund, in the destination currency. For a cross-currency transfer it is
+// the same share of the credited amount as refund is of the debited one; the
+// final reversal takes whatever is left so rounding never strands a remainder.
+func reversalClawback(ctx context.Context, stores repository.Stores, original models.Transaction, refund, remaining decimal.Decimal) (decimal.Decimal, error) {
+	if original.Currency == original.DestinationCurrency {
+		return refund, nil
+	}
+
+	if refund.Equal(remaining) {
+		reversals, err := stores.Transactions.ListReversals(ctx, original.ID)
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		clawedBack := decimal.Zero
+		for _, reversal := range reversals {
+			clawedBack = clawedBack.Add(reversal.Amount)
+		}
+
+		return original.DestinationAmount.Sub(clawedBack), nil
+	}
+
+	destCurrency, _ := currency.Lookup(original.DestinationCurrency)
+	clawback := destCurrency.Round(refund.Mul(original.DestinationAmount).Div(original.Amount))
+	if !clawback.IsPositive() {
+		return decimal.Zero, ErrInvalidAmount
+	}
+
+	return clawback, nil
+}
+
+// ListReversals returns the reversals of a transaction, oldest first.
+func (s *TransactionService) ListReversals(ctx context.Context, transactionID int64) ([]models.Transaction, error) {
+	if _, err := s.transactionStore.GetByID(ctx, transactionID); err != nil {
+		return nil, err
+	}
+
+	return s.transactionStore.ListReversals(ctx, transactionID)
+}
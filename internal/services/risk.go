@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/KaranPal130/transfers-system/internal/risk"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrRiskDenied         = errors.New("transfer was denied by risk screening")
+	ErrRiskReviewRequired = errors.New("transfers held for risk review must be made on their own")
+	ErrInvalidRiskAction  = errors.New("invalid risk action")
+	ErrRiskRulesNotLoaded = errors.New("risk screening is not configured")
+)
+
+// screen runs the transfer past the risk rules in use. The source account
+// must be locked, so that its history cannot change in the meantime. Without
+// rules, nothing is screened and the decision is nil.
+func (s *TransactionService) screen(ctx context.Context, stores repository.Stores, source models.Account, req models.TransactionRequest, amount decimal.Decimal) (*models.RiskDecision, error) {
+	if s.risk == nil {
+		return nil, nil
+	}
+
+	rules := s.risk.Rules()
+	now := time.Now().UTC()
+
+	var history []models.Transaction
+	if lookback := rules.Lookback(); lookback > 0 {
+		var err error
+		history, err = stores.Transactions.ListOutbound(ctx, source.AccountID, now.Add(-lookback))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decision := rules.Evaluate(risk.Transfer{
+		Source:               source,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               amount,
+		At:                   now,
+	}, history)
+
+	return &decision, nil
+}
+
+// mustPostNow turns down a transfer that has to be posted at once, such as a
+// batch leg or a hold capture, unless the risk rules allow it.
+func mustPostNow(decision *models.RiskDecision) error {
+	if decision == nil {
+		return nil
+	}
+
+	switch decision.Action {
+	case models.RiskActionDeny:
+		return ErrRiskDenied
+	case models.RiskActionReview:
+		return ErrRiskReviewRequired
+	}
+	return nil
+}
+
+// recordRejectedDecisions saves the decisions on transfers that were turned
+// down, and so never made, outside the unit of work that turned them down.
+func (s *TransactionService) recordRejectedDecisions(ctx context.Context, decisions []models.RiskDecision) error {
+	return runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		for _, decision := range decisions {
+			if _, err := stores.Risk.CreateDecision(ctx, decision); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListRiskDecisions returns recorded risk decisions, newest first.
+func (s *TransactionService) ListRiskDecisions(ctx context.Context, req models.RiskDecisionListRequest) ([]models.RiskDecision, error) {
+	var errs []error
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+	if limit < 0 || limit > MaxPageSize {
+		errs = append(errs, &FieldError{Field: "limit", Err: ErrInvalidPagination})
+	}
+	if req.Offset < 0 {
+		errs = append(errs, &FieldError{Field: "offset", Err: ErrInvalidPagination})
+	}
+
+	switch req.Action {
+	case "", models.RiskActionAllow, models.RiskActionReview, models.RiskActionDeny:
+	default:
+		errs = append(errs, ErrInvalidRiskAction)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return s.riskStore.ListDecisions(ctx, repository.RiskDecisionQuery{
+		SourceAccountID: req.SourceAccountID,
+		TransactionID:   req.TransactionID,
+		Action:          req.Action,
+		Limit:           limit,
+		Offset:          req.Offset,
+	})
+}
+
+// RiskRules describes the version of the risk rules in use.
+func (s *TransactionService) RiskRules() (models.RiskRules, error) {
+	if s.risk == nil {
+		return models.RiskRules{}, ErrRiskRulesNotLoaded
+	}
+
+	return s.risk.Rules().Summary(), nil
+}
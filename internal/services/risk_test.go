@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/KaranPal130/transfers-system/internal/risk"
+	"github.com/shopspring/decimal"
+)
+
+func TestBlocklistedTransferIsDenied(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	rules := "rules:\n  - {name: blocked, kind: destination_blocklist, action: deny, score: 10, accounts: [2]}\n"
+	if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := risk.NewFileEngine(path)
+	if err != nil {
+		t.Fatalf("load rules: %v", err)
+	}
+
+	s := newTestServices(t)
+	s.transactions.risk = engine
+	s.createAccount(t, 1, "100")
+	s.createAccount(t, 2, "0")
+	s.createAccount(t, 3, "0")
+
+	ctx := context.Background()
+	if _, err := s.transactions.CreateTransaction(ctx, transfer(1, 2, "40"), ""); !errors.Is(err, ErrRiskDenied) {
+		t.Fatalf("transfer to the blocklisted account: err = %v, want ErrRiskDenied", err)
+	}
+
+	if got := s.balance(t, 1); !got.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("source balance = %s, want 100", got)
+	}
+	if got := s.balance(t, 2); !got.IsZero() {
+		t.Errorf("blocklisted balance = %s, want 0", got)
+	}
+
+	// The denial is recorded, without a transaction.
+	decisions, err := s.db.Stores().Risk.ListDecisions(ctx, repository.RiskDecisionQuery{SourceAccountID: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("list decisions: %v", err)
+	}
+	if len(decisions) != 1 {
+		t.Fatalf("decisions = %+v, want one", decisions)
+	}
+	denied := decisions[0]
+	if denied.Action != models.RiskActionDeny || denied.TransactionID != nil || len(denied.Hits) != 1 || denied.Hits[0].Rule != "blocked" {
+		t.Errorf("decision = %+v, want a deny by rule blocked without a transaction", denied)
+	}
+
+	// Other destinations are unaffected.
+	if _, err := s.transactions.CreateTransaction(ctx, transfer(1, 3, "40"), ""); err != nil {
+		t.Fatalf("transfer to another account: %v", err)
+	}
+}
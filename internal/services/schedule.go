@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+)
+
+var (
+	ErrInvalidSchedule          = errors.New("invalid schedule")
+	ErrInvalidFrequency         = errors.New("invalid recurrence frequency")
+	ErrScheduleStatusTransition = errors.New("scheduled transfer cannot make that status change")
+)
+
+type ScheduleService struct {
+	uow           repository.UnitOfWork
+	scheduleStore repository.ScheduleStore
+	accountStore  repository.AccountStore
+}
+
+func NewScheduleService(uow repository.UnitOfWork, scheduleStore repository.ScheduleStore, accountStore repository.AccountStore) *ScheduleService {
+	return &ScheduleService{
+		uow:           uow,
+		scheduleStore: scheduleStore,
+		accountStore:  accountStore,
+	}
+}
+
+// CreateSchedule stores a transfer instruction for the scheduler to run at
+// execute_at and, with a recurrence, on every later date of the series. Funds
+// are only checked when each run executes.
+func (s *ScheduleService) CreateSchedule(ctx context.Context, req models.ScheduledTransferRequest) (models.ScheduledTransfer, error) {
+	transfer := models.TransactionRequest{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		FXMode:               req.FXMode,
+	}
+
+	amount, err := validateTransferRequest(transfer)
+	if err != nil {
+		return models.ScheduledTransfer{}, err
+	}
+
+	fxMode := req.FXMode
+	if fxMode == "" {
+		fxMode = models.FXModeNone
+	}
+
+	now := time.Now().UTC()
+	executeAt := now
+	if req.ExecuteAt != nil && req.ExecuteAt.After(now) {
+		executeAt = req.ExecuteAt.UTC()
+	}
+
+	schedule := models.ScheduledTransfer{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               amount,
+		FXMode:               fxMode,
+		ExecuteAt:            executeAt,
+		Status:               models.ScheduleStatusActive,
+		NextRunAt:            &executeAt,
+		InitiatedBy:          initiatedBy(ctx),
+	}
+
+	if r := req.Recurrence; r != nil {
+		switch r.Frequency {
+		case models.FrequencyDaily, models.FrequencyWeekly, models.FrequencyMonthly:
+		default:
+			return models.ScheduledTransfer{}, ErrInvalidFrequency
+		}
+
+		if r.MaxOccurrences < 0 || (r.EndAt != nil && r.EndAt.Before(executeAt)) {
+			return models.ScheduledTransfer{}, ErrInvalidSchedule
+		}
+
+		schedule.Frequency = r.Frequency
+		schedule.MaxOccurrences = r.MaxOccurrences
+		if r.EndAt != nil {
+			endAt := r.EndAt.UTC()
+			schedule.EndAt = &endAt
+		}
+	}
+
+	// Runs are checked against the schedule's creator here, once; the
+	// scheduler runs them on its behalf later.
+	if err := checkDebitPermitted(ctx, req.SourceAccountID); err != nil {
+		return models.ScheduledTransfer{}, err
+	}
+
+	for _, accountID := range []int64{req.SourceAccountID, req.DestinationAccountID} {
+		if _, err := s.accountStore.GetByID(ctx, accountID); err != nil {
+			return models.ScheduledTransfer{}, err
+		}
+	}
+
+	return s.scheduleStore.Create(ctx, schedule)
+}
+
+func (s *ScheduleService) GetSchedule(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	return s.scheduleStore.GetByID(ctx, id)
+}
+
+func (s *ScheduleService) ListRuns(ctx context.Context, id int64) ([]models.ScheduledTransferRun, error) {
+	if _, err := s.scheduleStore.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.scheduleStore.ListRuns(ctx, id)
+}
+
+// PauseSchedule stops an active schedule from running until it is resumed.
+func (s *ScheduleService) PauseSchedule(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	return s.transition(ctx, id, func(schedule *models.ScheduledTransfer) error {
+		if schedule.Status != models.ScheduleStatusActive {
+			return ErrScheduleStatusTransition
+		}
+
+		schedule.Status = models.ScheduleStatusPaused
+		return nil
+	})
+}
+
+// ResumeSchedule reactivates a paused schedule. Recurring dates that passed
+// while it was paused are skipped; a one-off transfer whose time has passed
+// runs straight away.
+func (s *ScheduleService) ResumeSchedule(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	return s.transition(ctx, id, func(schedule *models.ScheduledTransfer) error {
+		if schedule.Status != models.ScheduleStatusPaused {
+			return ErrScheduleStatusTransition
+		}
+
+		schedule.Status = models.ScheduleStatusActive
+
+		if schedule.Frequency == "" {
+			return nil
+		}
+
+		now := time.Now()
+		for schedule.Status == models.ScheduleStatusActive && ScheduledFor(*schedule).Before(now) {
+			*schedule = AdvanceSchedule(*schedule)
+		}
+
+		if schedule.Status == models.ScheduleStatusActive {
+			next := ScheduledFor(*schedule)
+			schedule.NextRunAt = &next
+		}
+		return nil
+	})
+}
+
+// CancelSchedule stops a schedule for good.
+func (s *ScheduleService) CancelSchedule(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	return s.transition(ctx, id, func(schedule *models.ScheduledTransfer) error {
+		if schedule.Status != models.ScheduleStatusActive && schedule.Status != models.ScheduleStatusPaused {
+			return ErrScheduleStatusTransition
+		}
+
+		schedule.Status = models.ScheduleStatusCancelled
+		schedule.NextRunAt = nil
+		return nil
+	})
+}
+
+// transition applies fn to the locked schedule. The lock waits for a run the
+// scheduler may be executing, so a status change never races a run.
+func (s *ScheduleService) transition(ctx context.Context, id int64, fn func(schedule *models.ScheduledTransfer) error) (models.ScheduledTransfer, error) {
+	var schedule models.ScheduledTransfer
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		var err error
+		schedule, err = stores.Schedules.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(&schedule); err != nil {
+			return err
+		}
+
+		if err := stores.Schedules.Update(ctx, schedule); err != nil {
+			return err
+		}
+
+		schedule, err = stores.Schedules.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return models.ScheduledTransfer{}, err
+	}
+
+	return schedule, nil
+}
+
+// ScheduledFor returns the date of the schedule's current occurrence.
+func ScheduledFor(schedule models.ScheduledTransfer) time.Time {
+	return occurrence(schedule.ExecuteAt, schedule.Frequency, schedule.Occurrences)
+}
+
+// AdvanceSchedule moves the schedule past its current occurrence, completing
+// it once the series is exhausted.
+func AdvanceSchedule(schedule models.ScheduledTransfer) models.ScheduledTransfer {
+	schedule.Occurrences++
+	next := ScheduledFor(schedule)
+
+	if schedule.Frequency == "" ||
+		(schedule.MaxOccurrences > 0 && schedule.Occurrences >= schedule.MaxOccurrences) ||
+		(schedule.EndAt != nil && next.After(*schedule.EndAt)) {
+		schedule.Status = models.ScheduleStatusCompleted
+		schedule.NextRunAt = nil
+		return schedule
+	}
+
+	schedule.NextRunAt = &next
+	return schedule
+}
+
+// occurrence returns the nth date (counting from zero) of the series starting
+// at anchor. Dates are computed from the anchor rather than from each other
+// so that a monthly series on the 31st returns to the 31st after February.
+func occurrence(anchor time.Time, frequency string, n int) time.Time {
+	switch frequency {
+	case models.FrequencyDaily:
+		return anchor.AddDate(0, 0, n)
+	case models.FrequencyWeekly:
+		return anchor.AddDate(0, 0, 7*n)
+	case models.FrequencyMonthly:
+		year, month, day := anchor.Date()
+		first := time.Date(year, month+time.Month(n), 1,
+			anchor.Hour(), anchor.Minute(), anchor.Second(), anchor.Nanosecond(), anchor.Location())
+		lastDay := first.AddDate(0, 1, -1).Day()
+		return first.AddDate(0, 0, min(day, lastDay)-1)
+	default:
+		return anchor
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/KaranPal130/transfers-system/internal/statement"
+)
+
+var ErrInvalidStatementFormat = errors.New("invalid statement format")
+
+// StatementService produces account statements. Balances come from the
+// journal; the lines between them are streamed from the transactions table.
+// Both go by when transactions were posted, so that the lines always lead
+// from the opening balance to the closing one.
+type StatementService struct {
+	accountStore     repository.AccountStore
+	journalStore     repository.JournalStore
+	transactionStore repository.TransactionStore
+}
+
+func NewStatementService(accountStore repository.AccountStore, journalStore repository.JournalStore, transactionStore repository.TransactionStore) *StatementService {
+	return &StatementService{
+		accountStore:     accountStore,
+		journalStore:     journalStore,
+		transactionStore: transactionStore,
+	}
+}
+
+// PrepareStatement validates req and works out the statement's period and
+// balances, so that WriteStatement can no longer fail on bad input once a
+// response is under way. A period starting before the account was opened
+// starts when it was opened instead, so the opening balance includes the
+// initial funding, which is not a transaction.
+func (s *StatementService) PrepareStatement(ctx context.Context, accountID int64, req models.StatementRequest) (models.Statement, error) {
+	format := req.Format
+	if format == "" {
+		format = statement.FormatCSV
+	}
+	if !statement.Valid(format) {
+		return models.Statement{}, ErrInvalidStatementFormat
+	}
+
+	from, to, err := parseTimeRange(req.From, req.To)
+	if err != nil {
+		return models.Statement{}, err
+	}
+
+	account, err := s.accountStore.GetByID(ctx, accountID)
+	if err != nil {
+		return models.Statement{}, err
+	}
+
+	if from.Before(account.CreatedAt) {
+		from = account.CreatedAt
+		if !from.Before(to) {
+			return models.Statement{}, ErrInvalidTimeRange
+		}
+	}
+
+	st := models.Statement{
+		AccountID: accountID,
+		Currency:  account.Currency,
+		Format:    format,
+		From:      from,
+		To:        to,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	st.OpeningBalance, err = s.journalStore.SumPostingsPostedUntil(ctx, accountID, from)
+	if err != nil {
+		return models.Statement{}, err
+	}
+
+	st.ClosingBalance, err = s.journalStore.SumPostingsPostedUntil(ctx, accountID, to)
+	if err != nil {
+		return models.Statement{}, err
+	}
+
+	return st, nil
+}
+
+// WriteStatement renders st to w, reading its transactions one at a time.
+func (s *StatementService) WriteStatement(ctx context.Context, st models.Statement, w io.Writer) error {
+	enc, err := statement.NewEncoder(st.Format, w)
+	if err != nil {
+		return err
+	}
+
+	if err := enc.Begin(st); err != nil {
+		return err
+	}
+
+	balance := st.OpeningBalance
+	err = s.transactionStore.EachByAccount(ctx, st.AccountID, st.From, st.To, func(transaction models.Transaction) error {
+		line := statementLine(st.AccountID, transaction)
+		if line.Type == models.StatementLineDebit {
+			balance = balance.Sub(line.Amount)
+		} else {
+			balance = balance.Add(line.Amount)
+		}
+		line.Balance = balance
+
+		return enc.Line(line)
+	})
+	if err != nil {
+		return err
+	}
+
+	return enc.End(st)
+}
+
+// statementLine describes transaction from the side of accountID, which is
+// debited in the source currency or credited in the destination currency.
+func statementLine(accountID int64, transaction models.Transaction) models.StatementLine {
+	line := models.StatementLine{
+		Type:              models.StatementLineCredit,
+		BookedAt:          *transaction.PostedAt,
+		TransactionID:     &transaction.ID,
+		Kind:              transaction.Kind,
+		Reference:         statementReference(transaction),
+		ExternalReference: transaction.ExternalReference,
+		Amount:            transaction.DestinationAmount,
+		Currency:          transaction.DestinationCurrency,
+	}
+	counterparty := transaction.SourceAccountID
+
+	if transaction.SourceAccountID == accountID {
+		line.Type = models.StatementLineDebit
+		line.Amount = transaction.Amount
+		line.Currency = transaction.Currency
+		counterparty = transaction.DestinationAccountID
+	}
+	line.CounterpartyAccountID = &counterparty
+
+	return line
+}
+
+// statementReference returns the transfer's description or, without one, says
+// where the transaction came from.
+func statementReference(transaction models.Transaction) string {
+	switch {
+	case transaction.Description != "":
+		return transaction.Description
+	case transaction.ReversalOf != nil:
+		return fmt.Sprintf("Reversal of transaction %d (%s)", *transaction.ReversalOf, transaction.ReasonCode)
+	case transaction.BatchID != nil:
+		return fmt.Sprintf("Batch %d", *transaction.BatchID)
+	default:
+		return ""
+	}
+}
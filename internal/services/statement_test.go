@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/KaranPal130/transfers-system/internal/statement"
+	"github.com/shopspring/decimal"
+)
+
+func TestStatementLinesAddUpToClosingBalance(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 1, "500")
+	s.createAccount(t, 2, "500")
+	ctx := context.Background()
+
+	for _, req := range []models.TransactionRequest{
+		transfer(1, 2, "40"),
+		transfer(2, 1, "15.25"),
+		transfer(1, 2, "0.75"),
+	} {
+		if _, err := s.transactions.CreateTransaction(ctx, req, ""); err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+	}
+	_, err := s.transactions.CreateBatch(ctx, models.BatchTransactionRequest{
+		Legs: []models.TransactionRequest{transfer(2, 1, "100"), transfer(1, 2, "9.99")},
+	}, "")
+	if err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+
+	stores := s.db.Stores()
+	statements := NewStatementService(stores.Accounts, stores.Journal, stores.Transactions)
+
+	st, err := statements.PrepareStatement(ctx, 1, models.StatementRequest{
+		To:     time.Now().Add(time.Minute).Format(time.RFC3339Nano),
+		Format: statement.FormatJSONL,
+	})
+	if err != nil {
+		t.Fatalf("PrepareStatement: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := statements.WriteStatement(ctx, st, &buf); err != nil {
+		t.Fatalf("WriteStatement: %v", err)
+	}
+
+	type jsonLine struct {
+		Type    string          `json:"type"`
+		Amount  decimal.Decimal `json:"amount"`
+		Balance decimal.Decimal `json:"balance"`
+	}
+	var lines []jsonLine
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line jsonLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 7 {
+		t.Fatalf("got %d lines, want the opening balance, 5 transfers and the closing balance", len(lines))
+	}
+
+	running := st.OpeningBalance
+	for _, line := range lines[1 : len(lines)-1] {
+		switch line.Type {
+		case models.StatementLineDebit:
+			running = running.Sub(line.Amount)
+		case models.StatementLineCredit:
+			running = running.Add(line.Amount)
+		default:
+			t.Fatalf("unexpected %s line between the balances", line.Type)
+		}
+		if !line.Balance.Equal(running) {
+			t.Errorf("%s line balance = %s, want %s", line.Type, line.Balance, running)
+		}
+	}
+
+	if !running.Equal(st.ClosingBalance) {
+		t.Errorf("opening balance %s plus the lines = %s, want the closing balance %s", st.OpeningBalance, running, st.ClosingBalance)
+	}
+	if want := s.balance(t, 1); !st.ClosingBalance.Equal(want) {
+		t.Errorf("closing balance = %s, want the account balance %s", st.ClosingBalance, want)
+	}
+	if closing := lines[len(lines)-1]; !closing.Balance.Equal(st.ClosingBalance) {
+		t.Errorf("closing line balance = %s, want %s", closing.Balance, st.ClosingBalance)
+	}
+}
+
+func TestStatementBalancesFollowPostingTime(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 1, "100")
+	s.createAccount(t, 2, "0")
+	ctx := context.Background()
+
+	// Post a transfer whose journal entry is written in a later unit of work,
+	// so that the two carry different times.
+	var transaction models.Transaction
+	err := s.db.Do(ctx, func(stores repository.Stores) error {
+		var err error
+		transaction, err = stores.Transactions.Create(ctx, models.Transaction{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(10),
+			Currency:             "USD",
+			DestinationAmount:    decimal.NewFromInt(10),
+			DestinationCurrency:  "USD",
+			Kind:                 models.TransactionKindTransfer,
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	var entry models.JournalEntry
+	err = s.db.Do(ctx, func(stores repository.Stores) error {
+		var err error
+		entry, err = stores.Journal.CreateEntry(ctx, models.JournalEntry{
+			Kind:          models.JournalEntryTransfer,
+			TransactionID: &transaction.ID,
+			Postings:      transferPostings(transaction),
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("create journal entry: %v", err)
+	}
+
+	// The statement ends after the transfer was posted but before its entry
+	// was written.
+	to := transaction.PostedAt.Add(entry.CreatedAt.Sub(*transaction.PostedAt) / 2)
+
+	stores := s.db.Stores()
+	statements := NewStatementService(stores.Accounts, stores.Journal, stores.Transactions)
+
+	for _, id := range []int64{1, 2} {
+		st, err := statements.PrepareStatement(ctx, id, models.StatementRequest{To: to.Format(time.RFC3339Nano)})
+		if err != nil {
+			t.Fatalf("PrepareStatement: %v", err)
+		}
+
+		running := st.OpeningBalance
+		err = stores.Transactions.EachByAccount(ctx, id, st.From, st.To, func(transaction models.Transaction) error {
+			line := statementLine(id, transaction)
+			if line.Type == models.StatementLineDebit {
+				running = running.Sub(line.Amount)
+			} else {
+				running = running.Add(line.Amount)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("list lines: %v", err)
+		}
+
+		if !running.Equal(st.ClosingBalance) {
+			t.Errorf("account %d: opening balance %s plus the lines = %s, want the closing balance %s", id, st.OpeningBalance, running, st.ClosingBalance)
+		}
+	}
+}
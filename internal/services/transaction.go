@@ -2,99 +2,481 @@ package service
 
 import (
 	"context"
-	"database/sql"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/KaranPal130/transfers-system/internal/currency"
+	"github.com/KaranPal130/transfers-system/internal/fx"
 	"github.com/KaranPal130/transfers-system/internal/models"
 	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/KaranPal130/transfers-system/internal/risk"
 	"github.com/shopspring/decimal"
 )
 
 var (
-	ErrInvalidAmount       = errors.New("invalid amount")
-	ErrInsufficientBalance = errors.New("insufficient balance")
-	ErrSameSourceAndDest   = errors.New("source and destination accounts must be different")
+	ErrInvalidAmount          = errors.New("invalid amount")
+	ErrInsufficientBalance    = errors.New("insufficient balance")
+	ErrSameSourceAndDest      = errors.New("source and destination accounts must be different")
+	ErrInvalidPagination      = errors.New("invalid pagination parameters")
+	ErrInvalidIdempotencyKey  = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyConflict = errors.New("idempotency key was used by a concurrent request that can no longer be replayed")
+	ErrInvalidFXMode          = errors.New("invalid fx mode")
+	ErrCurrencyMismatch       = errors.New("source and destination accounts have different currencies")
+	ErrFXUnavailable          = errors.New("currency conversion is not available")
+
+	ErrInvalidDescription         = errors.New("invalid description")
+	ErrInvalidExternalReference   = errors.New("invalid external reference")
+	ErrInvalidMetadata            = errors.New("invalid metadata")
+	ErrDuplicateExternalReference = errors.New("external reference was already used by the source account")
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+
+	MaxIdempotencyKeyLength  = 255
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	// ScheduledIdempotencyKeyPrefix starts the idempotency keys of scheduled
+	// runs. Callers may not use it, so they cannot claim a run's key first.
+	ScheduledIdempotencyKeyPrefix = "sched-"
+
+	MaxDescriptionLength       = 500
+	MaxExternalReferenceLength = 128
+	// MaxMetadataKeys and MaxMetadataSize bound a transfer's metadata object:
+	// its top-level keys and its size encoded as JSON, in bytes.
+	MaxMetadataKeys = 50
+	MaxMetadataSize = 4096
 )
 
 type TransactionService struct {
-	db              *sql.DB
-	accountRepo     *repository.AccountRepository
-	transactionRepo *repository.TransactionRepository
+	uow              repository.UnitOfWork
+	accountStore     repository.AccountStore
+	transactionStore repository.TransactionStore
+	idempotencyStore repository.IdempotencyStore
+	batchStore       repository.BatchStore
+	approvalStore    repository.ApprovalStore
+	riskStore        repository.RiskStore
+	rates            fx.RateProvider
+	idempotencyTTL   time.Duration
+	approvals        ApprovalPolicy
+	limits           LimitPolicy
+	// risk screens transfers; nil turns screening off.
+	risk *risk.Engine
 }
 
 func NewTransactionService(
-	db *sql.DB,
-	accountRepo *repository.AccountRepository,
-	transactionRepo *repository.TransactionRepository,
+	uow repository.UnitOfWork,
+	accountStore repository.AccountStore,
+	transactionStore repository.TransactionStore,
+	idempotencyStore repository.IdempotencyStore,
+	batchStore repository.BatchStore,
+	approvalStore repository.ApprovalStore,
+	riskStore repository.RiskStore,
+	rates fx.RateProvider,
+	idempotencyTTL time.Duration,
+	approvals ApprovalPolicy,
+	limits LimitPolicy,
+	riskEngine *risk.Engine,
 ) *TransactionService {
 	return &TransactionService{
-		db:              db,
-		accountRepo:     accountRepo,
-		transactionRepo: transactionRepo,
+		uow:              uow,
+		accountStore:     accountStore,
+		transactionStore: transactionStore,
+		idempotencyStore: idempotencyStore,
+		batchStore:       batchStore,
+		approvalStore:    approvalStore,
+		riskStore:        riskStore,
+		rates:            rates,
+		idempotencyTTL:   idempotencyTTL,
+		approvals:        approvals,
+		limits:           limits,
+		risk:             riskEngine,
+	}
+}
+
+// CreateTransaction moves money between two accounts, within the source
+// account's transfer limits. A transfer above the
+// approval threshold is only recorded, as pending_approval, until another
+// caller approves it. When idempotencyKey is not empty, a retry carrying the
+// same key and request returns the originally created transaction instead of
+// moving the money again.
+func (s *TransactionService) CreateTransaction(ctx context.Context, req models.TransactionRequest, idempotencyKey string) (models.Transaction, error) {
+	if err := checkIdempotencyKey(idempotencyKey); err != nil {
+		return models.Transaction{}, err
 	}
+
+	return s.createTransaction(ctx, req, idempotencyKey)
+}
+
+// RunScheduledTransfer makes a scheduled transfer's given occurrence as
+// CreateTransaction does, under an idempotency key of its own that callers
+// cannot use.
+func (s *TransactionService) RunScheduledTransfer(ctx context.Context, req models.TransactionRequest, scheduleID int64, occurrence int) (models.Transaction, error) {
+	idempotencyKey := fmt.Sprintf("%s%d-%d", ScheduledIdempotencyKeyPrefix, scheduleID, occurrence)
+	return s.createTransaction(ctx, req, idempotencyKey)
 }
 
-func (s *TransactionService) CreateTransaction(ctx context.Context, req models.TransactionRequest) error {
+func (s *TransactionService) createTransaction(ctx context.Context, req models.TransactionRequest, idempotencyKey string) (models.Transaction, error) {
+	amount, err := validateTransferRequest(req)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	var requestHash string
+	if idempotencyKey != "" {
+		requestHash, err = hashRequest(req)
+		if err != nil {
+			return models.Transaction{}, err
+		}
+
+		transaction, err := s.replay(ctx, idempotencyKey, requestHash)
+		if !errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+			return transaction, err
+		}
+	}
+
+	transaction, err := s.transfer(ctx, req, amount, idempotencyKey, requestHash)
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		// A concurrent request with the same key committed first.
+		transaction, err = s.replay(ctx, idempotencyKey, requestHash)
+		return transaction, concurrentReplayError(err)
+	}
+
+	return transaction, err
+}
+
+// checkIdempotencyKey checks a key sent by a caller.
+func checkIdempotencyKey(key string) error {
+	if len(key) > MaxIdempotencyKeyLength || strings.HasPrefix(key, ScheduledIdempotencyKeyPrefix) {
+		return ErrInvalidIdempotencyKey
+	}
+	return nil
+}
+
+// validateTransferRequest checks what can be checked without reading any
+// account and returns the parsed amount.
+func validateTransferRequest(req models.TransactionRequest) (decimal.Decimal, error) {
+	var errs []error
+
 	if req.SourceAccountID == req.DestinationAccountID {
-		return ErrSameSourceAndDest
+		errs = append(errs, ErrSameSourceAndDest)
 	}
 
 	amount, err := decimal.NewFromString(req.Amount)
-	if err != nil {
-		return ErrInvalidAmount
+	if err != nil || amount.LessThanOrEqual(decimal.Zero) {
+		errs = append(errs, ErrInvalidAmount)
 	}
 
-	if amount.LessThanOrEqual(decimal.Zero) {
-		return ErrInvalidAmount
+	switch req.FXMode {
+	case "", models.FXModeNone, models.FXModeConvert:
+		if req.FXQuoteID != "" && req.FXMode != models.FXModeConvert {
+			errs = append(errs, ErrInvalidFXMode)
+		}
+	default:
+		errs = append(errs, ErrInvalidFXMode)
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
+	if !validText(req.Description, MaxDescriptionLength) {
+		errs = append(errs, ErrInvalidDescription)
 	}
 
-	defer func() {
+	if !validText(req.ExternalReference, MaxExternalReferenceLength) || strings.TrimSpace(req.ExternalReference) != req.ExternalReference {
+		errs = append(errs, ErrInvalidExternalReference)
+	}
+
+	if err := validateMetadata(req.Metadata); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Every failed check is reported, so that a client can fix them all at
+	// once.
+	if err := errors.Join(errs...); err != nil {
+		return decimal.Zero, err
+	}
+
+	return amount, nil
+}
+
+// validText reports whether s is valid UTF-8 of at most maxLength characters
+// without control characters, which would not survive a CSV or XML statement.
+func validText(s string, maxLength int) bool {
+	return utf8.ValidString(s) &&
+		utf8.RuneCountInString(s) <= maxLength &&
+		!strings.ContainsFunc(s, unicode.IsControl)
+}
+
+func validateMetadata(metadata map[string]any) error {
+	if len(metadata) > MaxMetadataKeys {
+		return ErrInvalidMetadata
+	}
+
+	body, err := json.Marshal(metadata)
+	if err != nil || len(body) > MaxMetadataSize {
+		return ErrInvalidMetadata
+	}
+
+	return nil
+}
+
+func (s *TransactionService) transfer(ctx context.Context, req models.TransactionRequest, amount decimal.Decimal, idempotencyKey, requestHash string) (models.Transaction, error) {
+	var transaction models.Transaction
+	// denied is the decision on a transfer the risk rules turned down, which
+	// is recorded even though the transfer is not.
+	var denied *models.RiskDecision
+
+	err := runInTx(ctx, s.uow, func(stores repository.Stores) error {
+		denied = nil
+
+		decision, err := s.admitTransfer(ctx, stores, req, amount)
+		if err != nil {
+			return err
+		}
+		if decision != nil && decision.Action == models.RiskActionDeny {
+			denied = decision
+			return ErrRiskDenied
+		}
+
+		review := decision != nil && decision.Action == models.RiskActionReview
+		if review || s.needsApproval(amount) {
+			transaction, err = s.requestApproval(ctx, stores, req, amount)
+		} else {
+			transaction, err = s.executeTransfer(ctx, stores, req, amount, nil)
+		}
 		if err != nil {
-			_ = tx.Rollback()
+			return err
 		}
-	}()
 
-	sourceAccount, err := s.accountRepo.GetByIDForUpdate(ctx, tx, req.SourceAccountID)
+		if decision != nil {
+			decision.TransactionID = &transaction.ID
+			if _, err := stores.Risk.CreateDecision(ctx, *decision); err != nil {
+				return err
+			}
+		}
+
+		if idempotencyKey == "" {
+			return nil
+		}
+
+		record := models.IdempotencyKey{
+			Key:           idempotencyKey,
+			RequestHash:   requestHash,
+			TransactionID: transaction.ID,
+		}
+
+		return stores.Idempotency.Create(ctx, record, s.idempotencyTTL)
+	})
+	if errors.Is(err, ErrRiskDenied) && denied != nil {
+		if err := s.recordRejectedDecisions(ctx, []models.RiskDecision{*denied}); err != nil {
+			return models.Transaction{}, err
+		}
+	}
 	if err != nil {
-		return err
+		return models.Transaction{}, err
 	}
 
-	destAccount, err := s.accountRepo.GetByIDForUpdate(ctx, tx, req.DestinationAccountID)
+	return transaction, nil
+}
+
+// executeTransfer moves amount as described by req within the caller's unit of
+// work. req must already have passed validateTransferRequest. batchID links the
+// transfer to its batch, if any.
+//
+// A failed check returns before anything is written, so the caller may carry
+// on with the unit of work.
+func (s *TransactionService) executeTransfer(ctx context.Context, stores repository.Stores, req models.TransactionRequest, amount decimal.Decimal, batchID *int64) (models.Transaction, error) {
+	accounts, err := lockAccounts(ctx, stores, req.SourceAccountID, req.DestinationAccountID)
 	if err != nil {
-		return err
+		return models.Transaction{}, err
 	}
 
-	if sourceAccount.Balance.LessThan(amount) {
-		return ErrInsufficientBalance
+	transaction, err := s.planTransfer(ctx, stores, accounts, req, amount)
+	if err != nil {
+		return models.Transaction{}, err
 	}
+	transaction.BatchID = batchID
 
-	newSourceBalance := sourceAccount.Balance.Sub(amount)
-	err = s.accountRepo.UpdateBalance(ctx, tx, req.SourceAccountID, newSourceBalance)
+	transaction, err = stores.Transactions.Create(ctx, transaction)
+	if errors.Is(err, repository.ErrExternalReferenceExists) {
+		return models.Transaction{}, ErrDuplicateExternalReference
+	}
 	if err != nil {
-		return err
+		return models.Transaction{}, err
 	}
 
-	newDestBalance := destAccount.Balance.Add(amount)
-	err = s.accountRepo.UpdateBalance(ctx, tx, req.DestinationAccountID, newDestBalance)
+	if transaction.FXQuoteID != nil {
+		if err := stores.FXQuotes.MarkUsed(ctx, *transaction.FXQuoteID, transaction.ID); err != nil {
+			return models.Transaction{}, err
+		}
+	}
+
+	_, err = postJournalEntry(ctx, stores, accounts, models.JournalEntry{
+		Kind:          models.JournalEntryTransfer,
+		TransactionID: &transaction.ID,
+		Postings:      transferPostings(transaction),
+	})
 	if err != nil {
-		return err
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// planTransfer runs every check a transfer must pass against the locked
+// accounts and returns the transaction it would create, without writing
+// anything.
+func (s *TransactionService) planTransfer(ctx context.Context, stores repository.Stores, accounts map[int64]models.Account, req models.TransactionRequest, amount decimal.Decimal) (models.Transaction, error) {
+	sourceAccount := accounts[req.SourceAccountID]
+	destAccount := accounts[req.DestinationAccountID]
+
+	if err := checkDebitPermitted(ctx, sourceAccount.AccountID); err != nil {
+		return models.Transaction{}, err
+	}
+
+	if err := checkCanDebit(sourceAccount); err != nil {
+		return models.Transaction{}, err
+	}
+
+	if err := checkCanCredit(destAccount); err != nil {
+		return models.Transaction{}, err
+	}
+
+	if err := checkPrecision(amount, sourceAccount.Currency); err != nil {
+		return models.Transaction{}, err
+	}
+
+	if sourceAccount.Headroom.LessThan(amount) {
+		return models.Transaction{}, ErrInsufficientBalance
+	}
+
+	// The source account is locked, so no concurrent transfer can take the
+	// reference between this check and the insert.
+	if req.ExternalReference != "" {
+		_, err := stores.Transactions.GetByExternalReference(ctx, req.SourceAccountID, req.ExternalReference)
+		if err == nil {
+			return models.Transaction{}, ErrDuplicateExternalReference
+		}
+		if !errors.Is(err, repository.ErrTransactionNotFound) {
+			return models.Transaction{}, err
+		}
 	}
 
 	transaction := models.Transaction{
+		Kind:                 models.TransactionKindTransfer,
 		SourceAccountID:      req.SourceAccountID,
 		DestinationAccountID: req.DestinationAccountID,
-		Amount:               req.Amount,
+		Amount:               amount,
+		Currency:             sourceAccount.Currency,
+		DestinationAmount:    amount,
+		DestinationCurrency:  destAccount.Currency,
+		Description:          req.Description,
+		ExternalReference:    req.ExternalReference,
+		Metadata:             req.Metadata,
+		InitiatedBy:          initiatedBy(ctx),
 	}
 
-	err = s.transactionRepo.Create(ctx, tx, transaction)
+	if sourceAccount.Currency != destAccount.Currency {
+		if req.FXMode != models.FXModeConvert {
+			return models.Transaction{}, ErrCurrencyMismatch
+		}
+
+		conv, err := resolveConversion(ctx, stores, s.rates, req.FXQuoteID, sourceAccount.Currency, destAccount.Currency)
+		if err != nil {
+			return models.Transaction{}, err
+		}
+
+		destCurrency, _ := currency.Lookup(destAccount.Currency)
+		transaction.DestinationAmount = destCurrency.Round(amount.Mul(conv.rate))
+		transaction.FXRate = &conv.rate
+		transaction.FXQuoteID = conv.quoteID
+
+		if !transaction.DestinationAmount.IsPositive() {
+			return models.Transaction{}, ErrInvalidAmount
+		}
+	} else if req.FXQuoteID != "" {
+		return models.Transaction{}, ErrFXQuoteMismatch
+	}
+
+	return transaction, nil
+}
+
+// replay returns the transaction previously created under key, or
+// ErrIdempotencyKeyNotFound if the key is unused or has expired.
+func (s *TransactionService) replay(ctx context.Context, key, requestHash string) (models.Transaction, error) {
+	record, err := s.idempotencyStore.Get(ctx, key)
 	if err != nil {
-		return err
+		return models.Transaction{}, err
+	}
+
+	if record.RequestHash != requestHash {
+		return models.Transaction{}, ErrIdempotencyKeyMismatch
+	}
+
+	return s.transactionStore.GetByID(ctx, record.TransactionID)
+}
+
+// concurrentReplayError is the error of replaying a key that a concurrent
+// request has just committed. The key may have expired or been purged since,
+// in which case the caller is asked to retry, and the retry runs afresh.
+func concurrentReplayError(err error) error {
+	if errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+		return ErrIdempotencyKeyConflict
+	}
+	return err
+}
+
+func hashRequest(req any) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes idempotency keys past their retention
+// window and returns how many were removed.
+func (s *TransactionService) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return s.idempotencyStore.DeleteExpired(ctx)
+}
+
+func (s *TransactionService) GetTransaction(ctx context.Context, id int64) (models.Transaction, error) {
+	return s.transactionStore.GetByID(ctx, id)
+}
+
+// FindByExternalReference returns the transactions made with the given
+// external reference, from any source account, oldest first.
+func (s *TransactionService) FindByExternalReference(ctx context.Context, externalReference string) ([]models.Transaction, error) {
+	if externalReference == "" || !validText(externalReference, MaxExternalReferenceLength) {
+		return nil, ErrInvalidExternalReference
+	}
+
+	return s.transactionStore.ListByExternalReference(ctx, externalReference)
+}
+
+func (s *TransactionService) ListAccountTransactions(ctx context.Context, accountID int64, limit, offset int) ([]models.Transaction, error) {
+	var errs []error
+	if limit <= 0 || limit > MaxPageSize {
+		errs = append(errs, &FieldError{Field: "limit", Err: ErrInvalidPagination})
+	}
+	if offset < 0 {
+		errs = append(errs, &FieldError{Field: "offset", Err: ErrInvalidPagination})
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.accountStore.GetByID(ctx, accountID); err != nil {
+		return nil, err
 	}
 
-	return tx.Commit()
+	return s.transactionStore.ListByAccount(ctx, accountID, limit, offset)
 }
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	repository "github.com/KaranPal130/transfers-system/internal/repositories"
+	"github.com/KaranPal130/transfers-system/internal/repositories/memory"
+	"github.com/shopspring/decimal"
+)
+
+type testServices struct {
+	db           *memory.DB
+	transactions *TransactionService
+	accounts     *AccountService
+}
+
+// newTestServices wires the services to a fresh in-memory backend, with no
+// approvals, limits or risk rules.
+func newTestServices(t *testing.T) testServices {
+	t.Helper()
+
+	db := memory.New()
+	stores := db.Stores()
+
+	transactions := NewTransactionService(db, stores.Accounts, stores.Transactions, stores.Idempotency, stores.Batches, stores.Approvals, stores.Risk, nil, DefaultIdempotencyKeyTTL, ApprovalPolicy{}, LimitPolicy{}, nil)
+	accounts := NewAccountService(db, stores.Accounts, stores.Holds, transactions)
+
+	return testServices{db: db, transactions: transactions, accounts: accounts}
+}
+
+func (s testServices) createAccount(t *testing.T, id int64, balance string) {
+	t.Helper()
+
+	err := s.accounts.CreateAccount(context.Background(), models.AccountCreateRequest{AccountID: id, InitialBalance: balance})
+	if err != nil {
+		t.Fatalf("create account %d: %v", id, err)
+	}
+}
+
+// balance returns the account's cached balance, failing the test if it has
+// drifted from its journal.
+func (s testServices) balance(t *testing.T, id int64) decimal.Decimal {
+	t.Helper()
+
+	reconciliation, err := s.accounts.ReconcileBalance(context.Background(), id)
+	if err != nil {
+		t.Fatalf("reconcile account %d: %v", id, err)
+	}
+	if !reconciliation.InSync {
+		t.Fatalf("account %d drifted: cached %s, ledger %s", id, reconciliation.CachedBalance, reconciliation.LedgerBalance)
+	}
+
+	return reconciliation.CachedBalance
+}
+
+func transfer(source, destination int64, amount string) models.TransactionRequest {
+	return models.TransactionRequest{SourceAccountID: source, DestinationAccountID: destination, Amount: amount}
+}
+
+func TestConcurrentOppositeTransfers(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 1, "1000")
+	s.createAccount(t, 2, "1000")
+
+	const perDirection = 50
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*perDirection)
+	for i := range 2 * perDirection {
+		req := transfer(1, 2, "3")
+		if i%2 == 1 {
+			req = transfer(2, 1, "3")
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.transactions.CreateTransaction(ctx, req, ""); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("transfer failed: %v", err)
+	}
+
+	// As many transfers went each way, so both balances end where they began.
+	for _, id := range []int64{1, 2} {
+		if got := s.balance(t, id); !got.Equal(decimal.NewFromInt(1000)) {
+			t.Errorf("account %d balance = %s, want 1000", id, got)
+		}
+	}
+}
+
+func TestTransferRetriesAfterLockConflict(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 1, "100")
+	s.createAccount(t, 2, "0")
+
+	// Keep the source locked for longer than a lock wait may last, so that
+	// the transfer's first attempt fails with a conflict.
+	held := memory.LockTimeout + memory.LockTimeout/2
+	locked := make(chan struct{})
+	holder := make(chan error, 1)
+	go func() {
+		holder <- s.db.Do(context.Background(), func(stores repository.Stores) error {
+			if _, err := stores.Accounts.GetByIDForUpdate(context.Background(), 1); err != nil {
+				close(locked)
+				return err
+			}
+			close(locked)
+			time.Sleep(held)
+			return nil
+		})
+	}()
+	<-locked
+
+	start := time.Now()
+	transaction, err := s.transactions.CreateTransaction(context.Background(), transfer(1, 2, "40"), "")
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if waited := time.Since(start); waited < memory.LockTimeout {
+		t.Errorf("transfer finished after %s, without waiting out a conflict", waited)
+	}
+	if transaction.Status != models.TransactionStatusPosted {
+		t.Errorf("status = %s, want %s", transaction.Status, models.TransactionStatusPosted)
+	}
+
+	if err := <-holder; err != nil {
+		t.Fatalf("lock holder: %v", err)
+	}
+
+	if got := s.balance(t, 1); !got.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("source balance = %s, want 60", got)
+	}
+	if got := s.balance(t, 2); !got.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("destination balance = %s, want 40", got)
+	}
+}
+
+func TestRejectedBatchLeavesNoPartialState(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 1, "100")
+	s.createAccount(t, 2, "0")
+	s.createAccount(t, 3, "0")
+
+	// The first leg would post, but the second overdraws the source.
+	_, err := s.transactions.CreateBatch(context.Background(), models.BatchTransactionRequest{
+		Legs: []models.TransactionRequest{
+			transfer(1, 2, "60"),
+			transfer(1, 3, "60"),
+		},
+	}, "batch-1")
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("got %v, want a BatchError", err)
+	}
+	if len(batchErr.Legs) != 1 || batchErr.Legs[0].Index != 1 || !errors.Is(batchErr.Legs[0].Err, ErrInsufficientBalance) {
+		t.Errorf("failed legs = %+v, want leg 1 with insufficient balance", batchErr.Legs)
+	}
+
+	for id, want := range map[int64]int64{1: 100, 2: 0, 3: 0} {
+		if got := s.balance(t, id); !got.Equal(decimal.NewFromInt(want)) {
+			t.Errorf("account %d balance = %s, want %d", id, got, want)
+		}
+	}
+
+	for _, id := range []int64{1, 2, 3} {
+		transactions, err := s.transactions.ListAccountTransactions(context.Background(), id, DefaultPageSize, 0)
+		if err != nil {
+			t.Fatalf("list transactions of account %d: %v", id, err)
+		}
+		if len(transactions) > 0 {
+			t.Errorf("account %d has transactions %+v", id, transactions)
+		}
+	}
+
+	// The idempotency key was rolled back with the batch, so it can be used
+	// again.
+	_, err = s.transactions.CreateBatch(context.Background(), models.BatchTransactionRequest{
+		Legs: []models.TransactionRequest{transfer(1, 2, "60")},
+	}, "batch-1")
+	if err != nil {
+		t.Fatalf("retry with the same key: %v", err)
+	}
+}
+
+// purgedIdempotencyStore reads every key as gone, as if each had been purged
+// the moment the request that claimed it committed.
+type purgedIdempotencyStore struct {
+	repository.IdempotencyStore
+}
+
+func (purgedIdempotencyStore) Get(ctx context.Context, key string) (models.IdempotencyKey, error) {
+	return models.IdempotencyKey{}, repository.ErrIdempotencyKeyNotFound
+}
+
+func TestLostIdempotencyRaceWithPurgedKey(t *testing.T) {
+	s := newTestServices(t)
+	s.createAccount(t, 1, "100")
+	s.createAccount(t, 2, "0")
+	ctx := context.Background()
+
+	original, err := s.transactions.CreateTransaction(ctx, transfer(1, 2, "10"), "")
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	// Every request below finds its key taken by a concurrent request, then
+	// gone when it tries to replay it.
+	err = s.db.Do(ctx, func(stores repository.Stores) error {
+		for _, key := range []string{"transfer-1", "batch-1", "reversal-1"} {
+			if err := stores.Idempotency.Create(ctx, models.IdempotencyKey{Key: key, TransactionID: original.ID}, time.Hour); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("claim keys: %v", err)
+	}
+	s.transactions.idempotencyStore = purgedIdempotencyStore{s.transactions.idempotencyStore}
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"transfer", func() error {
+			_, err := s.transactions.CreateTransaction(ctx, transfer(1, 2, "20"), "transfer-1")
+			return err
+		}},
+		{"batch", func() error {
+			_, err := s.transactions.CreateBatch(ctx, models.BatchTransactionRequest{Legs: []models.TransactionRequest{transfer(1, 2, "20")}}, "batch-1")
+			return err
+		}},
+		{"reversal", func() error {
+			_, err := s.transactions.ReverseTransaction(ctx, original.ID, models.ReversalRequest{ReasonCode: models.ReasonDuplicate}, "reversal-1")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, ErrIdempotencyKeyConflict) {
+				t.Fatalf("err = %v, want ErrIdempotencyKeyConflict", err)
+			}
+		})
+	}
+
+	// The losing requests were rolled back.
+	if got := s.balance(t, 1); !got.Equal(decimal.NewFromInt(90)) {
+		t.Errorf("source balance = %s, want 90", got)
+	}
+	if got := s.balance(t, 2); !got.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("destination balance = %s, want 10", got)
+	}
+}
@@ -0,0 +1,17 @@
+package service
+
+// FieldError attributes a validation error to the request field that caused
+// it, where the error alone does not say which field that was. It matches
+// Err under errors.Is.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,217 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+// ISO 20022 codes used in camt.053 statements.
+const (
+	camtCredit         = "CRDT"
+	camtDebit          = "DBIT"
+	camtOpeningBooked  = "OPBD"
+	camtClosingBooked  = "CLBD"
+	camtStatusBooked   = "BOOK"
+	camtDateTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+)
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtAccount struct {
+	ID string `xml:"Id>Othr>Id"`
+}
+
+type camtStatementAccount struct {
+	ID       string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+}
+
+type camtPeriod struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+type camtBalance struct {
+	Type      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	Indicator string     `xml:"CdtDbtInd"`
+	Date      string     `xml:"Dt>DtTm"`
+}
+
+type camtEntry struct {
+	XMLName     xml.Name         `xml:"Ntry"`
+	Reference   string           `xml:"NtryRef"`
+	Amount      camtAmount       `xml:"Amt"`
+	Indicator   string           `xml:"CdtDbtInd"`
+	Reversal    bool             `xml:"RvslInd,omitempty"`
+	Status      string           `xml:"Sts>Cd"`
+	BookingDate string           `xml:"BookgDt>DtTm"`
+	ServicerRef string           `xml:"AcctSvcrRef"`
+	BankTxCode  string           `xml:"BkTxCd>Prtry>Cd"`
+	Details     camtEntryDetails `xml:"NtryDtls>TxDtls"`
+	Info        string           `xml:"AddtlNtryInf,omitempty"`
+}
+
+type camtEntryDetails struct {
+	Refs       camtReferences  `xml:"Refs"`
+	Debtor     *camtAccount    `xml:"RltdPties>DbtrAcct,omitempty"`
+	Creditor   *camtAccount    `xml:"RltdPties>CdtrAcct,omitempty"`
+	Remittance *camtRemittance `xml:"RmtInf,omitempty"`
+}
+
+type camtReferences struct {
+	ServicerRef string `xml:"AcctSvcrRef"`
+	EndToEndID  string `xml:"EndToEndId,omitempty"`
+}
+
+type camtRemittance struct {
+	Unstructured string `xml:"Ustrd"`
+}
+
+// camt053Encoder writes a BkToCstmrStmt document holding a single statement.
+// Both balances precede the entries, as the schema requires.
+type camt053Encoder struct {
+	enc *xml.Encoder
+}
+
+func newCAMT053Encoder(w io.Writer) *camt053Encoder {
+	return &camt053Encoder{enc: xml.NewEncoder(w)}
+}
+
+func (e *camt053Encoder) Begin(st models.Statement) error {
+	id := fmt.Sprintf("STMT-%d-%s", st.AccountID, st.To.UTC().Format("20060102T150405"))
+	created := camtDateTime(st.CreatedAt)
+
+	declaration := xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)}
+	if err := e.enc.EncodeToken(declaration); err != nil {
+		return err
+	}
+
+	err := e.start(xml.StartElement{
+		Name: xml.Name{Local: "Document"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace}},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := e.start(element("BkToCstmrStmt")); err != nil {
+		return err
+	}
+
+	groupHeader := struct {
+		MessageID string `xml:"MsgId"`
+		CreatedAt string `xml:"CreDtTm"`
+	}{id, created}
+	if err := e.enc.EncodeElement(groupHeader, element("GrpHdr")); err != nil {
+		return err
+	}
+
+	if err := e.start(element("Stmt")); err != nil {
+		return err
+	}
+
+	fields := []struct {
+		name  string
+		value any
+	}{
+		{"Id", id},
+		{"CreDtTm", created},
+		{"FrToDt", camtPeriod{From: camtDateTime(st.From), To: camtDateTime(st.To)}},
+		{"Acct", camtStatementAccount{ID: strconv.FormatInt(st.AccountID, 10), Currency: st.Currency}},
+		{"Bal", balance(camtOpeningBooked, st.OpeningBalance, st.Currency, st.From)},
+		{"Bal", balance(camtClosingBooked, st.ClosingBalance, st.Currency, st.To)},
+	}
+	for _, field := range fields {
+		if err := e.enc.EncodeElement(field.value, element(field.name)); err != nil {
+			return err
+		}
+	}
+
+	return e.enc.Flush()
+}
+
+func (e *camt053Encoder) Line(line models.StatementLine) error {
+	ref := optionalID(line.TransactionID)
+
+	entry := camtEntry{
+		Reference:   ref,
+		Amount:      camtAmount{Currency: line.Currency, Value: formatAmount(line.Amount, line.Currency)},
+		Indicator:   camtCredit,
+		Reversal:    line.Kind == models.TransactionKindReversal,
+		Status:      camtStatusBooked,
+		BookingDate: camtDateTime(line.BookedAt),
+		ServicerRef: ref,
+		BankTxCode:  line.Kind,
+		Details:     camtEntryDetails{Refs: camtReferences{ServicerRef: ref, EndToEndID: line.ExternalReference}},
+		Info:        line.Reference,
+	}
+
+	if line.Reference != "" {
+		entry.Details.Remittance = &camtRemittance{Unstructured: line.Reference}
+	}
+
+	var counterparty *camtAccount
+	if line.CounterpartyAccountID != nil {
+		counterparty = &camtAccount{ID: strconv.FormatInt(*line.CounterpartyAccountID, 10)}
+	}
+
+	if line.Type == models.StatementLineDebit {
+		entry.Indicator = camtDebit
+		entry.Details.Creditor = counterparty
+	} else {
+		entry.Details.Debtor = counterparty
+	}
+
+	// Encode flushes, so every entry reaches w as soon as it is written.
+	return e.enc.Encode(entry)
+}
+
+func (e *camt053Encoder) End(st models.Statement) error {
+	for _, name := range []string{"Stmt", "BkToCstmrStmt", "Document"} {
+		if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+
+	return e.enc.Flush()
+}
+
+func (e *camt053Encoder) start(el xml.StartElement) error {
+	return e.enc.EncodeToken(el)
+}
+
+func element(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+// balance expresses amount the way camt.053 does: unsigned, with a credit or
+// debit indicator.
+func balance(code string, amount decimal.Decimal, currency string, at time.Time) camtBalance {
+	indicator := camtCredit
+	if amount.IsNegative() {
+		indicator = camtDebit
+	}
+
+	return camtBalance{
+		Type:      code,
+		Amount:    camtAmount{Currency: currency, Value: formatAmount(amount.Abs(), currency)},
+		Indicator: indicator,
+		Date:      camtDateTime(at),
+	}
+}
+
+func camtDateTime(t time.Time) string {
+	return t.UTC().Format(camtDateTimeLayout)
+}
@@ -0,0 +1,74 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+)
+
+var csvHeader = []string{
+	"type",
+	"booked_at",
+	"transaction_id",
+	"kind",
+	"counterparty_account_id",
+	"reference",
+	"external_reference",
+	"amount",
+	"currency",
+	"balance",
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Begin(st models.Statement) error {
+	if err := e.w.Write(csvHeader); err != nil {
+		return err
+	}
+	return e.Line(openingLine(st))
+}
+
+func (e *csvEncoder) Line(line models.StatementLine) error {
+	amount := ""
+	if line.Type == models.StatementLineDebit || line.Type == models.StatementLineCredit {
+		amount = formatAmount(line.Amount, line.Currency)
+	}
+
+	return e.w.Write([]string{
+		line.Type,
+		line.BookedAt.UTC().Format(time.RFC3339Nano),
+		optionalID(line.TransactionID),
+		line.Kind,
+		optionalID(line.CounterpartyAccountID),
+		line.Reference,
+		line.ExternalReference,
+		amount,
+		line.Currency,
+		formatAmount(line.Balance, line.Currency),
+	})
+}
+
+func (e *csvEncoder) End(st models.Statement) error {
+	if err := e.Line(closingLine(st)); err != nil {
+		return err
+	}
+
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func optionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
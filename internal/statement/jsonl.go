@@ -0,0 +1,68 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+)
+
+// jsonlEncoder writes one jsonlLine per line, starting with the opening
+// balance and ending with the closing balance.
+type jsonlEncoder struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+// jsonlLine is a models.StatementLine with its amounts written to the
+// currency's minor units, as in the other formats. Balance lines have no
+// amount.
+type jsonlLine struct {
+	Type                  string    `json:"type"`
+	BookedAt              time.Time `json:"booked_at"`
+	TransactionID         *int64    `json:"transaction_id,omitempty"`
+	Kind                  string    `json:"kind,omitempty"`
+	CounterpartyAccountID *int64    `json:"counterparty_account_id,omitempty"`
+	Reference             string    `json:"reference,omitempty"`
+	ExternalReference     string    `json:"external_reference,omitempty"`
+	Amount                string    `json:"amount,omitempty"`
+	Currency              string    `json:"currency"`
+	Balance               string    `json:"balance"`
+}
+
+func newJSONLEncoder(w io.Writer) *jsonlEncoder {
+	buf := bufio.NewWriter(w)
+	return &jsonlEncoder{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+func (e *jsonlEncoder) Begin(st models.Statement) error {
+	return e.Line(openingLine(st))
+}
+
+func (e *jsonlEncoder) Line(line models.StatementLine) error {
+	out := jsonlLine{
+		Type:                  line.Type,
+		BookedAt:              line.BookedAt.UTC(),
+		TransactionID:         line.TransactionID,
+		Kind:                  line.Kind,
+		CounterpartyAccountID: line.CounterpartyAccountID,
+		Reference:             line.Reference,
+		ExternalReference:     line.ExternalReference,
+		Currency:              line.Currency,
+		Balance:               formatAmount(line.Balance, line.Currency),
+	}
+	if line.Type == models.StatementLineDebit || line.Type == models.StatementLineCredit {
+		out.Amount = formatAmount(line.Amount, line.Currency)
+	}
+
+	return e.enc.Encode(out)
+}
+
+func (e *jsonlEncoder) End(st models.Statement) error {
+	if err := e.Line(closingLine(st)); err != nil {
+		return err
+	}
+	return e.buf.Flush()
+}
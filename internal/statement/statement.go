@@ -0,0 +1,105 @@
+// Package statement renders account statements as CSV, JSON Lines or ISO
+// 20022 camt.053 XML. Lines are written as they arrive, so a statement of any
+// length is rendered in constant memory.
+package statement
+
+import (
+	"errors"
+	"io"
+
+	"github.com/KaranPal130/transfers-system/internal/currency"
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatCAMT053 = "camt053"
+)
+
+var ErrUnknownFormat = errors.New("unknown statement format")
+
+// Encoder writes one statement. Begin is called once, then Line for every
+// debit and credit in booking order, then End.
+type Encoder interface {
+	// Begin writes everything up to the first line, including the opening
+	// balance.
+	Begin(st models.Statement) error
+	Line(line models.StatementLine) error
+	// End writes the closing balance and flushes anything still buffered.
+	End(st models.Statement) error
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w), nil
+	case FormatJSONL:
+		return newJSONLEncoder(w), nil
+	case FormatCAMT053:
+		return newCAMT053Encoder(w), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// Valid reports whether format is one NewEncoder accepts.
+func Valid(format string) bool {
+	switch format {
+	case FormatCSV, FormatJSONL, FormatCAMT053:
+		return true
+	default:
+		return false
+	}
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatCAMT053:
+		return "application/xml"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// FileExtension is the extension a file holding the format would carry.
+func FileExtension(format string) string {
+	if format == FormatCAMT053 {
+		return "xml"
+	}
+	return format
+}
+
+// openingLine and closingLine express a statement's balances as lines.
+func openingLine(st models.Statement) models.StatementLine {
+	return models.StatementLine{
+		Type:     models.StatementLineOpeningBalance,
+		BookedAt: st.From,
+		Currency: st.Currency,
+		Balance:  st.OpeningBalance,
+	}
+}
+
+func closingLine(st models.Statement) models.StatementLine {
+	return models.StatementLine{
+		Type:     models.StatementLineClosingBalance,
+		BookedAt: st.To,
+		Currency: st.Currency,
+		Balance:  st.ClosingBalance,
+	}
+}
+
+// formatAmount writes amount with the currency's minor units, e.g. 5.00 for
+// USD.
+func formatAmount(amount decimal.Decimal, code string) string {
+	cur, ok := currency.Lookup(code)
+	if !ok {
+		return amount.String()
+	}
+	return amount.StringFixed(cur.MinorUnits)
+}
@@ -0,0 +1,151 @@
+package statement
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KaranPal130/transfers-system/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func id(v int64) *int64 {
+	return &v
+}
+
+// testStatement opens overdrawn, so that both balance indicators show up in
+// camt.053, and has a line of each kind.
+func testStatement() (models.Statement, []models.StatementLine) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	st := models.Statement{
+		AccountID:      7,
+		Currency:       "USD",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: decimal.RequireFromString("-50"),
+		ClosingBalance: decimal.RequireFromString("50"),
+		CreatedAt:      time.Date(2026, 4, 2, 9, 30, 0, 0, time.UTC),
+	}
+
+	lines := []models.StatementLine{
+		{
+			Type:                  models.StatementLineCredit,
+			BookedAt:              from.Add(26 * time.Hour),
+			TransactionID:         id(11),
+			Kind:                  models.TransactionKindTransfer,
+			CounterpartyAccountID: id(9),
+			Reference:             "Batch 3",
+			Amount:                decimal.RequireFromString("100"),
+			Currency:              "USD",
+			Balance:               decimal.RequireFromString("50"),
+		},
+		{
+			Type:                  models.StatementLineDebit,
+			BookedAt:              from.Add(50*time.Hour + 1500*time.Millisecond),
+			TransactionID:         id(12),
+			Kind:                  models.TransactionKindTransfer,
+			CounterpartyAccountID: id(8),
+			Reference:             "Rent, March",
+			ExternalReference:     "inv-2026-03",
+			Amount:                decimal.RequireFromString("12.5"),
+			Currency:              "USD",
+			Balance:               decimal.RequireFromString("37.5"),
+		},
+		{
+			Type:                  models.StatementLineCredit,
+			BookedAt:              from.Add(74 * time.Hour),
+			TransactionID:         id(13),
+			Kind:                  models.TransactionKindReversal,
+			CounterpartyAccountID: id(8),
+			Reference:             "Reversal of transaction 12 (duplicate)",
+			Amount:                decimal.RequireFromString("12.5"),
+			Currency:              "USD",
+			Balance:               decimal.RequireFromString("50"),
+		},
+	}
+
+	return st, lines
+}
+
+func TestEncoders(t *testing.T) {
+	tests := []struct {
+		format string
+		golden string
+	}{
+		{FormatCSV, "statement.csv"},
+		{FormatJSONL, "statement.jsonl"},
+		{FormatCAMT053, "statement.xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			st, lines := testStatement()
+			st.Format = tt.format
+
+			var buf bytes.Buffer
+			enc, err := NewEncoder(tt.format, &buf)
+			if err != nil {
+				t.Fatalf("NewEncoder: %v", err)
+			}
+			if err := enc.Begin(st); err != nil {
+				t.Fatalf("Begin: %v", err)
+			}
+			for _, line := range lines {
+				if err := enc.Line(line); err != nil {
+					t.Fatalf("Line: %v", err)
+				}
+			}
+			if err := enc.End(st); err != nil {
+				t.Fatalf("End: %v", err)
+			}
+
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("%s output differs from %s:\ngot:\n%s\nwant:\n%s", tt.format, path, buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestNewEncoderRejectsUnknownFormat(t *testing.T) {
+	if _, err := NewEncoder("pdf", &bytes.Buffer{}); err != ErrUnknownFormat {
+		t.Fatalf("err = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{"1000", "USD", "1000.00"},
+		{"12.5", "USD", "12.50"},
+		{"1000", "JPY", "1000"},
+		{"0.5", "BHD", "0.500"},
+		{"1.25", "usd", "1.25"},
+		{"1.5", "XXX", "1.5"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAmount(decimal.RequireFromString(tt.amount), tt.currency); got != tt.want {
+			t.Errorf("formatAmount(%s, %s) = %s, want %s", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}